@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+func byteOrderCommand(cfg *config.Config) *cobra.Command {
+	var (
+		expected float64
+		typeName string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "byte-order REG...",
+		Short: "Suggest the byte order (ABCD/CDAB/BADC/DCBA) that decodes registers to an expected value",
+		Example: "  gmm byte-order 0x0000 0x41BC --expect 23.5\n" +
+			"  gmm byte-order 16828 0 17096 0 --expect 100 --type float32\n" +
+			"  gmm byte-order 0x0C80 --expect 12.5 --type q16 --q16 be,n=8",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			regs := make([]uint16, 0, len(args))
+			for _, arg := range args {
+				reg, err := parseReadAddress(arg)
+				if err != nil {
+					return fmt.Errorf("register %q must be 0-65535", arg)
+				}
+				regs = append(regs, reg)
+			}
+			typ := config.DecoderType(typeName)
+			decoder, ok := defaultsDecoders(cfg)[typ]
+			if !ok {
+				decoder = config.DecoderConfig{Type: typ}
+			}
+			candidates, err := core.DetectByteOrder(regs, decoder, expected)
+			if err != nil {
+				return err
+			}
+			if len(candidates) == 0 {
+				return fmt.Errorf("no byte order decodes to a finite %s", typeName)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ORDER\tOFFSET\tVALUE\tDELTA")
+			for _, candidate := range candidates[:min(limit, len(candidates))] {
+				fmt.Fprintf(w, "%s\t+%d\t%s\t%s\n", candidate.Order, candidate.Offset,
+					strconv.FormatFloat(candidate.Value, 'g', 8, 64), strconv.FormatFloat(candidate.Delta, 'g', 4, 64))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			best := candidates[0]
+			fmt.Printf("\nSuggested: %s at +%d (use --%s %s)\n", best.Order, best.Offset, config.DecoderFlag(typ), best.Order)
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&expected, "expect", 0, "value the registers should decode to")
+	cmd.Flags().StringVar(&typeName, "type", string(config.DecoderFloat32), "decoder type to try (q16/q32 take n= from --q16/--q32)")
+	cmd.Flags().IntVar(&limit, "limit", 8, "number of candidates to list")
+	_ = cmd.MarkFlagRequired("expect")
+
+	return cmd
+}
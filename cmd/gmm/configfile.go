@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gomodmaster/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// configFlagValues renders the settings of a config file as the values of
+// the global flags that set them.
+func configFlagValues(cfg config.Config) map[string]string {
+	return map[string]string{
+		"serial":          cfg.Serial.Device,
+		"speed":           strconv.FormatUint(uint64(cfg.Serial.Speed), 10),
+		"databits":        strconv.FormatUint(uint64(cfg.Serial.DataBits), 10),
+		"stopbits":        strconv.FormatUint(uint64(cfg.Serial.StopBits), 10),
+		"parity":          cfg.Serial.Parity,
+		"host":            cfg.TCP.Host,
+		"port":            strconv.Itoa(cfg.TCP.Port),
+		"unit-id":         strconv.Itoa(int(cfg.UnitID)),
+		"timeout":         strconv.FormatInt(cfg.TimeoutMs, 10),
+		"address":         strconv.Itoa(int(cfg.ReadAddress)),
+		"count":           strconv.Itoa(int(cfg.ReadQuantity)),
+		"function":        cfg.ReadKind,
+		"address-base":    strconv.Itoa(int(cfg.AddressBase)),
+		"address-format":  strconv.Itoa(int(cfg.AddressFormat)),
+		"value-base":      strconv.Itoa(int(cfg.ValueBase)),
+		"map":             cfg.MapFile,
+		"profile":         cfg.Profile,
+		"capture":         cfg.CapturePath,
+		"log-file":        cfg.LogFile.Path,
+		"log-max-size":    strconv.Itoa(cfg.LogFile.MaxSizeMB),
+		"log-max-age":     strconv.Itoa(cfg.LogFile.MaxAgeHours),
+		"log-max-backups": strconv.Itoa(cfg.LogFile.MaxBackups),
+		"log-compress":    strconv.FormatBool(cfg.LogFile.Compress),
+		"auto-save":       strconv.FormatBool(cfg.AutoSave),
+	}
+}
+
+// applyConfigFile loads path into cfg and copies its settings into the flags
+// that were not given on the command line, so flags win over the file. A
+// missing file leaves the defaults and is created on the next save.
+func applyConfigFile(cmd *cobra.Command, path string, cfg *config.Config) error {
+	loaded, err := config.LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		cfg.ConfigFile = path
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	*cfg = loaded
+	for name, value := range configFlagValues(loaded) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gomodmaster/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+func encodeCommand() *cobra.Command {
+	var sfValue int
+
+	cmd := &cobra.Command{
+		Use:   "encode TYPE[,options] VALUE",
+		Short: "Show the register words a typed value encodes to",
+		Long: "Encode is the inverse of the decoders: it converts a value into the register\n" +
+			"words a device expects, using the same TYPE[,options] syntax as --at.",
+		Example: "  gmm encode float32,CDAB 23.5\n" +
+			"  gmm encode int16,scale=0.1,offset=-40 23.5\n" +
+			"  gmm encode uint16,sf=40084 1230 --sf-value -1\n" +
+			"  gmm encode string,len=4 \"SN-0042\"",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName, options, _ := strings.Cut(args[0], ",")
+			decoder, err := defaultDecoder(typeName)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(options) != "" {
+				decoder, err = parseDecoderSpec(options, decoder)
+				if err != nil {
+					return err
+				}
+			}
+			var (
+				address uint16
+				block   []uint16
+			)
+			if decoder.ScaleFactor != nil && cmd.Flags().Changed("sf-value") {
+				if sfValue < -10 || sfValue > 10 {
+					return fmt.Errorf("sf-value must be -10..10")
+				}
+				address = *decoder.ScaleFactor
+				block = []uint16{uint16(int16(sfValue))}
+			}
+			regs, err := core.Encode(args[1], decoder, address, block)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "OFFSET\tHEX\tDEC")
+			for i, reg := range regs {
+				fmt.Fprintf(w, "+%d\t0x%04X\t%d\n", i, reg, reg)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().IntVar(&sfValue, "sf-value", 0, "value of the scale factor register when the decoder uses sf=")
+
+	return cmd
+}
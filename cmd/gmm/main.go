@@ -11,10 +11,14 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	// Embedded zone database so tz= works on hosts without one.
+	_ "time/tzdata"
 
 	"gomodmaster/internal/config"
 	"gomodmaster/internal/core"
 	"gomodmaster/internal/netutil"
+	"gomodmaster/internal/profiles"
+	"gomodmaster/internal/regmap"
 	httptransport "gomodmaster/internal/transport/http"
 	"gomodmaster/internal/transport/ws"
 	"gomodmaster/internal/tui"
@@ -23,6 +27,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const webLogBufferSize = 2000
+
 func main() {
 	cfg := config.DefaultConfig()
 	rootCmd := &cobra.Command{
@@ -36,6 +42,10 @@ func main() {
 
 	addGlobalFlags(rootCmd, &cfg)
 	rootCmd.AddCommand(webCommand(&cfg))
+	rootCmd.AddCommand(byteOrderCommand(&cfg))
+	rootCmd.AddCommand(encodeCommand())
+	rootCmd.AddCommand(mapCommand(&cfg))
+	rootCmd.AddCommand(profilesCommand(&cfg))
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -56,7 +66,7 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 		unitID    uint
 		timeoutMs int64
 		address   string
-		count     uint
+		count     uint16
 		function  string
 		addrBase  uint
 		addrFmt   string
@@ -65,7 +75,33 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 		i16Spec   string
 		u32Spec   string
 		i32Spec   string
+		f16Spec   string
 		f32Spec   string
+		u64Spec   string
+		i64Spec   string
+		f64Spec   string
+		q16Spec   string
+		q32Spec   string
+		strSpec   string
+		bcd16Spec string
+		bcd32Spec string
+		bitsSpec  string
+		enumSpec  string
+		ep32Spec  string
+		ep64Spec  string
+		dateSpec  string
+		packSpec  string
+		atSpecs   []string
+		mapFile   string
+		profile   string
+		cfgFile   string
+		autoSave  bool
+		capture   string
+		logFile   string
+		logSize   int
+		logAge    int
+		logKeep   int
+		logGzip   bool
 		showVer   bool
 	)
 
@@ -80,16 +116,42 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 	root.PersistentFlags().UintVar(&unitID, "unit-id", uint(cfg.UnitID), "unit id")
 	root.PersistentFlags().Int64Var(&timeoutMs, "timeout", cfg.TimeoutMs, "request timeout (ms)")
 	root.PersistentFlags().StringVar(&address, "address", fmt.Sprintf("%d", cfg.ReadAddress), "default read address (decimal or 0x...)")
-	root.PersistentFlags().UintVar(&count, "count", uint(cfg.ReadQuantity), "default read count")
+	root.PersistentFlags().Uint16Var(&count, "count", cfg.ReadQuantity, "default read count")
 	root.PersistentFlags().StringVar(&function, "function", cfg.ReadKind, "default function (01/02/03/04 or coils/discrete_inputs/holding_registers/input_registers)")
 	root.PersistentFlags().UintVar(&addrBase, "address-base", uint(cfg.AddressBase), "address base (0 or 1)")
 	root.PersistentFlags().StringVar(&addrFmt, "address-format", formatBaseHelp(cfg.AddressFormat), "address format (dec or hex)")
 	root.PersistentFlags().StringVar(&valueBase, "value-base", formatBaseHelp(cfg.ValueBase), "value format (dec or hex)")
 	root.PersistentFlags().StringVar(&u16Spec, "u16", "", "enable uint16 decoder (be/le[,hf/lf])")
 	root.PersistentFlags().StringVar(&i16Spec, "i16", "", "enable int16 decoder (be/le[,hf/lf])")
-	root.PersistentFlags().StringVar(&u32Spec, "u32", "", "enable uint32 decoder (be/le[,hf/lf])")
-	root.PersistentFlags().StringVar(&i32Spec, "i32", "", "enable int32 decoder (be/le[,hf/lf])")
-	root.PersistentFlags().StringVar(&f32Spec, "f32", "", "enable float32 decoder (be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&u32Spec, "u32", "", "enable uint32 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&i32Spec, "i32", "", "enable int32 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&f16Spec, "f16", "", "enable float16 (half precision) decoder (be/le)")
+	root.PersistentFlags().StringVar(&f32Spec, "f32", "", "enable float32 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&u64Spec, "u64", "", "enable uint64 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&i64Spec, "i64", "", "enable int64 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&f64Spec, "f64", "", "enable float64 decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&q16Spec, "q16", "", "enable 16-bit Qm.n fixed-point decoder (be/le[,n=FRACBITS][,unsigned])")
+	root.PersistentFlags().StringVar(&q32Spec, "q32", "", "enable 32-bit Qm.n fixed-point decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf][,n=FRACBITS][,unsigned])")
+	root.PersistentFlags().StringVar(&bcd16Spec, "bcd16", "", "enable 16-bit BCD decoder (be/le)")
+	root.PersistentFlags().StringVar(&bcd32Spec, "bcd32", "", "enable 32-bit BCD decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf])")
+	root.PersistentFlags().StringVar(&bitsSpec, "bits", "", "enable bitfield decoder (be/le[,BIT=NAME...], e.g. 0=RUN,3=FAULT)")
+	root.PersistentFlags().StringVar(&enumSpec, "enum", "", "enable enum decoder (be/le[,VALUE=LABEL...], e.g. 0=Stopped,1=Running,2=Fault)")
+	root.PersistentFlags().StringVar(&ep32Spec, "epoch32", "", "enable 32-bit Unix timestamp decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf][,ms][,tz=ZONE])")
+	root.PersistentFlags().StringVar(&ep64Spec, "epoch64", "", "enable 64-bit Unix timestamp decoder (ABCD/CDAB/BADC/DCBA or be/le[,hf/lf][,ms][,tz=ZONE])")
+	root.PersistentFlags().StringVar(&dateSpec, "datetime", "", "enable packed date/time decoder: Y,M,D,h,m,s registers, or YYMM,DDhh,mmss bytes with len=3 (be/le[,len=6/3][,tz=ZONE])")
+	root.PersistentFlags().StringVar(&packSpec, "packed", "", "enable coil/discrete-input packing decoder (bits=N[,lsb/msb])")
+	root.PersistentFlags().StringVar(&strSpec, "str", "", "enable string decoder (be/le/swap[,len=N][,trim=none/null/space/both][,enc=ascii/utf-8/latin-1])")
+	root.PersistentFlags().StringArrayVar(&atSpecs, "at", nil, "pin a decoder to an address: ADDR[:COUNT]=TYPE[,options][,name=NAME] (repeatable)")
+	root.PersistentFlags().StringVar(&mapFile, "map", "", "load named points from a register map file (.yaml or .csv)")
+	root.PersistentFlags().StringVar(&profile, "profile", "", "apply a saved connection profile or a device profile (see gmm profiles)")
+	root.PersistentFlags().StringVar(&capture, "capture", "", "record Modbus traffic to a pcapng file")
+	root.PersistentFlags().StringVar(&logFile, "log-file", "", "append session log to a JSON Lines file")
+	root.PersistentFlags().IntVar(&logSize, "log-max-size", cfg.LogFile.MaxSizeMB, "rotate log file after this many megabytes (0 disables)")
+	root.PersistentFlags().IntVar(&logAge, "log-max-age", cfg.LogFile.MaxAgeHours, "rotate log file after this many hours (0 disables)")
+	root.PersistentFlags().IntVar(&logKeep, "log-max-backups", cfg.LogFile.MaxBackups, "number of rotated log files to keep (0 keeps all)")
+	root.PersistentFlags().BoolVar(&logGzip, "log-compress", false, "gzip rotated log files")
+	root.PersistentFlags().StringVar(&cfgFile, "config", "", "load settings from a YAML or JSON config file (flags take precedence)")
+	root.PersistentFlags().BoolVar(&autoSave, "auto-save", false, "save the configuration to the config file on exit")
 	root.PersistentFlags().BoolVar(&showVer, "version", false, "print version and exit")
 
 	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
@@ -98,6 +160,15 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 			os.Exit(0)
 		}
 		flags := cmd.Flags()
+		path := cfgFile
+		if path == "" && interactive(cmd) {
+			path, _ = config.DefaultFile()
+		}
+		if path != "" {
+			if err := applyConfigFile(cmd, path, cfg); err != nil {
+				return err
+			}
+		}
 		serialMode := flags.Changed("serial") ||
 			flags.Changed("speed") ||
 			flags.Changed("databits") ||
@@ -113,7 +184,7 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 				return fmt.Errorf("unsupported framing: %s", framing)
 			}
 			cfg.Protocol = config.ProtocolRTU
-		} else {
+		} else if tcpMode {
 			cfg.Protocol = config.ProtocolTCP
 		}
 		cfg.Serial.Device = serial
@@ -130,11 +201,8 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 			return err
 		}
 		cfg.ReadAddress = readAddress
-		if count == 0 || count > 0xffff {
-			return fmt.Errorf("count must be 1-65535")
-		}
-		cfg.ReadQuantity = uint16(count)
-		readKind, err := parseReadKind(function)
+		cfg.ReadQuantity = count
+		readKind, err := config.ParseReadKind(function)
 		if err != nil {
 			return err
 		}
@@ -154,17 +222,102 @@ func addGlobalFlags(root *cobra.Command, cfg *config.Config) {
 			return err
 		}
 		cfg.ValueBase = value
+		cfg.CapturePath = capture
+		if logSize < 0 || logAge < 0 || logKeep < 0 {
+			return fmt.Errorf("log rotation limits must be >= 0")
+		}
+		cfg.LogFile = config.LogFileConfig{
+			Path:        logFile,
+			MaxSizeMB:   logSize,
+			MaxAgeHours: logAge,
+			MaxBackups:  logKeep,
+			Compress:    logGzip,
+		}
+		cfg.AutoSave = autoSave
 		if err := applyDecoderOverrides(cfg, defaultsDecoders(cfg), decoderSpec{spec: u16Spec, changed: flags.Changed("u16"), typ: config.DecoderUint16},
 			decoderSpec{spec: i16Spec, changed: flags.Changed("i16"), typ: config.DecoderInt16},
 			decoderSpec{spec: u32Spec, changed: flags.Changed("u32"), typ: config.DecoderUint32},
 			decoderSpec{spec: i32Spec, changed: flags.Changed("i32"), typ: config.DecoderInt32},
-			decoderSpec{spec: f32Spec, changed: flags.Changed("f32"), typ: config.DecoderFloat32}); err != nil {
+			decoderSpec{spec: f16Spec, changed: flags.Changed("f16"), typ: config.DecoderFloat16},
+			decoderSpec{spec: f32Spec, changed: flags.Changed("f32"), typ: config.DecoderFloat32},
+			decoderSpec{spec: u64Spec, changed: flags.Changed("u64"), typ: config.DecoderUint64},
+			decoderSpec{spec: i64Spec, changed: flags.Changed("i64"), typ: config.DecoderInt64},
+			decoderSpec{spec: f64Spec, changed: flags.Changed("f64"), typ: config.DecoderFloat64},
+			decoderSpec{spec: q16Spec, changed: flags.Changed("q16"), typ: config.DecoderQ16},
+			decoderSpec{spec: q32Spec, changed: flags.Changed("q32"), typ: config.DecoderQ32},
+			decoderSpec{spec: bcd16Spec, changed: flags.Changed("bcd16"), typ: config.DecoderBCD16},
+			decoderSpec{spec: bcd32Spec, changed: flags.Changed("bcd32"), typ: config.DecoderBCD32},
+			decoderSpec{spec: bitsSpec, changed: flags.Changed("bits"), typ: config.DecoderBits},
+			decoderSpec{spec: enumSpec, changed: flags.Changed("enum"), typ: config.DecoderEnum},
+			decoderSpec{spec: ep32Spec, changed: flags.Changed("epoch32"), typ: config.DecoderEpoch32},
+			decoderSpec{spec: ep64Spec, changed: flags.Changed("epoch64"), typ: config.DecoderEpoch64},
+			decoderSpec{spec: dateSpec, changed: flags.Changed("datetime"), typ: config.DecoderDate},
+			decoderSpec{spec: packSpec, changed: flags.Changed("packed"), typ: config.DecoderPacked},
+			decoderSpec{spec: strSpec, changed: flags.Changed("str"), typ: config.DecoderString}); err != nil {
 			return err
 		}
+		if flags.Changed("at") {
+			cfg.Assignments = nil
+			for _, spec := range atSpecs {
+				assignment, err := parseAssignment(spec)
+				if err != nil {
+					return fmt.Errorf("--at %s: %w", spec, err)
+				}
+				cfg.Assignments = append(cfg.Assignments, assignment)
+			}
+		}
+		if mapFile != "" {
+			registerMap, err := regmap.Load(mapFile)
+			if err != nil {
+				return err
+			}
+			cfg.MapFile = mapFile
+			cfg.Assignments = append(cfg.Assignments, registerMap.Assignments()...)
+		}
+		if profile != "" {
+			// A profile named in the config file only brings its points; the
+			// file already holds the settings it was saved with.
+			keep := func(flag string) bool {
+				if !flags.Changed("profile") {
+					return true
+				}
+				if flag == "protocol" {
+					return serialMode || tcpMode
+				}
+				return flags.Changed(flag)
+			}
+			settings, err := profiles.Lookup(profile)
+			if err != nil {
+				return err
+			}
+			settings.Apply(cfg, keep)
+		}
+		if usesSettings(cmd) {
+			return cfg.Validate()
+		}
 		return nil
 	}
 }
 
+// interactive reports whether cmd is the TUI or the web UI, the commands that
+// load the default config file when no --config is given.
+func interactive(cmd *cobra.Command) bool {
+	return !cmd.HasParent() || cmd.CommandPath() == "gmm web"
+}
+
+// usesSettings reports whether cmd connects or saves with the connection and
+// read settings, which then have to pass config.Validate.
+func usesSettings(cmd *cobra.Command) bool {
+	switch cmd.CommandPath() {
+	case "gmm profiles save":
+		return true
+	case "gmm map doc":
+		live, _ := cmd.Flags().GetBool("live")
+		return live
+	}
+	return interactive(cmd)
+}
+
 func parseReadAddress(value string) (uint16, error) {
 	value = strings.TrimSpace(strings.ToLower(value))
 	if value == "" {
@@ -183,22 +336,6 @@ func parseReadAddress(value string) (uint16, error) {
 	return uint16(parsed), nil
 }
 
-func parseReadKind(value string) (string, error) {
-	trimmed := strings.TrimSpace(strings.ToLower(value))
-	switch trimmed {
-	case "1", "01", "coils", "coil":
-		return "coils", nil
-	case "2", "02", "discrete_inputs", "discrete":
-		return "discrete_inputs", nil
-	case "3", "03", "holding_registers", "holding":
-		return "holding_registers", nil
-	case "4", "04", "input_registers", "input":
-		return "input_registers", nil
-	default:
-		return "", fmt.Errorf("unsupported function: %s", value)
-	}
-}
-
 func parseAddressBase(value uint) (config.AddressBase, error) {
 	switch value {
 	case 0:
@@ -261,17 +398,57 @@ func parseDecoderSpec(spec string, defaults config.DecoderConfig) (config.Decode
 	if strings.TrimSpace(spec) == "" {
 		return config.DecoderConfig{}, fmt.Errorf("decoder flag requires a value")
 	}
-	parts := strings.FieldsFunc(strings.ToLower(strings.TrimSpace(spec)), func(r rune) bool {
-		return r == ',' || r == ' '
-	})
+	parts := splitDecoderSpec(spec)
 	decoder := defaults
 	decoder.Enabled = true
+	if decoder.Type == config.DecoderBits {
+		decoder.BitNames = nil
+	}
+	if decoder.Type == config.DecoderEnum {
+		decoder.Labels = nil
+	}
 	for _, part := range parts {
-		switch part {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			if number, err := strconv.Atoi(key); err == nil {
+				if err := setLabel(&decoder, number, value); err != nil {
+					return config.DecoderConfig{}, err
+				}
+				continue
+			}
+			if err := applyDecoderOption(&decoder, strings.ToLower(key), value); err != nil {
+				return config.DecoderConfig{}, err
+			}
+			continue
+		}
+		if order, ok := config.ParseByteOrder(part); ok {
+			decoder.SetByteOrder(order)
+			continue
+		}
+		switch strings.ToLower(part) {
 		case "be":
 			decoder.Endianness = config.EndianBig
 		case "le":
 			decoder.Endianness = config.EndianLittle
+		case "swap":
+			if decoder.Type != config.DecoderString {
+				return config.DecoderConfig{}, fmt.Errorf("unsupported decoder option: %s", part)
+			}
+			decoder.Endianness = config.EndianLittle
+		case "unsigned":
+			if decoder.Type != config.DecoderQ16 && decoder.Type != config.DecoderQ32 {
+				return config.DecoderConfig{}, fmt.Errorf("unsupported decoder option: %s", part)
+			}
+			decoder.Unsigned = true
+		case "ms":
+			if decoder.Type != config.DecoderEpoch32 && decoder.Type != config.DecoderEpoch64 {
+				return config.DecoderConfig{}, fmt.Errorf("unsupported decoder option: %s", part)
+			}
+			decoder.Millis = true
+		case "lsb", "msb":
+			if decoder.Type != config.DecoderPacked {
+				return config.DecoderConfig{}, fmt.Errorf("unsupported decoder option: %s", part)
+			}
+			decoder.MSBFirst = strings.EqualFold(part, "msb")
 		case "hf":
 			decoder.WordOrder = config.WordHighFirst
 		case "lf":
@@ -285,6 +462,241 @@ func parseDecoderSpec(spec string, defaults config.DecoderConfig) (config.Decode
 	return decoder, nil
 }
 
+// splitDecoderSpec splits a decoder spec at commas and spaces, except that
+// labels (VALUE=LABEL) and unit= run to the next comma so they may hold
+// spaces.
+func splitDecoderSpec(spec string) []string {
+	parts := []string{}
+	for _, field := range strings.Split(spec, ",") {
+		words := strings.Fields(field)
+		for i, word := range words {
+			key, _, ok := strings.Cut(word, "=")
+			if _, err := strconv.Atoi(key); ok && (err == nil || strings.EqualFold(key, "unit")) {
+				parts = append(parts, strings.Join(words[i:], " "))
+				break
+			}
+			parts = append(parts, word)
+		}
+	}
+	return parts
+}
+
+func applyDecoderOption(decoder *config.DecoderConfig, key, value string) error {
+	switch key {
+	case "scale", "offset", "dec", "unit", "sf":
+		if !scalable(decoder.Type) {
+			return fmt.Errorf("unsupported decoder option: %s", key)
+		}
+		return applyScalingOption(decoder, key, value)
+	case "n":
+		return applyFracBits(decoder, value)
+	case "tz":
+		return applyTimezone(decoder, value)
+	case "bits":
+		if decoder.Type != config.DecoderPacked {
+			return fmt.Errorf("unsupported decoder option: bits")
+		}
+		bits, err := strconv.Atoi(value)
+		if err != nil || bits < 1 || bits > 64 {
+			return fmt.Errorf("bits must be 1-64")
+		}
+		decoder.Bits = bits
+		return nil
+	}
+	if decoder.Type == config.DecoderDate && key == "len" {
+		if value != "3" && value != "6" {
+			return fmt.Errorf("len must be 3 or 6")
+		}
+		decoder.Registers, _ = strconv.Atoi(value)
+		return nil
+	}
+	if decoder.Type != config.DecoderString {
+		return fmt.Errorf("unsupported decoder option: %s", key)
+	}
+	value = strings.ToLower(value)
+	switch key {
+	case "len":
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 || count > 125 {
+			return fmt.Errorf("len must be 1-125")
+		}
+		decoder.Registers = count
+	case "trim":
+		switch trim := config.StringTrim(value); trim {
+		case config.TrimNone, config.TrimNull, config.TrimSpace, config.TrimBoth:
+			decoder.Trim = trim
+		default:
+			return fmt.Errorf("unsupported trim: %s", value)
+		}
+	case "enc":
+		switch value {
+		case "ascii":
+			decoder.Encoding = config.EncodingASCII
+		case "utf-8", "utf8":
+			decoder.Encoding = config.EncodingUTF8
+		case "latin-1", "latin1", "iso-8859-1":
+			decoder.Encoding = config.EncodingLatin1
+		default:
+			return fmt.Errorf("unsupported encoding: %s", value)
+		}
+	default:
+		return fmt.Errorf("unsupported decoder option: %s", key)
+	}
+	return nil
+}
+
+func scalable(typ config.DecoderType) bool {
+	switch typ {
+	case config.DecoderString, config.DecoderBits, config.DecoderEnum,
+		config.DecoderEpoch32, config.DecoderEpoch64, config.DecoderDate, config.DecoderPacked:
+		return false
+	default:
+		return true
+	}
+}
+
+func applyFracBits(decoder *config.DecoderConfig, value string) error {
+	width := 16
+	switch decoder.Type {
+	case config.DecoderQ16:
+	case config.DecoderQ32:
+		width = 32
+	default:
+		return fmt.Errorf("unsupported decoder option: n")
+	}
+	bits, err := strconv.Atoi(value)
+	if err != nil || bits < 0 || bits > width {
+		return fmt.Errorf("n must be 0-%d", width)
+	}
+	decoder.FracBits = bits
+	return nil
+}
+
+func applyTimezone(decoder *config.DecoderConfig, value string) error {
+	switch decoder.Type {
+	case config.DecoderEpoch32, config.DecoderEpoch64, config.DecoderDate:
+	default:
+		return fmt.Errorf("unsupported decoder option: tz")
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		return fmt.Errorf("unknown timezone: %s", value)
+	}
+	decoder.Timezone = value
+	return nil
+}
+
+func applyScalingOption(decoder *config.DecoderConfig, key, value string) error {
+	switch key {
+	case "scale":
+		scale, err := strconv.ParseFloat(value, 64)
+		if err != nil || scale == 0 {
+			return fmt.Errorf("scale must be a non-zero number")
+		}
+		decoder.Scale = scale
+	case "offset":
+		offset, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("offset must be a number")
+		}
+		decoder.Offset = offset
+	case "dec":
+		decimals, err := strconv.Atoi(value)
+		if err != nil || decimals < 0 || decimals > 10 {
+			return fmt.Errorf("dec must be 0-10")
+		}
+		decoder.Decimals = &decimals
+	case "unit":
+		decoder.Unit = value
+	case "sf":
+		register, err := parseReadAddress(value)
+		if err != nil {
+			return fmt.Errorf("sf: %w", err)
+		}
+		decoder.ScaleFactor = &register
+	}
+	return nil
+}
+
+// setLabel records a bit name for bitfield decoders or a value label for
+// enum decoders.
+func setLabel(decoder *config.DecoderConfig, key int, label string) error {
+	if label == "" {
+		return fmt.Errorf("%d requires a label", key)
+	}
+	switch decoder.Type {
+	case config.DecoderBits:
+		if key < 0 || key > 15 {
+			return fmt.Errorf("bit must be 0-15")
+		}
+		if decoder.BitNames == nil {
+			decoder.BitNames = map[int]string{}
+		}
+		decoder.BitNames[key] = label
+	case config.DecoderEnum:
+		if key < 0 || key > 0xffff {
+			return fmt.Errorf("enum value must be 0-65535")
+		}
+		if decoder.Labels == nil {
+			decoder.Labels = map[int]string{}
+		}
+		decoder.Labels[key] = label
+	default:
+		return fmt.Errorf("unsupported decoder option: %d=%s", key, label)
+	}
+	return nil
+}
+
+// defaultDecoder returns the default settings of the named decoder type.
+func defaultDecoder(typeName string) (config.DecoderConfig, error) {
+	decoder, ok := config.DefaultDecoder(typeName)
+	if !ok {
+		return config.DecoderConfig{}, fmt.Errorf("unknown decoder type: %s", typeName)
+	}
+	return decoder, nil
+}
+
+// parseAssignment reads ADDR[:COUNT]=TYPE[,options][,name=NAME]; options are
+// the same as for the matching decoder flag.
+func parseAssignment(spec string) (config.Assignment, error) {
+	target, decoderSpec, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok {
+		return config.Assignment{}, fmt.Errorf("expected ADDR[:COUNT]=TYPE")
+	}
+	var assignment config.Assignment
+	addr, countValue, hasCount := strings.Cut(target, ":")
+	address, err := parseReadAddress(addr)
+	if err != nil {
+		return config.Assignment{}, err
+	}
+	assignment.Address = address
+	if hasCount {
+		count, err := strconv.Atoi(countValue)
+		if err != nil || count < 1 || count > 0xffff {
+			return config.Assignment{}, fmt.Errorf("count must be 1-65535")
+		}
+		assignment.Count = count
+	}
+	typeName, rest, _ := strings.Cut(decoderSpec, ",")
+	decoder, err := defaultDecoder(typeName)
+	if err != nil {
+		return config.Assignment{}, err
+	}
+	// The name comes last and runs to the end, so it may contain commas.
+	if i := strings.Index(","+rest, ",name="); i >= 0 {
+		assignment.Name = strings.TrimSpace(rest[i+len("name="):])
+		rest = strings.TrimSuffix(rest[:i], ",")
+	}
+	if strings.TrimSpace(rest) != "" {
+		decoder, err = parseDecoderSpec(rest, decoder)
+		if err != nil {
+			return config.Assignment{}, err
+		}
+	}
+	decoder.Enabled = true
+	assignment.Decoder = decoder
+	return assignment, nil
+}
+
 func setDecoder(cfg *config.Config, decoder config.DecoderConfig) {
 	for idx := range cfg.Decoders {
 		if cfg.Decoders[idx].Type == decoder.Type {
@@ -305,11 +717,25 @@ func webCommand(cfg *config.Config) *cobra.Command {
 		Use:   "web",
 		Short: "Launch local web UI",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg.ListenAddr = listen
+			if cmd.Flags().Changed("listen") || cfg.ListenAddr == "" {
+				cfg.ListenAddr = listen
+			}
 			cfg.RequireToken = !noToken
 
-			service := core.NewService(*cfg)
+			service := core.NewServiceWithLogSize(*cfg, webLogBufferSize)
 			hub := ws.NewHub()
+			if cfg.LogFile.Path != "" {
+				if err := service.OpenLogFile(cfg.LogFile); err != nil {
+					return err
+				}
+				defer service.CloseLogFile()
+			}
+			if cfg.CapturePath != "" {
+				if err := service.StartCapture(cfg.CapturePath); err != nil {
+					return err
+				}
+				defer service.StopCapture()
+			}
 
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
@@ -328,6 +754,16 @@ func webCommand(cfg *config.Config) *cobra.Command {
 				serverErr <- e.Start(current.ListenAddr)
 			}()
 
+			defer func() {
+				if current := service.Config(); current.AutoSave {
+					if path, err := config.Save(current); err != nil {
+						fmt.Fprintln(os.Stderr, "save config:", err)
+					} else {
+						fmt.Println("Saved config to", path)
+					}
+				}
+			}()
+
 			select {
 			case err := <-serverErr:
 				_ = service.Disconnect()
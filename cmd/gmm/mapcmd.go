@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/core"
+	"gomodmaster/internal/regmap"
+	"gomodmaster/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+func mapCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "map",
+		Short: "Work with register map files",
+	}
+	cmd.AddCommand(mapImportCommand(cfg))
+	cmd.AddCommand(mapDocCommand(cfg))
+	return cmd
+}
+
+func mapImportCommand(cfg *config.Config) *cobra.Command {
+	var (
+		mappingFile string
+		saveMapping string
+		output      string
+		headerRow   int
+		delimiter   string
+		wizard      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import VENDOR.csv",
+		Short: "Convert a vendor register list into a register map",
+		Long: "Import reads a vendor CSV export, maps its columns to point fields (address,\n" +
+			"name, type, scale, unit, access, ...) with a mapping file or an interactive\n" +
+			"wizard, converts Modicon references (40001, 30001, ...) to --address-base\n" +
+			"addresses and writes a register map for --map.",
+		Example: "  gmm map import vendor.csv -o device.yaml --save-mapping vendor-columns.yaml\n" +
+			"  gmm map import vendor.csv --mapping vendor-columns.yaml -o device.csv",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var mapping regmap.Mapping
+			if mappingFile != "" {
+				mapping, err = regmap.LoadMapping(mappingFile)
+				if err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("header-row") {
+				mapping.HeaderRow = headerRow
+			}
+			if cmd.Flags().Changed("delimiter") {
+				mapping.Delimiter = delimiter
+			}
+			if mappingFile == "" || wizard {
+				if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+					return fmt.Errorf("the column wizard needs a terminal; pass --mapping FILE")
+				}
+				table, err := regmap.ReadTable(data, mapping.HeaderRow, mapping.Delimiter)
+				if err != nil {
+					return fmt.Errorf("%s: %w", args[0], err)
+				}
+				mapping, err = tui.RunImportWizard(table, mapping, cfg.AddressBase)
+				if err != nil {
+					return err
+				}
+			}
+			if saveMapping != "" {
+				if err := regmap.SaveMapping(saveMapping, mapping); err != nil {
+					return err
+				}
+			}
+			registerMap, warnings, err := regmap.Import(data, mapping, cfg.AddressBase)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			for _, warning := range warnings {
+				fmt.Fprintln(os.Stderr, warning)
+			}
+			if output == "" {
+				data, err := regmap.FormatYAML(registerMap)
+				if err != nil {
+					return err
+				}
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := regmap.Save(output, registerMap); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "wrote %d points to %s\n", len(registerMap.Points), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&mappingFile, "mapping", "m", "", "column mapping file (YAML or JSON); without it the wizard asks")
+	cmd.Flags().StringVar(&saveMapping, "save-mapping", "", "write the mapping used to this file for the next import")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "register map to write (.yaml or .csv; default YAML on stdout)")
+	cmd.Flags().IntVar(&headerRow, "header-row", 1, "line of the header row")
+	cmd.Flags().StringVar(&delimiter, "delimiter", "", "column delimiter (default: detect , ; or tab)")
+	cmd.Flags().BoolVar(&wizard, "wizard", false, "review a --mapping file in the wizard")
+
+	return cmd
+}
+
+func mapDocCommand(cfg *config.Config) *cobra.Command {
+	var (
+		output string
+		format string
+		title  string
+		live   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doc [MAP]",
+		Short: "Render a register map as Markdown or HTML reference tables",
+		Long: "Doc renders a register map (the argument or --map) as one table per block,\n" +
+			"or per read kind for points without a block, listing address, type, size,\n" +
+			"unit, scale and access. With --live the points are read using the\n" +
+			"connection flags and their current values are added.",
+		Example: "  gmm map doc device.yaml -o device.md\n" +
+			"  gmm map doc device.yaml -o device.html --live --host 192.168.1.20",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := cfg.MapFile
+			if len(args) == 1 {
+				path = args[0]
+			}
+			if path == "" {
+				return fmt.Errorf("no register map: pass MAP or --map")
+			}
+			registerMap, err := regmap.Load(path)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("format") && strings.EqualFold(filepath.Ext(output), ".html") {
+				format = "html"
+			}
+			if title == "" {
+				title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			}
+			opts := regmap.DocOptions{Title: title}
+			if live {
+				values, warnings, err := readLiveValues(*cfg, registerMap)
+				if err != nil {
+					return err
+				}
+				for _, warning := range warnings {
+					fmt.Fprintln(os.Stderr, warning)
+				}
+				opts.Values = values
+				opts.ReadAt = time.Now()
+			}
+			var doc []byte
+			switch format {
+			case "md", "markdown":
+				doc = regmap.RenderMarkdown(registerMap, opts)
+			case "html":
+				doc, err = regmap.RenderHTML(registerMap, opts)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("format must be markdown or html")
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(doc)
+				return err
+			}
+			return os.WriteFile(output, doc, 0o644)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write (default stdout)")
+	cmd.Flags().StringVar(&format, "format", "markdown", "markdown or html (default html for -o *.html)")
+	cmd.Flags().StringVar(&title, "title", "", "document title (default: map file name)")
+	cmd.Flags().BoolVar(&live, "live", false, "read the points and include their current values")
+
+	return cmd
+}
+
+// readLiveValues connects with the connection flags, waiting up to the
+// request timeout (at least five seconds) for the connection.
+func readLiveValues(cfg config.Config, registerMap regmap.Map) ([]string, []string, error) {
+	service := core.NewService(cfg)
+	if err := service.Connect(); err != nil {
+		return nil, nil, err
+	}
+	defer service.Disconnect()
+	deadline := time.Now().Add(max(time.Duration(cfg.TimeoutMs)*time.Millisecond, 5*time.Second))
+	for !service.IsConnected() {
+		if message := service.LastConnectError(); message != "" {
+			return nil, nil, fmt.Errorf("connect: %s", message)
+		}
+		if time.Now().After(deadline) {
+			return nil, nil, fmt.Errorf("connect: timed out")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return regmap.LiveValues(context.Background(), service.Read, registerMap)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/profiles"
+
+	"github.com/spf13/cobra"
+)
+
+func profilesCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "List the connection and device profiles usable with --profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := profiles.ConnectionsFile()
+			if err != nil {
+				return err
+			}
+			connections, err := profiles.LoadConnections(path)
+			if err != nil {
+				return err
+			}
+			library, errs := profiles.Library()
+			for _, err := range errs {
+				fmt.Fprintln(os.Stderr, "warning:", err)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTITLE\tCONNECTION\tPOINTS\tSOURCE")
+			for _, connection := range connections {
+				fmt.Fprintf(w, "%s\t\t%s\t-\t%s\n", connection.Name, connectionSummary(connection.Protocol, connection.Serial, connection.TCP, connection.UnitID), path)
+			}
+			for _, profile := range library {
+				summary := connectionSummary(profile.Protocol,
+					config.SerialConfig{Speed: profile.Serial.Speed, DataBits: profile.Serial.DataBits, Parity: profile.Serial.Parity, StopBits: profile.Serial.StopBits},
+					config.TCPConfig{Port: profile.TCP.Port}, profile.UnitID)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", profile.Name, profile.Title, summary, len(profile.Map.Points), profile.Source)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if dir, err := profiles.UserDir(); err == nil {
+				fmt.Printf("\nUser device profiles: %s/*.yaml\n", dir)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "save NAME",
+		Short:   "Save the connection flags and decoders as a connection profile",
+		Example: "  gmm profiles save boiler --host 10.0.0.7 --unit-id 3 --f32 CDAB",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := profiles.ConnectionsFile()
+			if err != nil {
+				return err
+			}
+			if err := profiles.PutConnection(path, profiles.NewConnection(args[0], *cfg)); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %s to %s\n", args[0], path)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a connection profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := profiles.ConnectionsFile()
+			if err != nil {
+				return err
+			}
+			return profiles.DeleteConnection(path, args[0])
+		},
+	})
+	return cmd
+}
+
+func connectionSummary(protocol config.Protocol, serial config.SerialConfig, tcp config.TCPConfig, unitID uint8) string {
+	parts := []string{string(protocol)}
+	switch protocol {
+	case config.ProtocolRTU:
+		parity := "N"
+		switch serial.Parity {
+		case "even":
+			parity = "E"
+		case "odd":
+			parity = "O"
+		}
+		if serial.Device != "" {
+			parts = append(parts, serial.Device)
+		}
+		parts = append(parts, fmt.Sprintf("%d %d%s%d", serial.Speed, serial.DataBits, parity, serial.StopBits))
+	case config.ProtocolTCP:
+		if tcp.Host != "" {
+			parts = append(parts, fmt.Sprintf("%s:%d", tcp.Host, tcp.Port))
+		} else if tcp.Port != 0 {
+			parts = append(parts, fmt.Sprintf(":%d", tcp.Port))
+		}
+	}
+	if unitID != 0 {
+		parts = append(parts, fmt.Sprintf("unit %d", unitID))
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
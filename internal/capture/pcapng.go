@@ -0,0 +1,130 @@
+package capture
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	LinkTypeRaw   uint16 = 101
+	LinkTypeUser0 uint16 = 147
+)
+
+const (
+	blockSectionHeader  uint32 = 0x0A0D0D0A
+	blockInterfaceDesc  uint32 = 0x00000001
+	blockEnhancedPacket uint32 = 0x00000006
+	byteOrderMagic      uint32 = 0x1A2B3C4D
+	optEndOfOpt         uint16 = 0
+	optIfName           uint16 = 2
+	optEPBFlags         uint16 = 2
+	epbFlagInbound      uint32 = 0x1
+	epbFlagOutbound     uint32 = 0x2
+	defaultSnapLen      uint32 = 65535
+)
+
+type Direction int
+
+const (
+	Outbound Direction = iota
+	Inbound
+)
+
+// Writer emits a pcapng stream with one section and lazily declared interfaces.
+type Writer struct {
+	w          io.Writer
+	interfaces map[uint16]uint32
+}
+
+func NewWriter(w io.Writer) (*Writer, error) {
+	pw := &Writer{w: w, interfaces: map[uint16]uint32{}}
+	body := make([]byte, 16)
+	binary.LittleEndian.PutUint32(body[0:], byteOrderMagic)
+	binary.LittleEndian.PutUint16(body[4:], 1)
+	binary.LittleEndian.PutUint16(body[6:], 0)
+	binary.LittleEndian.PutUint64(body[8:], 0xFFFFFFFFFFFFFFFF)
+	if err := pw.writeBlock(blockSectionHeader, body); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *Writer) WritePacket(linkType uint16, ts time.Time, dir Direction, data []byte) error {
+	iface, err := pw.interfaceFor(linkType)
+	if err != nil {
+		return err
+	}
+	micros := uint64(ts.UnixMicro())
+	body := make([]byte, 20, 20+len(data)+16)
+	binary.LittleEndian.PutUint32(body[0:], iface)
+	binary.LittleEndian.PutUint32(body[4:], uint32(micros>>32))
+	binary.LittleEndian.PutUint32(body[8:], uint32(micros))
+	binary.LittleEndian.PutUint32(body[12:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(body[16:], uint32(len(data)))
+	body = append(body, pad(data)...)
+	flags := epbFlagOutbound
+	if dir == Inbound {
+		flags = epbFlagInbound
+	}
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, flags)
+	body = appendOption(body, optEPBFlags, value)
+	body = appendOption(body, optEndOfOpt, nil)
+	return pw.writeBlock(blockEnhancedPacket, body)
+}
+
+func (pw *Writer) interfaceFor(linkType uint16) (uint32, error) {
+	if id, ok := pw.interfaces[linkType]; ok {
+		return id, nil
+	}
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint16(body[0:], linkType)
+	binary.LittleEndian.PutUint32(body[4:], defaultSnapLen)
+	body = appendOption(body, optIfName, []byte(interfaceName(linkType)))
+	body = appendOption(body, optEndOfOpt, nil)
+	if err := pw.writeBlock(blockInterfaceDesc, body); err != nil {
+		return 0, err
+	}
+	id := uint32(len(pw.interfaces))
+	pw.interfaces[linkType] = id
+	return id, nil
+}
+
+func (pw *Writer) writeBlock(blockType uint32, body []byte) error {
+	total := uint32(12 + len(body))
+	buf := make([]byte, 0, total)
+	buf = binary.LittleEndian.AppendUint32(buf, blockType)
+	buf = binary.LittleEndian.AppendUint32(buf, total)
+	buf = append(buf, body...)
+	buf = binary.LittleEndian.AppendUint32(buf, total)
+	_, err := pw.w.Write(buf)
+	return err
+}
+
+func appendOption(buf []byte, code uint16, value []byte) []byte {
+	buf = binary.LittleEndian.AppendUint16(buf, code)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(value)))
+	return append(buf, pad(value)...)
+}
+
+func pad(data []byte) []byte {
+	rem := len(data) % 4
+	if rem == 0 {
+		return data
+	}
+	out := make([]byte, len(data), len(data)+4-rem)
+	copy(out, data)
+	return append(out, make([]byte, 4-rem)...)
+}
+
+func interfaceName(linkType uint16) string {
+	switch linkType {
+	case LinkTypeRaw:
+		return "gmm-tcp"
+	case LinkTypeUser0:
+		return "gmm-rtu"
+	default:
+		return "gmm"
+	}
+}
@@ -0,0 +1,239 @@
+package capture
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const clientPort = 50200
+
+var (
+	clientAddr   = net.IPv4(192, 0, 2, 1).To4()
+	fallbackAddr = net.IPv4(192, 0, 2, 2).To4()
+)
+
+type Protocol string
+
+const (
+	ProtocolTCP Protocol = "tcp"
+	ProtocolRTU Protocol = "rtu"
+)
+
+// Target describes the link a transaction travelled on.
+type Target struct {
+	Protocol Protocol
+	Host     string
+	Port     int
+}
+
+// Transaction is a single Modbus request with its optional response.
+// Request and Response hold PDUs (function code + data); a nil Response
+// means no reply was received.
+type Transaction struct {
+	Target       Target
+	UnitID       uint8
+	RequestTime  time.Time
+	Request      []byte
+	ResponseTime time.Time
+	Response     []byte
+}
+
+type Recorder struct {
+	mu        sync.Mutex
+	file      *os.File
+	writer    *Writer
+	path      string
+	packets   int
+	txID      uint16
+	clientSeq uint32
+	serverSeq uint32
+	ipID      uint16
+}
+
+func Create(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewWriter(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &Recorder{
+		file:      file,
+		writer:    writer,
+		path:      path,
+		clientSeq: 1,
+		serverSeq: 1,
+	}, nil
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+func (r *Recorder) Packets() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.packets
+}
+
+func (r *Recorder) Record(tx Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	if tx.Target.Protocol == ProtocolRTU {
+		return r.recordRTU(tx)
+	}
+	return r.recordTCP(tx)
+}
+
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *Recorder) recordRTU(tx Transaction) error {
+	if err := r.writer.WritePacket(LinkTypeUser0, tx.RequestTime, Outbound, RTUFrame(tx.UnitID, tx.Request)); err != nil {
+		return err
+	}
+	r.packets++
+	if tx.Response == nil {
+		return nil
+	}
+	if err := r.writer.WritePacket(LinkTypeUser0, tx.ResponseTime, Inbound, RTUFrame(tx.UnitID, tx.Response)); err != nil {
+		return err
+	}
+	r.packets++
+	return nil
+}
+
+func (r *Recorder) recordTCP(tx Transaction) error {
+	r.txID++
+	server := serverAddr(tx.Target.Host)
+	port := uint16(tx.Target.Port)
+
+	request := MBAPFrame(r.txID, tx.UnitID, tx.Request)
+	packet := r.ipPacket(clientAddr, server, clientPort, port, r.clientSeq, r.serverSeq, request)
+	if err := r.writer.WritePacket(LinkTypeRaw, tx.RequestTime, Outbound, packet); err != nil {
+		return err
+	}
+	r.clientSeq += uint32(len(request))
+	r.packets++
+	if tx.Response == nil {
+		return nil
+	}
+
+	response := MBAPFrame(r.txID, tx.UnitID, tx.Response)
+	packet = r.ipPacket(server, clientAddr, port, clientPort, r.serverSeq, r.clientSeq, response)
+	if err := r.writer.WritePacket(LinkTypeRaw, tx.ResponseTime, Inbound, packet); err != nil {
+		return err
+	}
+	r.serverSeq += uint32(len(response))
+	r.packets++
+	return nil
+}
+
+func (r *Recorder) ipPacket(src, dst net.IP, srcPort, dstPort uint16, seq, ack uint32, payload []byte) []byte {
+	r.ipID++
+	tcp := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	binary.BigEndian.PutUint32(tcp[8:], ack)
+	tcp[12] = 5 << 4
+	tcp[13] = 0x18 // PSH, ACK
+	binary.BigEndian.PutUint16(tcp[14:], 0xFFFF)
+	tcp = append(tcp, payload...)
+
+	pseudo := make([]byte, 0, 12+len(tcp))
+	pseudo = append(pseudo, src...)
+	pseudo = append(pseudo, dst...)
+	pseudo = append(pseudo, 0, 6)
+	pseudo = binary.BigEndian.AppendUint16(pseudo, uint16(len(tcp)))
+	pseudo = append(pseudo, tcp...)
+	binary.BigEndian.PutUint16(tcp[16:], checksum(pseudo))
+
+	ip := make([]byte, 20, 20+len(tcp))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+	binary.BigEndian.PutUint16(ip[4:], r.ipID)
+	ip[6] = 0x40 // don't fragment
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], src)
+	copy(ip[16:20], dst)
+	binary.BigEndian.PutUint16(ip[10:], checksum(ip))
+	return append(ip, tcp...)
+}
+
+// MBAPFrame wraps a PDU in a Modbus/TCP application header.
+func MBAPFrame(txID uint16, unitID uint8, pdu []byte) []byte {
+	frame := make([]byte, 7, 7+len(pdu))
+	binary.BigEndian.PutUint16(frame[0:], txID)
+	binary.BigEndian.PutUint16(frame[4:], uint16(len(pdu)+1))
+	frame[6] = unitID
+	return append(frame, pdu...)
+}
+
+// RTUFrame prefixes the unit id and appends the Modbus CRC.
+func RTUFrame(unitID uint8, pdu []byte) []byte {
+	frame := make([]byte, 0, len(pdu)+3)
+	frame = append(frame, unitID)
+	frame = append(frame, pdu...)
+	crc := CRC16(frame)
+	return append(frame, byte(crc), byte(crc>>8))
+}
+
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = (crc >> 1) ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+func checksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xFFFF) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
+
+func serverAddr(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		if v4 := ip.To4(); v4 != nil {
+			return v4
+		}
+	}
+	if host == "localhost" {
+		return net.IPv4(127, 0, 0, 1).To4()
+	}
+	return fallbackAddr
+}
@@ -0,0 +1,88 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRC16(t *testing.T) {
+	frame := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}
+	require.Equal(t, uint16(0xCDC5), CRC16(frame))
+}
+
+func TestRTUFrameAppendsCRCLowByteFirst(t *testing.T) {
+	frame := RTUFrame(0x01, []byte{0x03, 0x00, 0x00, 0x00, 0x0A})
+	require.Equal(t, []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}, frame)
+}
+
+func TestMBAPFrame(t *testing.T) {
+	frame := MBAPFrame(7, 0x11, []byte{0x03, 0x00, 0x6B, 0x00, 0x03})
+	require.Equal(t, []byte{0x00, 0x07, 0x00, 0x00, 0x00, 0x06, 0x11, 0x03, 0x00, 0x6B, 0x00, 0x03}, frame)
+}
+
+func TestWriterEmitsBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePacket(LinkTypeUser0, time.Unix(1, 0), Outbound, []byte{1, 2, 3}))
+	require.NoError(t, w.WritePacket(LinkTypeUser0, time.Unix(2, 0), Inbound, []byte{4, 5, 6, 7}))
+
+	types := blockTypes(t, buf.Bytes())
+	require.Equal(t, []uint32{blockSectionHeader, blockInterfaceDesc, blockEnhancedPacket, blockEnhancedPacket}, types)
+}
+
+func TestRecorderTCPWritesRequestAndResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.pcapng")
+	rec, err := Create(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+	target := Target{Protocol: ProtocolTCP, Host: "10.1.2.3", Port: 502}
+	require.NoError(t, rec.Record(Transaction{
+		Target:       target,
+		UnitID:       1,
+		RequestTime:  now,
+		Request:      []byte{0x03, 0x00, 0x00, 0x00, 0x01},
+		ResponseTime: now.Add(time.Millisecond),
+		Response:     []byte{0x03, 0x02, 0x12, 0x34},
+	}))
+	require.NoError(t, rec.Record(Transaction{
+		Target:      target,
+		UnitID:      1,
+		RequestTime: now,
+		Request:     []byte{0x03, 0x00, 0x00, 0x00, 0x01},
+	}))
+	require.Equal(t, 3, rec.Packets())
+	require.NoError(t, rec.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{blockSectionHeader, blockInterfaceDesc, blockEnhancedPacket, blockEnhancedPacket, blockEnhancedPacket}, blockTypes(t, data))
+}
+
+func TestIPChecksumValidates(t *testing.T) {
+	rec := &Recorder{}
+	packet := rec.ipPacket(clientAddr, fallbackAddr, clientPort, 502, 1, 1, []byte{1, 2, 3})
+	require.Equal(t, uint16(0), checksum(packet[:20]))
+}
+
+func blockTypes(t *testing.T, data []byte) []uint32 {
+	t.Helper()
+	types := []uint32{}
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 12)
+		blockType := binary.LittleEndian.Uint32(data[0:])
+		total := binary.LittleEndian.Uint32(data[4:])
+		require.Zero(t, total%4)
+		require.Equal(t, total, binary.LittleEndian.Uint32(data[total-4:]))
+		types = append(types, blockType)
+		data = data[total:]
+	}
+	return types
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -29,6 +31,14 @@ type WordOrder string
 
 type DecoderType string
 
+// ByteOrder names a multi-register byte layout the way vendor manuals do:
+// ABCD is the most significant byte first.
+type ByteOrder string
+
+type StringTrim string
+
+type StringEncoding string
+
 const (
 	EndianBig    Endianness = "big"
 	EndianLittle Endianness = "little"
@@ -40,9 +50,80 @@ const (
 	DecoderInt16   DecoderType = "int16"
 	DecoderUint32  DecoderType = "uint32"
 	DecoderInt32   DecoderType = "int32"
+	DecoderFloat16 DecoderType = "float16"
 	DecoderFloat32 DecoderType = "float32"
+	DecoderUint64  DecoderType = "uint64"
+	DecoderInt64   DecoderType = "int64"
+	DecoderFloat64 DecoderType = "float64"
+	DecoderQ16     DecoderType = "q16"
+	DecoderQ32     DecoderType = "q32"
+	DecoderString  DecoderType = "string"
+	DecoderBCD16   DecoderType = "bcd16"
+	DecoderBCD32   DecoderType = "bcd32"
+	DecoderBits    DecoderType = "bitfield"
+	DecoderEnum    DecoderType = "enum"
+	DecoderEpoch32 DecoderType = "epoch32"
+	DecoderEpoch64 DecoderType = "epoch64"
+	DecoderDate    DecoderType = "datetime"
+	DecoderPacked  DecoderType = "packed"
+
+	ByteOrderABCD ByteOrder = "ABCD"
+	ByteOrderCDAB ByteOrder = "CDAB"
+	ByteOrderBADC ByteOrder = "BADC"
+	ByteOrderDCBA ByteOrder = "DCBA"
+
+	TrimNone  StringTrim = "none"
+	TrimNull  StringTrim = "null"
+	TrimSpace StringTrim = "space"
+	TrimBoth  StringTrim = "both"
+
+	EncodingASCII  StringEncoding = "ascii"
+	EncodingUTF8   StringEncoding = "utf-8"
+	EncodingLatin1 StringEncoding = "latin-1"
 )
 
+var ByteOrders = []ByteOrder{ByteOrderABCD, ByteOrderCDAB, ByteOrderBADC, ByteOrderDCBA}
+
+// ParseByteOrder accepts a preset name in any case.
+func ParseByteOrder(value string) (ByteOrder, bool) {
+	order := ByteOrder(strings.ToUpper(strings.TrimSpace(value)))
+	for _, known := range ByteOrders {
+		if order == known {
+			return order, true
+		}
+	}
+	return "", false
+}
+
+// ByteOrder reports the preset equivalent to the decoder's endianness and
+// word order: little swaps bytes (BA), low-first swaps words (CD first).
+func (d DecoderConfig) ByteOrder() ByteOrder {
+	little := d.Endianness == EndianLittle
+	lowFirst := d.WordOrder == WordLowFirst
+	switch {
+	case little && lowFirst:
+		return ByteOrderDCBA
+	case little:
+		return ByteOrderBADC
+	case lowFirst:
+		return ByteOrderCDAB
+	default:
+		return ByteOrderABCD
+	}
+}
+
+// SetByteOrder sets endianness and word order from a preset.
+func (d *DecoderConfig) SetByteOrder(order ByteOrder) {
+	d.Endianness = EndianBig
+	d.WordOrder = WordHighFirst
+	if order == ByteOrderBADC || order == ByteOrderDCBA {
+		d.Endianness = EndianLittle
+	}
+	if order == ByteOrderCDAB || order == ByteOrderDCBA {
+		d.WordOrder = WordLowFirst
+	}
+}
+
 type SerialConfig struct {
 	Device   string `json:"device"`
 	Speed    uint   `json:"speed"`
@@ -57,10 +138,60 @@ type TCPConfig struct {
 }
 
 type DecoderConfig struct {
-	Type       DecoderType `json:"type"`
-	Endianness Endianness  `json:"endianness"`
-	WordOrder  WordOrder   `json:"wordOrder"`
-	Enabled    bool        `json:"enabled"`
+	Type       DecoderType    `json:"type"`
+	Endianness Endianness     `json:"endianness"`
+	WordOrder  WordOrder      `json:"wordOrder"`
+	Enabled    bool           `json:"enabled"`
+	Registers  int            `json:"registers,omitempty"`
+	Trim       StringTrim     `json:"trim,omitempty"`
+	Encoding   StringEncoding `json:"encoding,omitempty"`
+	BitNames   map[int]string `json:"bitNames,omitempty"`
+	Labels     map[int]string `json:"labels,omitempty"`
+	// FracBits is n in Qm.n fixed point; Unsigned reads the raw value as
+	// unsigned (UQm.n).
+	FracBits int  `json:"fracBits,omitempty"`
+	Unsigned bool `json:"unsigned,omitempty"`
+	// Millis reads epoch timestamps as milliseconds instead of seconds.
+	// Timezone is the IANA zone timestamps are rendered in (UTC when empty);
+	// packed date/time registers are taken as wall-clock time in that zone.
+	Millis   bool   `json:"millis,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	// Bits is how many consecutive coils or discrete inputs a packed decoder
+	// combines into one unsigned integer; the first one is bit 0 unless
+	// MSBFirst is set.
+	Bits     int  `json:"bits,omitempty"`
+	MSBFirst bool `json:"msbFirst,omitempty"`
+	// Scale, Offset and ScaleFactor convert numeric values into engineering
+	// units: raw × Scale × 10^SF + Offset, where SF is the int16 held in the
+	// ScaleFactor register (SunSpec-style _SF) of the same read.
+	Scale       float64 `json:"scale,omitempty"`
+	Offset      float64 `json:"offset,omitempty"`
+	Decimals    *int    `json:"decimals,omitempty"`
+	Unit        string  `json:"unit,omitempty"`
+	ScaleFactor *uint16 `json:"scaleFactor,omitempty"`
+}
+
+// Assignment pins a decoder to a register address instead of applying it to
+// the start of every read. Count > 1 repeats the decoder back-to-back. Kind
+// limits it to one read kind (e.g. "input_registers"); empty applies to all.
+// Mapped assignments come from the register map file and are not repeated as
+// --at flags in invocations.
+type Assignment struct {
+	Address     uint16        `json:"address"`
+	Count       int           `json:"count,omitempty"`
+	Name        string        `json:"name,omitempty"`
+	Kind        string        `json:"kind,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Mapped      bool          `json:"mapped,omitempty"`
+	Decoder     DecoderConfig `json:"decoder"`
+}
+
+type LogFileConfig struct {
+	Path        string `json:"path,omitempty"`
+	MaxSizeMB   int    `json:"maxSizeMb"`
+	MaxAgeHours int    `json:"maxAgeHours"`
+	MaxBackups  int    `json:"maxBackups"`
+	Compress    bool   `json:"compress"`
 }
 
 type Config struct {
@@ -76,9 +207,18 @@ type Config struct {
 	Serial        SerialConfig    `json:"serial"`
 	TCP           TCPConfig       `json:"tcp"`
 	Decoders      []DecoderConfig `json:"decoders"`
+	Assignments   []Assignment    `json:"assignments,omitempty"`
+	MapFile       string          `json:"mapFile,omitempty"`
+	Profile       string          `json:"profile,omitempty"`
 	ListenAddr    string          `json:"listenAddr"`
 	RequireToken  bool            `json:"requireToken"`
 	Token         string          `json:"token"`
+	CapturePath   string          `json:"capturePath,omitempty"`
+	LogFile       LogFileConfig   `json:"logFile"`
+	AutoSave      bool            `json:"autoSave,omitempty"`
+	// ConfigFile is the file the configuration was loaded from and is saved
+	// to; it is not stored in the file itself.
+	ConfigFile string `json:"configFile,omitempty"`
 }
 
 func DefaultConfig() Config {
@@ -108,10 +248,29 @@ func DefaultConfig() Config {
 			{Type: DecoderInt16, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
 			{Type: DecoderUint32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
 			{Type: DecoderInt32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderFloat16, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
 			{Type: DecoderFloat32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderUint64, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderInt64, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderFloat64, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderQ16, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false, FracBits: 15},
+			{Type: DecoderQ32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false, FracBits: 16},
+			{Type: DecoderBCD16, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderBCD32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderBits, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderEnum, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderEpoch32, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderEpoch64, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false},
+			{Type: DecoderDate, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false, Registers: 6},
+			{Type: DecoderPacked, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false, Bits: 4},
+			{Type: DecoderString, Endianness: EndianBig, WordOrder: WordHighFirst, Enabled: false, Registers: 8, Trim: TrimBoth, Encoding: EncodingASCII},
 		},
 		ListenAddr:   "0.0.0.0:8502",
 		RequireToken: true,
+		LogFile: LogFileConfig{
+			MaxSizeMB:  10,
+			MaxBackups: 10,
+		},
 	}
 }
 
@@ -194,7 +353,34 @@ func (c Config) invocation(includeWeb bool, includeAll bool) string {
 			}
 			flag, value, ok := decoderInvocation(decoder, defaultDecoders[decoder.Type])
 			if ok {
-				parts = append(parts, flag, value)
+				parts = append(parts, flag, shellQuote(value))
+			}
+		}
+		if c.Profile != "" {
+			parts = append(parts, "--profile", c.Profile)
+		}
+		if c.MapFile != "" {
+			parts = append(parts, "--map", c.MapFile)
+		}
+		for _, assignment := range c.Assignments {
+			if assignment.Mapped {
+				continue
+			}
+			parts = append(parts, "--at", assignmentInvocation(assignment, defaultDecoders[assignment.Decoder.Type]))
+		}
+		if c.LogFile.Path != "" {
+			parts = append(parts, "--log-file", c.LogFile.Path)
+			if c.LogFile.MaxSizeMB != defaults.LogFile.MaxSizeMB {
+				parts = append(parts, "--log-max-size", fmt.Sprintf("%d", c.LogFile.MaxSizeMB))
+			}
+			if c.LogFile.MaxAgeHours != defaults.LogFile.MaxAgeHours {
+				parts = append(parts, "--log-max-age", fmt.Sprintf("%d", c.LogFile.MaxAgeHours))
+			}
+			if c.LogFile.MaxBackups != defaults.LogFile.MaxBackups {
+				parts = append(parts, "--log-max-backups", fmt.Sprintf("%d", c.LogFile.MaxBackups))
+			}
+			if c.LogFile.Compress {
+				parts = append(parts, "--log-compress")
 			}
 		}
 		if includeWeb {
@@ -238,6 +424,53 @@ func (c Config) invocation(includeWeb bool, includeAll bool) string {
 	return strings.Join(parts, " ")
 }
 
+// ParseReadKind accepts a function code (01-04) or read kind name.
+func ParseReadKind(value string) (string, error) {
+	trimmed := strings.TrimSpace(strings.ToLower(value))
+	switch trimmed {
+	case "1", "01", "coils", "coil":
+		return "coils", nil
+	case "2", "02", "discrete_inputs", "discrete":
+		return "discrete_inputs", nil
+	case "3", "03", "holding_registers", "holding":
+		return "holding_registers", nil
+	case "4", "04", "input_registers", "input":
+		return "input_registers", nil
+	default:
+		return "", fmt.Errorf("unsupported function: %s", value)
+	}
+}
+
+// decoderAliases maps the short flag names (u16, f32, str, ...) to types.
+var decoderAliases = map[string]DecoderType{
+	"u16":  DecoderUint16,
+	"i16":  DecoderInt16,
+	"u32":  DecoderUint32,
+	"i32":  DecoderInt32,
+	"f16":  DecoderFloat16,
+	"f32":  DecoderFloat32,
+	"u64":  DecoderUint64,
+	"i64":  DecoderInt64,
+	"f64":  DecoderFloat64,
+	"bits": DecoderBits,
+	"str":  DecoderString,
+}
+
+// DefaultDecoder returns the default settings of a decoder type, accepting
+// the short flag names as well.
+func DefaultDecoder(name string) (DecoderConfig, bool) {
+	typ := DecoderType(strings.ToLower(strings.TrimSpace(name)))
+	if alias, ok := decoderAliases[string(typ)]; ok {
+		typ = alias
+	}
+	for _, decoder := range DefaultConfig().Decoders {
+		if decoder.Type == typ {
+			return decoder, true
+		}
+	}
+	return DecoderConfig{}, false
+}
+
 func readKindCode(kind string) string {
 	switch strings.ToLower(strings.TrimSpace(kind)) {
 	case "coils":
@@ -270,25 +503,64 @@ func formatBaseFlag(base ValueBase) string {
 	return "dec"
 }
 
-func decoderInvocation(decoder DecoderConfig, defaults DecoderConfig) (string, string, bool) {
-	if !decoder.Enabled {
-		return "", "", false
-	}
-	flag := ""
-	switch decoder.Type {
+// DecoderFlag is the name of the command-line flag enabling a decoder type,
+// or "" for types without one.
+func DecoderFlag(typ DecoderType) string {
+	switch typ {
 	case DecoderUint16:
-		flag = "--u16"
+		return "u16"
 	case DecoderInt16:
-		flag = "--i16"
+		return "i16"
 	case DecoderUint32:
-		flag = "--u32"
+		return "u32"
 	case DecoderInt32:
-		flag = "--i32"
+		return "i32"
+	case DecoderFloat16:
+		return "f16"
 	case DecoderFloat32:
-		flag = "--f32"
+		return "f32"
+	case DecoderUint64:
+		return "u64"
+	case DecoderInt64:
+		return "i64"
+	case DecoderFloat64:
+		return "f64"
+	case DecoderQ16:
+		return "q16"
+	case DecoderQ32:
+		return "q32"
+	case DecoderBCD16:
+		return "bcd16"
+	case DecoderBCD32:
+		return "bcd32"
+	case DecoderBits:
+		return "bits"
+	case DecoderEnum:
+		return "enum"
+	case DecoderEpoch32:
+		return "epoch32"
+	case DecoderEpoch64:
+		return "epoch64"
+	case DecoderDate:
+		return "datetime"
+	case DecoderPacked:
+		return "packed"
+	case DecoderString:
+		return "str"
 	default:
+		return ""
+	}
+}
+
+func decoderInvocation(decoder DecoderConfig, defaults DecoderConfig) (string, string, bool) {
+	if !decoder.Enabled {
+		return "", "", false
+	}
+	flag := DecoderFlag(decoder.Type)
+	if flag == "" {
 		return "", "", false
 	}
+	flag = "--" + flag
 	parts := []string{}
 	if decoder.Endianness != defaults.Endianness {
 		if decoder.Endianness == EndianLittle {
@@ -304,6 +576,61 @@ func decoderInvocation(decoder DecoderConfig, defaults DecoderConfig) (string, s
 			parts = append(parts, "hf")
 		}
 	}
+	if decoder.Type == DecoderString {
+		if decoder.Registers != defaults.Registers {
+			parts = append(parts, fmt.Sprintf("len=%d", decoder.Registers))
+		}
+		if decoder.Trim != defaults.Trim {
+			parts = append(parts, fmt.Sprintf("trim=%s", decoder.Trim))
+		}
+		if decoder.Encoding != defaults.Encoding {
+			parts = append(parts, fmt.Sprintf("enc=%s", decoder.Encoding))
+		}
+	}
+	if decoder.Type == DecoderQ16 || decoder.Type == DecoderQ32 {
+		if decoder.FracBits != defaults.FracBits {
+			parts = append(parts, fmt.Sprintf("n=%d", decoder.FracBits))
+		}
+		if decoder.Unsigned {
+			parts = append(parts, "unsigned")
+		}
+	}
+	if decoder.Type == DecoderDate && decoder.Registers != defaults.Registers {
+		parts = append(parts, fmt.Sprintf("len=%d", decoder.Registers))
+	}
+	if decoder.Millis {
+		parts = append(parts, "ms")
+	}
+	if decoder.Type == DecoderPacked {
+		parts = append(parts, fmt.Sprintf("bits=%d", decoder.Bits))
+		if decoder.MSBFirst {
+			parts = append(parts, "msb")
+		}
+	}
+	if decoder.Timezone != "" {
+		parts = append(parts, "tz="+decoder.Timezone)
+	}
+	if decoder.Scale != 0 && decoder.Scale != 1 {
+		parts = append(parts, "scale="+strconv.FormatFloat(decoder.Scale, 'g', -1, 64))
+	}
+	if decoder.Offset != 0 {
+		parts = append(parts, "offset="+strconv.FormatFloat(decoder.Offset, 'g', -1, 64))
+	}
+	if decoder.ScaleFactor != nil {
+		parts = append(parts, fmt.Sprintf("sf=%d", *decoder.ScaleFactor))
+	}
+	if decoder.Decimals != nil {
+		parts = append(parts, fmt.Sprintf("dec=%d", *decoder.Decimals))
+	}
+	if decoder.Unit != "" {
+		parts = append(parts, "unit="+decoder.Unit)
+	}
+	if decoder.Type == DecoderBits && len(decoder.BitNames) > 0 {
+		parts = append(parts, FormatLabels(decoder.BitNames))
+	}
+	if decoder.Type == DecoderEnum && len(decoder.Labels) > 0 {
+		parts = append(parts, FormatLabels(decoder.Labels))
+	}
 	if len(parts) == 0 {
 		if defaults.Endianness == EndianLittle {
 			parts = append(parts, "le")
@@ -313,3 +640,47 @@ func decoderInvocation(decoder DecoderConfig, defaults DecoderConfig) (string, s
 	}
 	return flag, strings.Join(parts, ","), true
 }
+
+// assignmentInvocation renders an assignment as
+// ADDR[:COUNT]=TYPE[,options][,name=NAME].
+func assignmentInvocation(assignment Assignment, defaults DecoderConfig) string {
+	target := fmt.Sprintf("%d", assignment.Address)
+	if assignment.Count > 1 {
+		target += fmt.Sprintf(":%d", assignment.Count)
+	}
+	decoder := assignment.Decoder
+	decoder.Enabled = true
+	_, options, _ := decoderInvocation(decoder, defaults)
+	value := fmt.Sprintf("%s=%s,%s", target, decoder.Type, options)
+	if assignment.Name != "" {
+		value += ",name=" + assignment.Name
+	}
+	return shellQuote(value)
+}
+
+// shellQuote single-quotes value when it holds anything a shell would split
+// or expand, such as spaces in a point name.
+func shellQuote(value string) string {
+	unsafe := func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("_@%+=:,./-", r))
+	}
+	if strings.IndexFunc(value, unsafe) < 0 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// FormatLabels renders a bit-name or enum table as "0=RUN,3=FAULT" in key
+// order. Validate refuses labels with commas, which this can't separate.
+func FormatLabels(names map[int]string) string {
+	bits := make([]int, 0, len(names))
+	for bit := range names {
+		bits = append(bits, bit)
+	}
+	sort.Ints(bits)
+	parts := make([]string, 0, len(bits))
+	for _, bit := range bits {
+		parts = append(parts, fmt.Sprintf("%d=%s", bit, names[bit]))
+	}
+	return strings.Join(parts, ",")
+}
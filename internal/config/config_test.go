@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvocationQuotesAssignmentNames(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Assignments = []Assignment{
+		{Address: 10, Name: "temp", Decoder: DecoderConfig{Type: DecoderUint16, Endianness: EndianBig, WordOrder: WordHighFirst}},
+		{Address: 20, Name: "phase 1, L-N", Decoder: DecoderConfig{Type: DecoderUint16, Endianness: EndianBig, WordOrder: WordHighFirst}},
+		{Address: 30, Name: "it's", Decoder: DecoderConfig{Type: DecoderUint16, Endianness: EndianBig, WordOrder: WordHighFirst}},
+	}
+	invocation := cfg.InvocationFullTUI()
+	require.Contains(t, invocation, "--at 10=uint16,be,name=temp")
+	require.Contains(t, invocation, "--at '20=uint16,be,name=phase 1, L-N'")
+	require.Contains(t, invocation, `--at '30=uint16,be,name=it'\''s'`)
+}
+
+func TestInvocationQuotesDecoderLabels(t *testing.T) {
+	cfg := DefaultConfig()
+	for i := range cfg.Decoders {
+		switch cfg.Decoders[i].Type {
+		case DecoderEnum:
+			cfg.Decoders[i].Enabled = true
+			cfg.Decoders[i].Labels = map[int]string{0: "Stopped", 1: "Not running"}
+		case DecoderUint16:
+			cfg.Decoders[i].Enabled = true
+			cfg.Decoders[i].Unit = "m³/h"
+		}
+	}
+	invocation := cfg.InvocationFullTUI()
+	require.Contains(t, invocation, "--enum '0=Stopped,1=Not running'")
+	require.Contains(t, invocation, "--u16 'unit=m³/h'")
+}
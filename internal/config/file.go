@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is where the configuration is saved when gmm was started
+// without --config.
+func DefaultFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gmm", "config.yaml"), nil
+}
+
+// configFile is a configuration as saved, with its schema version.
+type configFile struct {
+	Version int `json:"version"`
+	Config
+}
+
+// LoadFile reads a configuration saved by SaveFile, as JSON for .json files
+// and YAML otherwise, upgrading files saved by earlier builds. Settings
+// missing from the file keep their defaults, and decoders missing from it are
+// added with theirs. Mapped assignments are not stored; load MapFile and
+// Profile again to restore them.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	file := configFile{Config: DefaultConfig()}
+	defaults := file.Decoders
+	file.Decoders = nil
+	if err := ConfigMigrations.Unmarshal(path, data, &file); err != nil {
+		return Config{}, err
+	}
+	cfg := file.Config
+	for _, dec := range defaults {
+		found := false
+		for _, loaded := range cfg.Decoders {
+			if loaded.Type == dec.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cfg.Decoders = append(cfg.Decoders, dec)
+		}
+	}
+	cfg.ConfigFile = path
+	return cfg, nil
+}
+
+// SaveFile writes cfg to path in the format LoadFile reads, creating the
+// directory if needed. The web token, mapped assignments, ConfigFile and
+// CapturePath are left out, so a later start doesn't overwrite the capture.
+func SaveFile(path string, cfg Config) error {
+	cfg.Token = ""
+	cfg.ConfigFile = ""
+	cfg.CapturePath = ""
+	assignments := []Assignment{}
+	for _, assignment := range cfg.Assignments {
+		if !assignment.Mapped {
+			assignments = append(assignments, assignment)
+		}
+	}
+	cfg.Assignments = assignments
+	data, err := MarshalFile(path, configFile{Version: ConfigMigrations.Version(), Config: cfg})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// UnmarshalFile decodes the contents of path into v: JSON for .json files
+// and YAML with the same field names otherwise. Unknown fields are errors.
+func UnmarshalFile(path string, data []byte, v interface{}) error {
+	return unmarshalFile(path, data, nil, v)
+}
+
+// Unmarshal works like UnmarshalFile but first upgrades the file to the
+// current schema version; v must have a version field.
+func (m Migrations) Unmarshal(path string, data []byte, v interface{}) error {
+	return unmarshalFile(path, data, m, v)
+}
+
+func unmarshalFile(path string, data []byte, migrations Migrations, v interface{}) error {
+	if !isJSON(path) {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if doc == nil {
+			doc = map[string]interface{}{}
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if migrations != nil {
+		doc := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := migrations.Apply(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// MarshalFile encodes v in the format UnmarshalFile reads for path.
+func MarshalFile(path string, v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if isJSON(path) {
+		return append(data, '\n'), nil
+	}
+	// JSON is YAML, so decoding into a node keeps the field order; only the
+	// flow style has to go.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// Save writes cfg to its ConfigFile, or to DefaultFile when it has none, and
+// returns the path written.
+func Save(cfg Config) (string, error) {
+	path := cfg.ConfigFile
+	if path == "" {
+		var err error
+		if path, err = DefaultFile(); err != nil {
+			return "", err
+		}
+	}
+	return path, SaveFile(path, cfg)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveFileRoundTrip(t *testing.T) {
+	for _, name := range []string{"gmm.yaml", "gmm.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", name)
+			cfg := DefaultConfig()
+			cfg.Protocol = ProtocolRTU
+			cfg.Serial.Device = "/dev/ttyS1"
+			cfg.Serial.Parity = "even"
+			cfg.ReadAddress = 100
+			cfg.Token = "secret"
+			cfg.MapFile = "meter.yaml"
+			cfg.Decoders[2].Enabled = true
+			cfg.Assignments = []Assignment{
+				{Address: 10, Name: "pinned", Decoder: DecoderConfig{Type: DecoderFloat32, Enabled: true}},
+				{Address: 20, Name: "mapped", Mapped: true, Decoder: DecoderConfig{Type: DecoderUint16, Enabled: true}},
+			}
+			cfg.CapturePath = "2024"
+			require.NoError(t, SaveFile(path, cfg))
+
+			loaded, err := LoadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, path, loaded.ConfigFile)
+			require.Empty(t, loaded.Token)
+			require.Empty(t, loaded.CapturePath)
+			require.Len(t, loaded.Assignments, 1)
+			require.Equal(t, "pinned", loaded.Assignments[0].Name)
+
+			cfg.Token = ""
+			cfg.CapturePath = ""
+			cfg.Assignments = cfg.Assignments[:1]
+			cfg.ConfigFile = path
+			require.Equal(t, cfg, loaded)
+		})
+	}
+}
+
+func TestLoadFileKeepsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gmm.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`unitId: 5
+tcp:
+  host: plc.local
+decoders:
+  - { type: float32, endianness: little, wordOrder: high-first, enabled: true }
+`), 0o644))
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, uint8(5), cfg.UnitID)
+	require.Equal(t, "plc.local", cfg.TCP.Host)
+	require.Equal(t, 502, cfg.TCP.Port)
+	require.Len(t, cfg.Decoders, len(DefaultConfig().Decoders))
+	require.Equal(t, DecoderFloat32, cfg.Decoders[0].Type)
+	require.True(t, cfg.Decoders[0].Enabled)
+
+	require.NoError(t, os.WriteFile(path, []byte("unit: 5\n"), 0o644))
+	_, err = LoadFile(path)
+	require.ErrorContains(t, err, "unknown field")
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Migrations upgrade a decoded file one schema version at a time: step i
+// turns a version i document into version i+1, so files are current at
+// len(steps). Files without a version key are version 0.
+type Migrations []func(doc map[string]any) error
+
+// Version is the schema version files are upgraded to and saved with.
+func (m Migrations) Version() int {
+	return len(m)
+}
+
+// Apply upgrades doc to the current version and records it under "version".
+// Files from a newer gmm are refused rather than loaded with settings
+// dropped.
+func (m Migrations) Apply(doc map[string]any) error {
+	version, err := docVersion(doc)
+	if err != nil {
+		return err
+	}
+	if version > m.Version() {
+		return fmt.Errorf("schema version %d is newer than this gmm supports (%d)", version, m.Version())
+	}
+	for ; version < m.Version(); version++ {
+		if err := m[version](doc); err != nil {
+			return fmt.Errorf("migrating from schema version %d: %w", version, err)
+		}
+	}
+	doc["version"] = version
+	return nil
+}
+
+func docVersion(doc map[string]any) (int, error) {
+	switch value := doc["version"].(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		version, err := value.Int64()
+		if err != nil || version < 0 {
+			return 0, fmt.Errorf("invalid schema version %s", value)
+		}
+		return int(version), nil
+	default:
+		return 0, fmt.Errorf("invalid schema version %v", value)
+	}
+}
+
+// ConfigMigrations upgrade config files saved by earlier builds.
+var ConfigMigrations = Migrations{
+	configV1,
+}
+
+// configV1 normalizes values that unversioned files could hold but Validate
+// refuses: read kind aliases and function codes, upper-case protocol and
+// parity, and read quantities over the protocol limit.
+func configV1(doc map[string]any) error {
+	NormalizeConnection(doc)
+	kind := DefaultConfig().ReadKind
+	if value, ok := doc["readKind"].(string); ok {
+		parsed, err := ParseReadKind(value)
+		if err != nil {
+			return err
+		}
+		doc["readKind"] = parsed
+		kind = parsed
+	}
+	limit := MaxRegisterRead
+	if kind == "coils" || kind == "discrete_inputs" {
+		limit = MaxBitRead
+	}
+	if value, ok := doc["readQuantity"].(json.Number); ok {
+		if quantity, err := value.Int64(); err == nil && quantity > int64(limit) {
+			doc["readQuantity"] = limit
+		}
+	}
+	return nil
+}
+
+// NormalizeConnection lower-cases the protocol and parity of a connection
+// document, for migrations of files that store connection settings.
+func NormalizeConnection(doc map[string]any) {
+	lowerString(doc, "protocol")
+	if serial, ok := doc["serial"].(map[string]any); ok {
+		lowerString(serial, "parity")
+	}
+}
+
+func lowerString(doc map[string]any, key string) {
+	if value, ok := doc[key].(string); ok {
+		doc[key] = strings.ToLower(strings.TrimSpace(value))
+	}
+}
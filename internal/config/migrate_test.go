@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsApply(t *testing.T) {
+	steps := []int{}
+	migrations := Migrations{
+		func(doc map[string]any) error { steps = append(steps, 0); return nil },
+		func(doc map[string]any) error { steps = append(steps, 1); return nil },
+	}
+
+	doc := map[string]any{}
+	require.NoError(t, migrations.Apply(doc))
+	require.Equal(t, []int{0, 1}, steps)
+	require.Equal(t, 2, doc["version"])
+
+	steps = nil
+	doc = map[string]any{"version": json.Number("1")}
+	require.NoError(t, migrations.Apply(doc))
+	require.Equal(t, []int{1}, steps)
+
+	steps = nil
+	require.NoError(t, migrations.Apply(map[string]any{"version": json.Number("2")}))
+	require.Empty(t, steps)
+
+	require.ErrorContains(t, migrations.Apply(map[string]any{"version": json.Number("3")}), "newer than this gmm supports")
+	require.ErrorContains(t, migrations.Apply(map[string]any{"version": "two"}), "invalid schema version")
+
+	failing := Migrations{func(doc map[string]any) error { return errors.New("boom") }}
+	require.ErrorContains(t, failing.Apply(map[string]any{}), "migrating from schema version 0: boom")
+}
+
+func TestConfigV1(t *testing.T) {
+	doc := map[string]any{
+		"protocol":     "RTU",
+		"serial":       map[string]any{"parity": "Even"},
+		"readKind":     "3",
+		"readQuantity": json.Number("500"),
+	}
+	require.NoError(t, configV1(doc))
+	require.Equal(t, "rtu", doc["protocol"])
+	require.Equal(t, "even", doc["serial"].(map[string]any)["parity"])
+	require.Equal(t, "holding_registers", doc["readKind"])
+	require.Equal(t, MaxRegisterRead, doc["readQuantity"])
+
+	doc = map[string]any{"readKind": "coils", "readQuantity": json.Number("1500")}
+	require.NoError(t, configV1(doc))
+	require.Equal(t, json.Number("1500"), doc["readQuantity"])
+
+	doc = map[string]any{"readQuantity": json.Number("126")}
+	require.NoError(t, configV1(doc))
+	require.Equal(t, MaxRegisterRead, doc["readQuantity"])
+
+	require.Error(t, configV1(map[string]any{"readKind": "05"}))
+}
+
+func TestLoadFileMigrates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "old.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("protocol: TCP\nreadKind: input\nreadQuantity: 300\ntcp:\n  host: plc\n  port: 1502\n"), 0o644))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolTCP, cfg.Protocol)
+	require.Equal(t, "input_registers", cfg.ReadKind)
+	require.Equal(t, uint16(MaxRegisterRead), cfg.ReadQuantity)
+	require.Equal(t, "plc", cfg.TCP.Host)
+	require.NoError(t, cfg.Validate())
+
+	require.NoError(t, SaveFile(path, cfg))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "version: 1\n")
+
+	newer := filepath.Join(dir, "newer.json")
+	require.NoError(t, os.WriteFile(newer, []byte(`{"version": 99}`), 0o644))
+	_, err = LoadFile(newer)
+	require.ErrorContains(t, err, "schema version 99")
+}
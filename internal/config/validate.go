@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Protocol limits on the quantity of a single read.
+const (
+	MaxRegisterRead = 125
+	MaxBitRead      = 2000
+)
+
+// FieldError is a problem with one setting. Field is its JSON path (e.g.
+// "tcp.port") and Message reads after the setting's name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationError lists every invalid setting of a configuration.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, field := range e {
+		parts = append(parts, field.Error())
+	}
+	return "invalid configuration: " + strings.Join(parts, "; ")
+}
+
+// Message returns the problem with field, or "" when it is valid.
+func (e ValidationError) Message(field string) string {
+	for _, fieldErr := range e {
+		if fieldErr.Field == field {
+			return fieldErr.Message
+		}
+	}
+	return ""
+}
+
+// Validate checks the settings a connection and a read depend on. The
+// returned error is a ValidationError, or nil when the configuration is
+// usable.
+func (c Config) Validate() error {
+	var errs ValidationError
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	// Invocations separate labels with commas, so they can't hold one.
+	checkLabels := func(field string, decoder DecoderConfig) {
+		if labelsWithComma(decoder.BitNames) {
+			fail(field+".bitNames", "must not contain commas")
+		}
+		if labelsWithComma(decoder.Labels) {
+			fail(field+".labels", "must not contain commas")
+		}
+	}
+
+	switch c.Protocol {
+	case ProtocolTCP:
+		if strings.TrimSpace(c.TCP.Host) == "" {
+			fail("tcp.host", "is required")
+		}
+	case ProtocolRTU:
+		if strings.TrimSpace(c.Serial.Device) == "" {
+			fail("serial.device", "is required")
+		}
+	default:
+		fail("protocol", "must be tcp or rtu, not %q", c.Protocol)
+	}
+	if c.TCP.Port < 1 || c.TCP.Port > 0xffff {
+		fail("tcp.port", "must be 1-65535")
+	}
+	if c.Serial.Speed == 0 {
+		fail("serial.speed", "must be > 0")
+	}
+	if c.Serial.DataBits < 5 || c.Serial.DataBits > 8 {
+		fail("serial.dataBits", "must be 5-8")
+	}
+	switch c.Serial.Parity {
+	case "none", "even", "odd":
+	default:
+		fail("serial.parity", "must be none, even or odd")
+	}
+	if c.Serial.StopBits != 1 && c.Serial.StopBits != 2 {
+		fail("serial.stopBits", "must be 1 or 2")
+	}
+	if c.TimeoutMs <= 0 {
+		fail("timeoutMs", "must be > 0")
+	}
+
+	kind, err := ParseReadKind(c.ReadKind)
+	if err != nil || kind != c.ReadKind {
+		fail("readKind", "must be coils, discrete_inputs, holding_registers or input_registers")
+	} else {
+		limit := MaxRegisterRead
+		if kind == "coils" || kind == "discrete_inputs" {
+			limit = MaxBitRead
+		}
+		if c.ReadQuantity < 1 || int(c.ReadQuantity) > limit {
+			fail("readQuantity", "must be 1-%d for %s", limit, kind)
+		} else if int(c.ReadAddress)+int(c.ReadQuantity)-1 > 0xffff+int(c.AddressBase) {
+			fail("readAddress", "must leave room for %d items before 65535", c.ReadQuantity)
+		}
+	}
+	if c.AddressBase != AddressBaseZero && c.AddressBase != AddressBaseOne {
+		fail("addressBase", "must be 0 or 1")
+	}
+	if c.AddressFormat != ValueBaseDec && c.AddressFormat != ValueBaseHex {
+		fail("addressFormat", "must be 10 or 16")
+	}
+	if c.ValueBase != ValueBaseDec && c.ValueBase != ValueBaseHex {
+		fail("valueBase", "must be 10 or 16")
+	}
+
+	for i, decoder := range c.Decoders {
+		field := fmt.Sprintf("decoders[%d]", i)
+		if !knownDecoder(decoder.Type) {
+			fail(field+".type", "%q is not a decoder type", decoder.Type)
+		}
+		if decoder.Endianness != EndianBig && decoder.Endianness != EndianLittle {
+			fail(field+".endianness", "must be big or little")
+		}
+		if decoder.WordOrder != WordHighFirst && decoder.WordOrder != WordLowFirst {
+			fail(field+".wordOrder", "must be high-first or low-first")
+		}
+		checkLabels(field, decoder)
+	}
+	for i, assignment := range c.Assignments {
+		checkLabels(fmt.Sprintf("assignments[%d].decoder", i), assignment.Decoder)
+	}
+
+	if c.LogFile.MaxSizeMB < 0 {
+		fail("logFile.maxSizeMb", "must be >= 0")
+	}
+	if c.LogFile.MaxAgeHours < 0 {
+		fail("logFile.maxAgeHours", "must be >= 0")
+	}
+	if c.LogFile.MaxBackups < 0 {
+		fail("logFile.maxBackups", "must be >= 0")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func labelsWithComma(labels map[int]string) bool {
+	for _, label := range labels {
+		if strings.Contains(label, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+func knownDecoder(typ DecoderType) bool {
+	for _, decoder := range DefaultConfig().Decoders {
+		if decoder.Type == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+
+	cfg := DefaultConfig()
+	cfg.Protocol = ProtocolRTU
+	cfg.ReadKind = "coils"
+	cfg.ReadQuantity = MaxBitRead
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateFieldErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Protocol = "ascii"
+	cfg.Serial.Speed = 0
+	cfg.Serial.Parity = "mark"
+	cfg.TCP.Port = 0
+	cfg.TimeoutMs = 0
+	cfg.ReadQuantity = 126
+	cfg.ValueBase = 8
+	cfg.Decoders[1].WordOrder = "middle"
+	cfg.LogFile.MaxBackups = -1
+	cfg.Assignments = []Assignment{{Decoder: DecoderConfig{Type: DecoderEnum, Labels: map[int]string{1: "on, idle"}}}}
+
+	err := cfg.Validate()
+	var invalid ValidationError
+	require.ErrorAs(t, err, &invalid)
+	fields := []string{}
+	for _, fieldErr := range invalid {
+		fields = append(fields, fieldErr.Field)
+	}
+	require.Equal(t, []string{
+		"protocol",
+		"tcp.port",
+		"serial.speed",
+		"serial.parity",
+		"timeoutMs",
+		"readQuantity",
+		"valueBase",
+		"decoders[1].wordOrder",
+		"assignments[0].decoder.labels",
+		"logFile.maxBackups",
+	}, fields)
+	require.Equal(t, "must be 1-125 for holding_registers", invalid.Message("readQuantity"))
+	require.Empty(t, invalid.Message("unitId"))
+	require.ErrorContains(t, err, "tcp.port must be 1-65535")
+}
+
+func TestValidateReadRange(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReadAddress = 0xffff
+	cfg.ReadQuantity = 2
+	var invalid ValidationError
+	require.ErrorAs(t, cfg.Validate(), &invalid)
+	require.Equal(t, "readAddress", invalid[0].Field)
+
+	cfg.AddressBase = AddressBaseOne
+	require.NoError(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Protocol = ProtocolTCP
+	cfg.TCP.Host = " "
+	cfg.ReadKind = "holding"
+	require.ErrorAs(t, cfg.Validate(), &invalid)
+	require.Equal(t, "is required", invalid.Message("tcp.host"))
+	require.NotEmpty(t, invalid.Message("readKind"))
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"gomodmaster/internal/config"
+)
+
+// AddressValue is one value decoded at the address an assignment pins it to.
+type AddressValue struct {
+	Address uint16             `json:"address"`
+	Span    int                `json:"span"`
+	Name    string             `json:"name,omitempty"`
+	Type    config.DecoderType `json:"type"`
+	Value   interface{}        `json:"value,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// DecodeAssignments decodes every assigned value that starts inside the read
+// block [address, address+len(regs)). Values are returned in address order;
+// a value overlapping an earlier one is dropped, and one running past the end
+// of the block is reported with ErrShortRegisters.
+func DecodeAssignments(address uint16, regs []uint16, assignments []config.Assignment) []AddressValue {
+	return assignedValues(address, len(regs), assignments, registerDecoder, func(start uint16, span int, assignment config.Assignment) AddressValue {
+		return decodeAssigned(address, regs, start, span, assignment)
+	})
+}
+
+// AssignmentsFor returns the assignments that apply to reads of kind: those
+// without a kind and those of exactly that kind.
+func AssignmentsFor(kind ReadKind, assignments []config.Assignment) []config.Assignment {
+	out := make([]config.Assignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		if assignment.Kind == "" || ReadKind(assignment.Kind) == kind {
+			out = append(out, assignment)
+		}
+	}
+	return out
+}
+
+// assignedValues places the assignments accepted by include on a block of
+// length values at address and decodes them with decode.
+func assignedValues(address uint16, length int, assignments []config.Assignment, include func(config.DecoderConfig) bool,
+	decode func(start uint16, span int, assignment config.Assignment) AddressValue) []AddressValue {
+	out := []AddressValue{}
+	end := int(address) + length
+	for _, assignment := range assignments {
+		if !include(assignment.Decoder) {
+			continue
+		}
+		span := RegisterSpan(assignment.Decoder)
+		count := assignment.Count
+		if count < 1 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			start := int(assignment.Address) + i*span
+			if start < int(address) || start >= end {
+				continue
+			}
+			out = append(out, decode(uint16(start), span, assignment))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Address < out[j].Address
+	})
+	kept := out[:0]
+	next := int(address)
+	for _, value := range out {
+		if int(value.Address) < next {
+			continue
+		}
+		kept = append(kept, value)
+		next = int(value.Address) + value.Span
+	}
+	return kept
+}
+
+func registerDecoder(decoder config.DecoderConfig) bool {
+	return decoder.Type != config.DecoderPacked
+}
+
+func decodeAssigned(address uint16, regs []uint16, start uint16, span int, assignment config.Assignment) AddressValue {
+	decoded := AddressValue{Address: start, Span: span, Name: assignment.Name, Type: assignment.Decoder.Type}
+	value, err := DecodeAt(regs[start-address:], assignment.Decoder)
+	if err == nil {
+		value, err = ApplyScaling(value, assignment.Decoder, address, regs)
+	}
+	if errors.Is(err, ErrShortRegisters) {
+		decoded.Span = len(regs) - int(start-address)
+	}
+	if err != nil {
+		decoded.Error = err.Error()
+		return decoded
+	}
+	decoded.Value = value
+	return decoded
+}
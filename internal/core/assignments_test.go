@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAssignmentsMixesTypesInOneBlock(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 102, Decoder: config.DecoderConfig{Type: config.DecoderFloat32}},
+		{Address: 100, Name: "temp", Decoder: config.DecoderConfig{Type: config.DecoderInt16}},
+		{Address: 104, Decoder: config.DecoderConfig{Type: config.DecoderString, Registers: 2}},
+	}
+	regs := []uint16{0xFFFE, 0, 0x4049, 0x0FDB, 0x4142, 0x4344}
+	values := DecodeAssignments(100, regs, assignments)
+
+	require.Len(t, values, 3)
+	require.Equal(t, AddressValue{Address: 100, Span: 1, Name: "temp", Type: config.DecoderInt16, Value: int16(-2)}, values[0])
+	require.Equal(t, uint16(102), values[1].Address)
+	require.Equal(t, 2, values[1].Span)
+	require.InDelta(t, 3.14159, values[1].Value, 1e-5)
+	require.Equal(t, "ABCD", values[2].Value)
+}
+
+func TestDecodeAssignmentsRepeatsOverRange(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 8, Count: 3, Decoder: config.DecoderConfig{Type: config.DecoderUint32}},
+	}
+	values := DecodeAssignments(10, []uint16{0, 1, 0, 2, 0}, assignments)
+
+	require.Len(t, values, 2)
+	require.Equal(t, uint32(1), values[0].Value)
+	require.Equal(t, uint16(12), values[1].Address)
+	require.Equal(t, uint32(2), values[1].Value)
+}
+
+func TestDecodeAssignmentsReportsTruncatedValue(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 1, Decoder: config.DecoderConfig{Type: config.DecoderFloat64}},
+	}
+	values := DecodeAssignments(0, []uint16{0, 0, 0}, assignments)
+
+	require.Len(t, values, 1)
+	require.Equal(t, 2, values[0].Span)
+	require.Equal(t, ErrShortRegisters.Error(), values[0].Error)
+}
+
+func TestDecodeAssignmentsDropsOverlaps(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 0, Decoder: config.DecoderConfig{Type: config.DecoderUint32}},
+		{Address: 1, Decoder: config.DecoderConfig{Type: config.DecoderUint16}},
+	}
+	values := DecodeAssignments(0, []uint16{0, 5, 6}, assignments)
+
+	require.Len(t, values, 1)
+	require.Equal(t, config.DecoderUint32, values[0].Type)
+}
+
+func TestAssignmentsForFiltersByKind(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 1, Decoder: config.DecoderConfig{Type: config.DecoderUint16}},
+		{Address: 2, Kind: "input_registers", Decoder: config.DecoderConfig{Type: config.DecoderUint16}},
+		{Address: 3, Kind: "holding_registers", Decoder: config.DecoderConfig{Type: config.DecoderUint16}},
+	}
+	filtered := AssignmentsFor(ReadInput, assignments)
+	require.Len(t, filtered, 2)
+	require.Equal(t, uint16(1), filtered[0].Address)
+	require.Equal(t, uint16(2), filtered[1].Address)
+}
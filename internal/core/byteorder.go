@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gomodmaster/internal/config"
+)
+
+// ByteOrderCandidate is one decoding of the registers at Offset with Order.
+type ByteOrderCandidate struct {
+	Order  config.ByteOrder `json:"order"`
+	Offset int              `json:"offset"`
+	Value  float64          `json:"value"`
+	Delta  float64          `json:"delta"`
+}
+
+// DetectByteOrder decodes every offset of regs with decoder under every
+// byte-order preset, keeping its other settings (Q fraction bits), and ranks the results by how close they come to expected, best first.
+// Candidates decoding to NaN or infinity are skipped, and orders that decode
+// an offset to the same value as an earlier one (every order, for one-register
+// integers) are listed once.
+func DetectByteOrder(regs []uint16, decoder config.DecoderConfig, expected float64) ([]ByteOrderCandidate, error) {
+	switch decoder.Type {
+	case config.DecoderString, config.DecoderBits, config.DecoderEnum,
+		config.DecoderEpoch32, config.DecoderEpoch64, config.DecoderDate, config.DecoderPacked:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, decoder.Type)
+	}
+	span := RegisterSpan(decoder)
+	if len(regs) < span {
+		return nil, ErrShortRegisters
+	}
+	candidates := []ByteOrderCandidate{}
+	for offset := 0; offset+span <= len(regs); offset++ {
+		seen := map[float64]bool{}
+		for _, order := range config.ByteOrders {
+			decoder.SetByteOrder(order)
+			raw, err := DecodeAt(regs[offset:], decoder)
+			if err != nil {
+				continue
+			}
+			value, _ := numericValue(raw)
+			if math.IsNaN(value) || math.IsInf(value, 0) || seen[value] {
+				continue
+			}
+			seen[value] = true
+			candidates = append(candidates, ByteOrderCandidate{
+				Order:  order,
+				Offset: offset,
+				Value:  value,
+				Delta:  math.Abs(value - expected),
+			})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Delta < candidates[j].Delta
+	})
+	return candidates, nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteOrderPresetsRoundTrip(t *testing.T) {
+	for _, order := range config.ByteOrders {
+		var decoder config.DecoderConfig
+		decoder.SetByteOrder(order)
+		require.Equal(t, order, decoder.ByteOrder())
+	}
+}
+
+func TestByteOrderPresetsDecode(t *testing.T) {
+	regs := []uint16{0x4142, 0x4344}
+	cases := map[config.ByteOrder][]byte{
+		config.ByteOrderABCD: []byte("ABCD"),
+		config.ByteOrderCDAB: []byte("CDAB"),
+		config.ByteOrderBADC: []byte("BADC"),
+		config.ByteOrderDCBA: []byte("DCBA"),
+	}
+	for order, expected := range cases {
+		var decoder config.DecoderConfig
+		decoder.SetByteOrder(order)
+		require.Equal(t, expected, orderedBytes(regs, 2, decoder), order)
+	}
+}
+
+func TestDetectByteOrderFindsWordSwappedFloat(t *testing.T) {
+	bits := math.Float32bits(23.5)
+	regs := []uint16{0x0000, uint16(bits & 0xffff), uint16(bits >> 16)}
+	candidates, err := DetectByteOrder(regs, config.DecoderConfig{Type: config.DecoderFloat32}, 23.5)
+
+	require.NoError(t, err)
+	require.Equal(t, config.ByteOrderCDAB, candidates[0].Order)
+	require.Equal(t, 1, candidates[0].Offset)
+	require.Zero(t, candidates[0].Delta)
+}
+
+func TestDetectByteOrderKeepsFracBits(t *testing.T) {
+	decoder := config.DecoderConfig{Type: config.DecoderQ16, FracBits: 8}
+	candidates, err := DetectByteOrder([]uint16{0x800c}, decoder, 12.5)
+
+	require.NoError(t, err)
+	require.Equal(t, config.ByteOrderBADC, candidates[0].Order)
+	require.Zero(t, candidates[0].Delta)
+}
+
+func TestDetectByteOrderRejectsNonNumericTypes(t *testing.T) {
+	_, err := DetectByteOrder([]uint16{0x4142}, config.DecoderConfig{Type: config.DecoderString}, 1)
+
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestDetectByteOrderListsEqualDecodingsOnce(t *testing.T) {
+	candidates, err := DetectByteOrder([]uint16{0x0102}, config.DecoderConfig{Type: config.DecoderUint16}, 258)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.Equal(t, config.ByteOrderABCD, candidates[0].Order)
+
+	candidates, err = DetectByteOrder([]uint16{0x3c00}, config.DecoderConfig{Type: config.DecoderFloat16}, 1)
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	require.Equal(t, config.ByteOrderABCD, candidates[0].Order)
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"gomodmaster/internal/capture"
+	"gomodmaster/internal/config"
+
+	"github.com/simonvetter/modbus"
+)
+
+var ErrCaptureActive = errors.New("capture already running")
+
+type CaptureStatus struct {
+	Active  bool   `json:"active"`
+	Path    string `json:"path,omitempty"`
+	Packets int    `json:"packets"`
+}
+
+func (s *Service) StartCapture(path string) error {
+	if path == "" {
+		path = DefaultCapturePath(time.Now())
+	}
+	s.mu.Lock()
+	if s.capture != nil {
+		s.mu.Unlock()
+		return ErrCaptureActive
+	}
+	s.mu.Unlock()
+
+	recorder, err := capture.Create(path)
+	if err != nil {
+		s.logError(fmt.Sprintf("capture failed: %v", err))
+		return err
+	}
+
+	s.mu.Lock()
+	if s.capture != nil {
+		s.mu.Unlock()
+		_ = recorder.Close()
+		return ErrCaptureActive
+	}
+	s.capture = recorder
+	s.mu.Unlock()
+
+	s.logInfo(fmt.Sprintf("capture started: %s", path))
+	s.emitCapture()
+	return nil
+}
+
+func (s *Service) StopCapture() error {
+	s.mu.Lock()
+	recorder := s.capture
+	s.capture = nil
+	s.mu.Unlock()
+
+	if recorder == nil {
+		return nil
+	}
+	err := recorder.Close()
+	s.logInfo(fmt.Sprintf("capture stopped: %s (%d packets)", recorder.Path(), recorder.Packets()))
+	s.emitCapture()
+	return err
+}
+
+func (s *Service) CaptureStatus() CaptureStatus {
+	s.mu.Lock()
+	recorder := s.capture
+	s.mu.Unlock()
+	if recorder == nil {
+		return CaptureStatus{}
+	}
+	return CaptureStatus{Active: true, Path: recorder.Path(), Packets: recorder.Packets()}
+}
+
+func DefaultCapturePath(now time.Time) string {
+	return fmt.Sprintf("gmm-%s.pcapng", now.Format("20060102-150405"))
+}
+
+func (s *Service) emitCapture() {
+	s.emit(Event{Type: EventCapture, Payload: s.CaptureStatus()})
+}
+
+func (s *Service) recordCapture(cfg config.Config, req ReadRequest, addr uint16, unit uint8, sent time.Time, result ReadResult, err error) {
+	s.mu.Lock()
+	recorder := s.capture
+	s.mu.Unlock()
+	if recorder == nil {
+		return
+	}
+	tx := capture.Transaction{
+		Target:      captureTarget(cfg),
+		UnitID:      unit,
+		RequestTime: sent,
+		Request:     requestPDU(req.Kind, addr, req.Quantity),
+	}
+	if tx.Request == nil {
+		return
+	}
+	tx.ResponseTime = result.CompletedAt
+	if err != nil {
+		tx.Response = exceptionPDU(req.Kind, err)
+	} else {
+		tx.Response = responsePDU(req.Kind, result)
+	}
+	if err := recorder.Record(tx); err != nil {
+		s.logError(fmt.Sprintf("capture write failed: %v", err))
+		return
+	}
+	s.emitCapture()
+}
+
+func captureTarget(cfg config.Config) capture.Target {
+	if cfg.Protocol == config.ProtocolRTU {
+		return capture.Target{Protocol: capture.ProtocolRTU}
+	}
+	return capture.Target{Protocol: capture.ProtocolTCP, Host: cfg.TCP.Host, Port: cfg.TCP.Port}
+}
+
+func functionByte(kind ReadKind) byte {
+	switch kind {
+	case ReadCoils:
+		return 0x01
+	case ReadDiscreteInputs:
+		return 0x02
+	case ReadHolding:
+		return 0x03
+	case ReadInput:
+		return 0x04
+	default:
+		return 0
+	}
+}
+
+func requestPDU(kind ReadKind, addr uint16, quantity uint16) []byte {
+	fc := functionByte(kind)
+	if fc == 0 {
+		return nil
+	}
+	pdu := []byte{fc}
+	pdu = binary.BigEndian.AppendUint16(pdu, addr)
+	return binary.BigEndian.AppendUint16(pdu, quantity)
+}
+
+func responsePDU(kind ReadKind, result ReadResult) []byte {
+	fc := functionByte(kind)
+	switch kind {
+	case ReadCoils, ReadDiscreteInputs:
+		packed := make([]byte, (len(result.BoolValues)+7)/8)
+		for idx, value := range result.BoolValues {
+			if value {
+				packed[idx/8] |= 1 << (idx % 8)
+			}
+		}
+		return append([]byte{fc, byte(len(packed))}, packed...)
+	default:
+		pdu := []byte{fc, byte(len(result.RegValues) * 2)}
+		for _, reg := range result.RegValues {
+			pdu = binary.BigEndian.AppendUint16(pdu, reg)
+		}
+		return pdu
+	}
+}
+
+// exceptionPDU rebuilds the exception reply for Modbus errors; transport
+// failures produced no reply and yield nil.
+func exceptionPDU(kind ReadKind, err error) []byte {
+	codes := map[modbus.Error]byte{
+		modbus.ErrIllegalFunction:         0x01,
+		modbus.ErrIllegalDataAddress:      0x02,
+		modbus.ErrIllegalDataValue:        0x03,
+		modbus.ErrServerDeviceFailure:     0x04,
+		modbus.ErrAcknowledge:             0x05,
+		modbus.ErrServerDeviceBusy:        0x06,
+		modbus.ErrMemoryParityError:       0x08,
+		modbus.ErrGWPathUnavailable:       0x0A,
+		modbus.ErrGWTargetFailedToRespond: 0x0B,
+	}
+	var mbErr modbus.Error
+	if !errors.As(err, &mbErr) {
+		return nil
+	}
+	code, ok := codes[mbErr]
+	if !ok {
+		return nil
+	}
+	return []byte{functionByte(kind) | 0x80, code}
+}
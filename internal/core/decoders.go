@@ -2,24 +2,69 @@ package core
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
+	"strings"
+	"unicode/utf8"
 
 	"gomodmaster/internal/config"
 )
 
+var (
+	ErrShortRegisters  = errors.New("not enough registers")
+	ErrUnsupportedType = errors.New("unsupported decoder type")
+)
+
+// Bitfield is a status register split into its 16 bits. Flags lists the
+// configured names of the set bits, lowest bit first.
+type Bitfield struct {
+	Raw   uint16   `json:"raw"`
+	Bits  string   `json:"bits"`
+	Flags []string `json:"flags,omitempty"`
+}
+
+func (b Bitfield) String() string {
+	if len(b.Flags) == 0 {
+		return b.Bits
+	}
+	return b.Bits + "  " + strings.Join(b.Flags, ", ")
+}
+
+// Enum is a state register mapped through the decoder's label table.
+type Enum struct {
+	Raw   uint16 `json:"raw"`
+	Label string `json:"label"`
+	Known bool   `json:"known"`
+}
+
+func (e Enum) String() string {
+	return e.Label
+}
+
 type DecodedValue struct {
 	Type  config.DecoderType `json:"type"`
 	Value interface{}        `json:"value"`
+	Error string             `json:"error,omitempty"`
 }
 
-func DecodeValues(regs []uint16, decoders []config.DecoderConfig) []DecodedValue {
+// DecodeValues decodes the first value of every enabled decoder from a read
+// block starting at address, applying engineering-unit scaling.
+func DecodeValues(address uint16, regs []uint16, decoders []config.DecoderConfig) []DecodedValue {
 	out := make([]DecodedValue, 0, len(decoders))
 	for _, decoder := range decoders {
 		if !decoder.Enabled {
 			continue
 		}
-		value, ok := decodeWithConfig(regs, decoder)
-		if !ok {
+		value, err := DecodeAt(regs, decoder)
+		if errors.Is(err, ErrShortRegisters) || errors.Is(err, ErrUnsupportedType) {
+			continue
+		}
+		if err == nil {
+			value, err = ApplyScaling(value, decoder, address, regs)
+		}
+		if err != nil {
+			out = append(out, DecodedValue{Type: decoder.Type, Error: err.Error()})
 			continue
 		}
 		out = append(out, DecodedValue{Type: decoder.Type, Value: value})
@@ -27,47 +72,113 @@ func DecodeValues(regs []uint16, decoders []config.DecoderConfig) []DecodedValue
 	return out
 }
 
-func decodeWithConfig(regs []uint16, decoder config.DecoderConfig) (interface{}, bool) {
+// RegisterSpan reports how many registers a single value of the decoder occupies.
+func RegisterSpan(decoder config.DecoderConfig) int {
 	switch decoder.Type {
-	case config.DecoderUint16:
-		if len(regs) < 1 {
-			return nil, false
+	case config.DecoderUint32, config.DecoderInt32, config.DecoderFloat32, config.DecoderBCD32, config.DecoderQ32,
+		config.DecoderEpoch32:
+		return 2
+	case config.DecoderUint64, config.DecoderInt64, config.DecoderFloat64, config.DecoderEpoch64:
+		return 4
+	case config.DecoderDate:
+		if decoder.Registers == 3 {
+			return 3
 		}
-		return regs[0], true
-	case config.DecoderInt16:
-		if len(regs) < 1 {
-			return nil, false
+		return 6
+	case config.DecoderPacked:
+		// Coils or discrete inputs rather than registers.
+		if decoder.Bits < 1 {
+			return 1
+		}
+		return min(decoder.Bits, 64)
+	case config.DecoderString:
+		if decoder.Registers < 1 {
+			return 1
 		}
-		return int16(regs[0]), true
+		return decoder.Registers
+	default:
+		return 1
+	}
+}
+
+// DecodeAt decodes one value from the start of regs. It returns
+// ErrShortRegisters when regs is shorter than the decoder span.
+func DecodeAt(regs []uint16, decoder config.DecoderConfig) (interface{}, error) {
+	if len(regs) < RegisterSpan(decoder) {
+		return nil, ErrShortRegisters
+	}
+	switch decoder.Type {
+	case config.DecoderUint16:
+		return regs[0], nil
+	case config.DecoderInt16:
+		return int16(regs[0]), nil
 	case config.DecoderUint32:
-		value, ok := decodeUint32(regs, decoder)
-		return value, ok
+		return decodeUint32(regs, decoder), nil
 	case config.DecoderInt32:
-		value, ok := decodeUint32(regs, decoder)
-		if !ok {
-			return nil, false
-		}
-		return int32(value), true
+		return int32(decodeUint32(regs, decoder)), nil
+	case config.DecoderFloat16:
+		return float16ToFloat32(binary.BigEndian.Uint16(orderedBytes(regs, 1, decoder))), nil
 	case config.DecoderFloat32:
-		value, ok := decodeUint32(regs, decoder)
-		if !ok {
-			return nil, false
+		return math.Float32frombits(decodeUint32(regs, decoder)), nil
+	case config.DecoderUint64:
+		return decodeUint64(regs, decoder), nil
+	case config.DecoderInt64:
+		return int64(decodeUint64(regs, decoder)), nil
+	case config.DecoderFloat64:
+		return math.Float64frombits(decodeUint64(regs, decoder)), nil
+	case config.DecoderQ16:
+		raw := binary.BigEndian.Uint16(orderedBytes(regs, 1, decoder))
+		if decoder.Unsigned {
+			return decodeFixed(float64(raw), decoder.FracBits), nil
+		}
+		return decodeFixed(float64(int16(raw)), decoder.FracBits), nil
+	case config.DecoderQ32:
+		raw := decodeUint32(regs, decoder)
+		if decoder.Unsigned {
+			return decodeFixed(float64(raw), decoder.FracBits), nil
 		}
-		return math.Float32frombits(value), true
+		return decodeFixed(float64(int32(raw)), decoder.FracBits), nil
+	case config.DecoderEpoch32:
+		return decodeEpoch(int64(decodeUint32(regs, decoder)), decoder)
+	case config.DecoderEpoch64:
+		return decodeEpoch(int64(decodeUint64(regs, decoder)), decoder)
+	case config.DecoderDate:
+		return decodeDateTime(regs, decoder)
+	case config.DecoderString:
+		return decodeString(regs, decoder), nil
+	case config.DecoderBCD16:
+		return decodeBCD(orderedBytes(regs, 1, decoder))
+	case config.DecoderBCD32:
+		return decodeBCD(orderedBytes(regs, 2, decoder))
+	case config.DecoderBits:
+		return decodeBitfield(binary.BigEndian.Uint16(orderedBytes(regs, 1, decoder)), decoder.BitNames), nil
+	case config.DecoderEnum:
+		return decodeEnum(binary.BigEndian.Uint16(orderedBytes(regs, 1, decoder)), decoder.Labels), nil
 	default:
-		return nil, false
+		return nil, ErrUnsupportedType
 	}
 }
 
-func decodeUint32(regs []uint16, decoder config.DecoderConfig) (uint32, bool) {
-	if len(regs) < 2 {
-		return 0, false
-	}
-	ordered := regs[:2]
+func decodeUint32(regs []uint16, decoder config.DecoderConfig) uint32 {
+	return binary.BigEndian.Uint32(orderedBytes(regs, 2, decoder))
+}
+
+func decodeUint64(regs []uint16, decoder config.DecoderConfig) uint64 {
+	return binary.BigEndian.Uint64(orderedBytes(regs, 4, decoder))
+}
+
+// orderedBytes assembles count registers into big-endian byte order, applying
+// the decoder's word order (low-first reverses the words) and byte order.
+// Callers must ensure regs holds at least count registers.
+func orderedBytes(regs []uint16, count int, decoder config.DecoderConfig) []byte {
+	ordered := make([]uint16, count)
+	copy(ordered, regs[:count])
 	if decoder.WordOrder == config.WordLowFirst {
-		ordered = []uint16{regs[1], regs[0]}
+		for i, j := 0, count-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
 	}
-	bytes := make([]byte, 0, 4)
+	bytes := make([]byte, 0, count*2)
 	for _, reg := range ordered {
 		if decoder.Endianness == config.EndianLittle {
 			bytes = append(bytes, byte(reg&0xff), byte(reg>>8))
@@ -75,5 +186,138 @@ func decodeUint32(regs []uint16, decoder config.DecoderConfig) (uint32, bool) {
 			bytes = append(bytes, byte(reg>>8), byte(reg&0xff))
 		}
 	}
-	return binary.BigEndian.Uint32(bytes), true
+	return bytes
+}
+
+// float16ToFloat32 widens an IEEE 754 half-precision value; every half is
+// exactly representable as a float32.
+func float16ToFloat32(h uint16) float32 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return float32(sign * math.Ldexp(frac, -24))
+	case 0x1f:
+		if frac != 0 {
+			return float32(math.NaN())
+		}
+		return float32(math.Inf(int(sign)))
+	default:
+		return float32(sign * math.Ldexp(1+frac/1024, exp-15))
+	}
+}
+
+// decodeFixed reads a Qm.n fixed-point value: the raw integer divided by 2^n.
+func decodeFixed(raw float64, fracBits int) float64 {
+	return math.Ldexp(raw, -fracBits)
+}
+
+// decodeBCD reads packed BCD, two decimal digits per byte, most significant
+// digit first. Nibbles above 9 are rejected instead of producing garbage.
+func decodeBCD(bytes []byte) (uint32, error) {
+	var value uint32
+	for _, b := range bytes {
+		for _, nibble := range []byte{b >> 4, b & 0x0f} {
+			if nibble > 9 {
+				return 0, fmt.Errorf("invalid BCD digit 0x%X", nibble)
+			}
+			value = value*10 + uint32(nibble)
+		}
+	}
+	return value, nil
+}
+
+func decodeBitfield(raw uint16, names map[int]string) Bitfield {
+	field := Bitfield{Raw: raw, Bits: FormatBits(raw)}
+	for bit := 0; bit < 16; bit++ {
+		if raw&(1<<bit) == 0 {
+			continue
+		}
+		if name, ok := names[bit]; ok && name != "" {
+			field.Flags = append(field.Flags, name)
+		}
+	}
+	return field
+}
+
+func decodeEnum(raw uint16, labels map[int]string) Enum {
+	if label, ok := labels[int(raw)]; ok {
+		return Enum{Raw: raw, Label: label, Known: true}
+	}
+	return Enum{Raw: raw, Label: fmt.Sprintf("unknown (%d)", raw)}
+}
+
+// FormatBits renders a register as a 16-bit grid, bit 15 first, grouped by
+// nibble.
+func FormatBits(raw uint16) string {
+	var b strings.Builder
+	for bit := 15; bit >= 0; bit-- {
+		if raw&(1<<bit) != 0 {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+		if bit%4 == 0 && bit > 0 {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// decodeString unpacks two characters per register, high byte first unless
+// the decoder is little endian (byte-swapped).
+func decodeString(regs []uint16, decoder config.DecoderConfig) string {
+	count := RegisterSpan(decoder)
+	bytes := make([]byte, 0, count*2)
+	for _, reg := range regs[:count] {
+		if decoder.Endianness == config.EndianLittle {
+			bytes = append(bytes, byte(reg&0xff), byte(reg>>8))
+		} else {
+			bytes = append(bytes, byte(reg>>8), byte(reg&0xff))
+		}
+	}
+	text := decodeText(bytes, decoder.Encoding)
+	switch decoder.Trim {
+	case config.TrimNull:
+		text = trimNull(text)
+	case config.TrimSpace:
+		text = strings.TrimSpace(text)
+	case config.TrimBoth, "":
+		text = strings.TrimSpace(trimNull(text))
+	}
+	return text
+}
+
+func decodeText(bytes []byte, encoding config.StringEncoding) string {
+	switch encoding {
+	case config.EncodingUTF8:
+		return strings.ToValidUTF8(string(bytes), "\uFFFD")
+	case config.EncodingLatin1:
+		runes := make([]rune, len(bytes))
+		for i, b := range bytes {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		runes := make([]rune, len(bytes))
+		for i, b := range bytes {
+			if b < 0x80 {
+				runes[i] = rune(b)
+			} else {
+				runes[i] = utf8.RuneError
+			}
+		}
+		return string(runes)
+	}
+}
+
+func trimNull(text string) string {
+	if idx := strings.IndexByte(text, 0); idx != -1 {
+		return text[:idx]
+	}
+	return text
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"math"
 	"testing"
 
 	"gomodmaster/internal/config"
@@ -11,7 +12,7 @@ import (
 func TestDecodeUint32BigEndianHighFirst(t *testing.T) {
 	regs := []uint16{0x1234, 0x5678}
 	dec := config.DecoderConfig{Type: config.DecoderUint32, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
-	values := DecodeValues(regs, []config.DecoderConfig{dec})
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
 
 	require.Len(t, values, 1)
 	require.Equal(t, uint32(0x12345678), values[0].Value)
@@ -20,7 +21,7 @@ func TestDecodeUint32BigEndianHighFirst(t *testing.T) {
 func TestDecodeUint32LittleEndianLowFirst(t *testing.T) {
 	regs := []uint16{0x1122, 0x3344}
 	dec := config.DecoderConfig{Type: config.DecoderUint32, Endianness: config.EndianLittle, WordOrder: config.WordLowFirst, Enabled: true}
-	values := DecodeValues(regs, []config.DecoderConfig{dec})
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
 
 	require.Len(t, values, 1)
 	require.Equal(t, uint32(0x44332211), values[0].Value)
@@ -29,8 +30,185 @@ func TestDecodeUint32LittleEndianLowFirst(t *testing.T) {
 func TestDecodeInt16(t *testing.T) {
 	regs := []uint16{0xFFFE}
 	dec := config.DecoderConfig{Type: config.DecoderInt16, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
-	values := DecodeValues(regs, []config.DecoderConfig{dec})
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
 
 	require.Len(t, values, 1)
 	require.Equal(t, int16(-2), values[0].Value)
 }
+
+func TestDecodeUint64WordOrders(t *testing.T) {
+	regs := []uint16{0x0102, 0x0304, 0x0506, 0x0708}
+	cases := []struct {
+		endian config.Endianness
+		word   config.WordOrder
+		want   uint64
+	}{
+		{config.EndianBig, config.WordHighFirst, 0x0102030405060708},
+		{config.EndianBig, config.WordLowFirst, 0x0708050603040102},
+		{config.EndianLittle, config.WordHighFirst, 0x0201040306050807},
+		{config.EndianLittle, config.WordLowFirst, 0x0807060504030201},
+	}
+	for _, tc := range cases {
+		dec := config.DecoderConfig{Type: config.DecoderUint64, Endianness: tc.endian, WordOrder: tc.word, Enabled: true}
+		values := DecodeValues(0, regs, []config.DecoderConfig{dec})
+
+		require.Len(t, values, 1)
+		require.Equal(t, tc.want, values[0].Value, "%s/%s", tc.endian, tc.word)
+	}
+}
+
+func TestDecodeInt64(t *testing.T) {
+	regs := []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFE}
+	dec := config.DecoderConfig{Type: config.DecoderInt64, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, int64(-2), values[0].Value)
+}
+
+func TestDecodeFloat64(t *testing.T) {
+	regs := []uint16{0x4045, 0x4000, 0x0000, 0x0000}
+	dec := config.DecoderConfig{Type: config.DecoderFloat64, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, 42.5, values[0].Value)
+}
+
+func TestDecodeFloat64NeedsFourRegisters(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderFloat64, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
+	require.Empty(t, DecodeValues(0, []uint16{0x4045, 0x4000, 0x0000}, []config.DecoderConfig{dec}))
+}
+
+func TestDecodeStringTrimsPadding(t *testing.T) {
+	regs := []uint16{0x4142, 0x4344, 0x2000, 0x0000}
+	dec := config.DecoderConfig{Type: config.DecoderString, Endianness: config.EndianBig, Enabled: true, Registers: 4, Trim: config.TrimBoth, Encoding: config.EncodingASCII}
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, "ABCD", values[0].Value)
+}
+
+func TestDecodeStringByteSwap(t *testing.T) {
+	regs := []uint16{0x4241, 0x4443}
+	dec := config.DecoderConfig{Type: config.DecoderString, Endianness: config.EndianLittle, Enabled: true, Registers: 2, Trim: config.TrimNone, Encoding: config.EncodingASCII}
+	values := DecodeValues(0, regs, []config.DecoderConfig{dec})
+
+	require.Equal(t, "ABCD", values[0].Value)
+}
+
+func TestDecodeStringEncodings(t *testing.T) {
+	regs := []uint16{0xC3A9, 0x0000}
+	utf := config.DecoderConfig{Type: config.DecoderString, Enabled: true, Registers: 2, Trim: config.TrimNull, Encoding: config.EncodingUTF8}
+	latin := config.DecoderConfig{Type: config.DecoderString, Enabled: true, Registers: 2, Trim: config.TrimNull, Encoding: config.EncodingLatin1}
+	ascii := config.DecoderConfig{Type: config.DecoderString, Enabled: true, Registers: 2, Trim: config.TrimNull, Encoding: config.EncodingASCII}
+
+	require.Equal(t, "é", DecodeValues(0, regs, []config.DecoderConfig{utf})[0].Value)
+	require.Equal(t, "Ã©", DecodeValues(0, regs, []config.DecoderConfig{latin})[0].Value)
+	require.Equal(t, "��", DecodeValues(0, regs, []config.DecoderConfig{ascii})[0].Value)
+}
+
+func TestDecodeStringNeedsAllRegisters(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderString, Enabled: true, Registers: 4}
+	require.Empty(t, DecodeValues(0, []uint16{0x4142}, []config.DecoderConfig{dec}))
+}
+
+func TestDecodeBCD16(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderBCD16, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
+	values := DecodeValues(0, []uint16{0x1234}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, uint32(1234), values[0].Value)
+}
+
+func TestDecodeBCD32LowFirst(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderBCD32, Endianness: config.EndianBig, WordOrder: config.WordLowFirst, Enabled: true}
+	values := DecodeValues(0, []uint16{0x5678, 0x1234}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, uint32(12345678), values[0].Value)
+}
+
+func TestDecodeBCDRejectsInvalidNibble(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderBCD16, Endianness: config.EndianBig, WordOrder: config.WordHighFirst, Enabled: true}
+	values := DecodeValues(0, []uint16{0x12A4}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Nil(t, values[0].Value)
+	require.Equal(t, "invalid BCD digit 0xA", values[0].Error)
+}
+
+func TestDecodeBitfieldNamesSetFlags(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderBits, Endianness: config.EndianBig, Enabled: true, BitNames: map[int]string{0: "RUN", 3: "FAULT", 8: "REMOTE", 9: "LOCAL"}}
+	value, err := DecodeAt([]uint16{0x0109}, dec)
+
+	require.NoError(t, err)
+	require.Equal(t, Bitfield{Raw: 0x0109, Bits: "0000 0001 0000 1001", Flags: []string{"RUN", "FAULT", "REMOTE"}}, value)
+	require.Equal(t, "0000 0001 0000 1001  RUN, FAULT, REMOTE", value.(Bitfield).String())
+}
+
+func TestDecodeBitfieldByteSwap(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderBits, Endianness: config.EndianLittle, Enabled: true}
+	value, err := DecodeAt([]uint16{0x0100}, dec)
+
+	require.NoError(t, err)
+	require.Equal(t, Bitfield{Raw: 0x0001, Bits: "0000 0000 0000 0001"}, value)
+}
+
+func TestDecodeEnumLabels(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderEnum, Enabled: true, Labels: map[int]string{0: "Stopped", 1: "Running", 2: "Fault"}}
+	values := DecodeValues(0, []uint16{1}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, Enum{Raw: 1, Label: "Running", Known: true}, values[0].Value)
+}
+
+func TestDecodeEnumUnknownValue(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderEnum, Enabled: true, Labels: map[int]string{0: "Stopped"}}
+	value, err := DecodeAt([]uint16{7}, dec)
+
+	require.NoError(t, err)
+	require.Equal(t, "unknown (7)", value.(Enum).String())
+}
+
+func TestDecodeFloat16(t *testing.T) {
+	cases := map[uint16]float32{
+		0x3C00: 1,
+		0xC000: -2,
+		0x3555: 0.333251953125,
+		0x0001: 5.960464477539063e-08,
+		0x7BFF: 65504,
+	}
+	dec := config.DecoderConfig{Type: config.DecoderFloat16}
+	for raw, expected := range cases {
+		value, err := DecodeAt([]uint16{raw}, dec)
+		require.NoError(t, err)
+		require.Equal(t, expected, value, "0x%04X", raw)
+	}
+}
+
+func TestDecodeFloat16NonFinite(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderFloat16}
+	inf, _ := DecodeAt([]uint16{0xFC00}, dec)
+	nan, _ := DecodeAt([]uint16{0x7E00}, dec)
+
+	require.True(t, math.IsInf(float64(inf.(float32)), -1))
+	require.True(t, math.IsNaN(float64(nan.(float32))))
+}
+
+func TestDecodeQFormat(t *testing.T) {
+	q15 := config.DecoderConfig{Type: config.DecoderQ16, FracBits: 15}
+	value, err := DecodeAt([]uint16{0xC000}, q15)
+	require.NoError(t, err)
+	require.Equal(t, -0.5, value)
+
+	uq8 := config.DecoderConfig{Type: config.DecoderQ16, FracBits: 8, Unsigned: true}
+	value, err = DecodeAt([]uint16{0xFF80}, uq8)
+	require.NoError(t, err)
+	require.Equal(t, 255.5, value)
+
+	q16 := config.DecoderConfig{Type: config.DecoderQ32, FracBits: 16, WordOrder: config.WordLowFirst}
+	value, err = DecodeAt([]uint16{0x8000, 0xFFFD}, q16)
+	require.NoError(t, err)
+	require.Equal(t, -2.5, value)
+}
@@ -0,0 +1,411 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gomodmaster/internal/config"
+)
+
+var ErrOutOfRange = errors.New("value out of range")
+
+// Encode is the inverse of DecodeAt: it parses text as a value of the
+// decoder's type and returns the register words that decode back to it, in
+// the decoder's byte and word order. Scaled decoders take the engineering
+// value and undo raw × scale × 10^SF + offset; as with ApplyScaling, the
+// scale-factor register is looked up in block, a read starting at address.
+func Encode(text string, decoder config.DecoderConfig, address uint16, block []uint16) ([]uint16, error) {
+	text = strings.TrimSpace(text)
+	if decoder.Unit != "" {
+		text = strings.TrimSpace(strings.TrimSuffix(text, decoder.Unit))
+	}
+	bytes := make([]byte, RegisterSpan(decoder)*2)
+	switch decoder.Type {
+	case config.DecoderUint16, config.DecoderUint32, config.DecoderUint64:
+		value, err := encodeUnsigned(text, len(bytes)*8, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		putUint(bytes, value)
+	case config.DecoderInt16, config.DecoderInt32, config.DecoderInt64:
+		value, err := encodeSigned(text, len(bytes)*8, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		putUint(bytes, uint64(value))
+	case config.DecoderFloat16:
+		value, err := unscale(text, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		half, err := float64ToFloat16(value)
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint16(bytes, half)
+	case config.DecoderFloat32:
+		value, err := unscale(text, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		if math.Abs(value) > math.MaxFloat32 && !math.IsInf(value, 0) {
+			return nil, fmt.Errorf("%w: %s does not fit float32", ErrOutOfRange, text)
+		}
+		binary.BigEndian.PutUint32(bytes, math.Float32bits(float32(value)))
+	case config.DecoderFloat64:
+		value, err := unscale(text, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint64(bytes, math.Float64bits(value))
+	case config.DecoderQ16, config.DecoderQ32:
+		value, err := unscale(text, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := fixedRaw(math.Ldexp(value, decoder.FracBits), len(bytes)*8, decoder.Unsigned, text, decoder.Type)
+		if err != nil {
+			return nil, err
+		}
+		putUint(bytes, uint64(raw))
+	case config.DecoderBCD16, config.DecoderBCD32:
+		value, err := encodeUnsigned(text, 64, decoder, address, block)
+		if err != nil {
+			return nil, err
+		}
+		if err := putBCD(bytes, value); err != nil {
+			return nil, fmt.Errorf("%w: %s does not fit %s", ErrOutOfRange, text, decoder.Type)
+		}
+	case config.DecoderBits:
+		value, err := encodeBitfield(text, decoder.BitNames)
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint16(bytes, value)
+	case config.DecoderEnum:
+		value, err := encodeEnum(text, decoder.Labels)
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint16(bytes, value)
+	case config.DecoderEpoch32, config.DecoderEpoch64:
+		value, err := encodeEpoch(text, decoder)
+		if err != nil {
+			return nil, err
+		}
+		if decoder.Type == config.DecoderEpoch32 && (value < 0 || value > math.MaxUint32) {
+			return nil, fmt.Errorf("%w: %s does not fit epoch32", ErrOutOfRange, text)
+		}
+		putUint(bytes, uint64(value))
+	case config.DecoderDate:
+		if err := encodeDateTime(bytes, text, decoder); err != nil {
+			return nil, err
+		}
+	case config.DecoderString:
+		if err := encodeString(bytes, text, decoder); err != nil {
+			return nil, err
+		}
+		// Strings only swap bytes within each register.
+		decoder.WordOrder = config.WordHighFirst
+	default:
+		return nil, ErrUnsupportedType
+	}
+	return registerWords(bytes, decoder), nil
+}
+
+// registerWords undoes orderedBytes: big-endian bytes become registers in
+// the decoder's byte and word order.
+func registerWords(bytes []byte, decoder config.DecoderConfig) []uint16 {
+	regs := make([]uint16, len(bytes)/2)
+	for i := range regs {
+		if decoder.Endianness == config.EndianLittle {
+			regs[i] = uint16(bytes[i*2+1])<<8 | uint16(bytes[i*2])
+		} else {
+			regs[i] = binary.BigEndian.Uint16(bytes[i*2:])
+		}
+	}
+	if decoder.WordOrder == config.WordLowFirst {
+		for i, j := 0, len(regs)-1; i < j; i, j = i+1, j-1 {
+			regs[i], regs[j] = regs[j], regs[i]
+		}
+	}
+	return regs
+}
+
+func putUint(bytes []byte, value uint64) {
+	for i := len(bytes) - 1; i >= 0; i-- {
+		bytes[i] = byte(value)
+		value >>= 8
+	}
+}
+
+// changesValue reports whether scaling alters the number, as opposed to only
+// formatting it.
+func changesValue(decoder config.DecoderConfig) bool {
+	return (decoder.Scale != 0 && decoder.Scale != 1) || decoder.Offset != 0 || decoder.ScaleFactor != nil
+}
+
+// unscale parses an engineering value and converts it back to the raw value.
+func unscale(text string, decoder config.DecoderConfig, address uint16, block []uint16) (float64, error) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, numberError(err, text, decoder.Type)
+	}
+	value -= decoder.Offset
+	if decoder.Scale != 0 {
+		value /= decoder.Scale
+	}
+	if decoder.ScaleFactor != nil {
+		exp, err := scaleFactor(*decoder.ScaleFactor, address, block)
+		if err != nil {
+			return 0, err
+		}
+		value /= math.Pow10(exp)
+	}
+	return value, nil
+}
+
+func encodeUnsigned(text string, bits int, decoder config.DecoderConfig, address uint16, block []uint16) (uint64, error) {
+	if !changesValue(decoder) {
+		value, err := strconv.ParseUint(text, 0, bits)
+		if _, signed := strconv.ParseInt(text, 0, 64); err != nil && signed == nil {
+			return 0, fmt.Errorf("%w: %s does not fit %s", ErrOutOfRange, text, decoder.Type)
+		}
+		if err != nil {
+			return 0, numberError(err, text, decoder.Type)
+		}
+		return value, nil
+	}
+	value, err := unscale(text, decoder, address, block)
+	if err != nil {
+		return 0, err
+	}
+	value = math.Round(value)
+	if math.IsNaN(value) || value < 0 || value >= math.Ldexp(1, bits) {
+		return 0, fmt.Errorf("%w: %s does not fit %s", ErrOutOfRange, text, decoder.Type)
+	}
+	return uint64(value), nil
+}
+
+func encodeSigned(text string, bits int, decoder config.DecoderConfig, address uint16, block []uint16) (int64, error) {
+	if !changesValue(decoder) {
+		value, err := strconv.ParseInt(text, 0, bits)
+		if err != nil {
+			return 0, numberError(err, text, decoder.Type)
+		}
+		return value, nil
+	}
+	value, err := unscale(text, decoder, address, block)
+	if err != nil {
+		return 0, err
+	}
+	return fixedRaw(value, bits, false, text, decoder.Type)
+}
+
+// fixedRaw rounds value to an integer that fits bits, signed unless unsigned.
+func fixedRaw(value float64, bits int, unsigned bool, text string, typ config.DecoderType) (int64, error) {
+	value = math.Round(value)
+	low, high := -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1)
+	if unsigned {
+		low, high = 0, math.Ldexp(1, bits)
+	}
+	if math.IsNaN(value) || value < low || value >= high {
+		return 0, fmt.Errorf("%w: %s does not fit %s", ErrOutOfRange, text, typ)
+	}
+	if unsigned {
+		return int64(uint64(value)), nil
+	}
+	return int64(value), nil
+}
+
+func numberError(err error, text string, typ config.DecoderType) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%w: %s does not fit %s", ErrOutOfRange, text, typ)
+	}
+	return fmt.Errorf("invalid %s value %q", typ, text)
+}
+
+// float64ToFloat16 rounds to the nearest half-precision value, ties to even.
+func float64ToFloat16(value float64) (uint16, error) {
+	var sign uint16
+	if math.Signbit(value) {
+		sign = 0x8000
+	}
+	abs := math.Abs(value)
+	switch {
+	case math.IsNaN(value):
+		return 0x7E00, nil
+	case math.IsInf(value, 0):
+		return sign | 0x7C00, nil
+	case abs < math.Ldexp(1, -14):
+		// Subnormal; rounding up to 0x400 yields the smallest normal.
+		return sign | uint16(math.RoundToEven(math.Ldexp(abs, 24))), nil
+	}
+	frac, exp := math.Frexp(abs)
+	mantissa := math.RoundToEven((frac*2 - 1) * 1024)
+	biased := exp - 1 + 15
+	if mantissa == 1024 {
+		mantissa = 0
+		biased++
+	}
+	if biased >= 0x1f {
+		return 0, fmt.Errorf("%w: %v does not fit float16", ErrOutOfRange, value)
+	}
+	return sign | uint16(biased)<<10 | uint16(mantissa), nil
+}
+
+func putBCD(bytes []byte, value uint64) error {
+	for i := len(bytes) - 1; i >= 0; i-- {
+		bytes[i] = byte(value%10) | byte(value/10%10)<<4
+		value /= 100
+	}
+	if value != 0 {
+		return ErrOutOfRange
+	}
+	return nil
+}
+
+// encodeBitfield accepts a number or flag names joined by '|', e.g.
+// "RUN|FAULT".
+func encodeBitfield(text string, names map[int]string) (uint16, error) {
+	if value, err := strconv.ParseUint(text, 0, 16); err == nil {
+		return uint16(value), nil
+	}
+	var value uint16
+	for _, flag := range strings.Split(text, "|") {
+		flag = strings.TrimSpace(flag)
+		bit, ok := lookupLabel(names, flag)
+		if !ok || bit > 15 {
+			return 0, fmt.Errorf("unknown flag %q", flag)
+		}
+		value |= 1 << bit
+	}
+	return value, nil
+}
+
+// encodeEnum accepts a label or its number.
+func encodeEnum(text string, labels map[int]string) (uint16, error) {
+	if value, ok := lookupLabel(labels, text); ok {
+		return uint16(value), nil
+	}
+	value, err := strconv.ParseUint(text, 0, 16)
+	if err != nil {
+		return 0, numberError(err, text, config.DecoderEnum)
+	}
+	return uint16(value), nil
+}
+
+func lookupLabel(labels map[int]string, text string) (int, bool) {
+	for key, label := range labels {
+		if strings.EqualFold(label, text) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// encodeEpoch accepts an ISO-8601 time or a raw epoch number.
+func encodeEpoch(text string, decoder config.DecoderConfig) (int64, error) {
+	if value, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return value, nil
+	}
+	loc, err := timezone(decoder.Timezone)
+	if err != nil {
+		return 0, err
+	}
+	value, err := parseTime(text, loc)
+	if err != nil {
+		return 0, err
+	}
+	if decoder.Millis {
+		return value.UnixMilli(), nil
+	}
+	return value.Unix(), nil
+}
+
+func encodeDateTime(bytes []byte, text string, decoder config.DecoderConfig) error {
+	loc, err := timezone(decoder.Timezone)
+	if err != nil {
+		return err
+	}
+	value, err := parseTime(text, loc)
+	if err != nil {
+		return err
+	}
+	value = value.In(loc)
+	fields := []int{value.Year(), int(value.Month()), value.Day(), value.Hour(), value.Minute(), value.Second()}
+	if len(bytes) == 6 {
+		if fields[0] < 2000 || fields[0] > 2099 {
+			return fmt.Errorf("%w: year %d does not fit two digits", ErrOutOfRange, fields[0])
+		}
+		fields[0] -= 2000
+		for i, field := range fields {
+			bytes[i] = byte(field)
+		}
+		return nil
+	}
+	for i, field := range fields {
+		binary.BigEndian.PutUint16(bytes[i*2:], uint16(field))
+	}
+	return nil
+}
+
+// parseTime reads ISO-8601 with or without an offset; times without one are
+// wall-clock time in loc.
+func parseTime(text string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if value, err := time.ParseInLocation(layout, text, loc); err == nil {
+			return value, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use ISO-8601, e.g. 2024-07-04T12:30:00)", text)
+}
+
+// encodeString packs text two characters per register in the decoder's
+// encoding, padding with NUL (or spaces when only spaces are trimmed).
+func encodeString(bytes []byte, text string, decoder config.DecoderConfig) error {
+	var encoded []byte
+	switch decoder.Encoding {
+	case config.EncodingUTF8:
+		encoded = []byte(text)
+	default:
+		limit := rune(0x7f)
+		if decoder.Encoding == config.EncodingLatin1 {
+			limit = 0xff
+		}
+		for _, r := range text {
+			if r > limit || r == utf8.RuneError {
+				return fmt.Errorf("%w: %q is not %s", ErrOutOfRange, r, encodingName(decoder.Encoding))
+			}
+			encoded = append(encoded, byte(r))
+		}
+	}
+	if len(encoded) > len(bytes) {
+		return fmt.Errorf("%w: %d bytes do not fit %d registers", ErrOutOfRange, len(encoded), len(bytes)/2)
+	}
+	pad := byte(0)
+	if decoder.Trim == config.TrimSpace {
+		pad = ' '
+	}
+	for i := range bytes {
+		if i < len(encoded) {
+			bytes[i] = encoded[i]
+		} else {
+			bytes[i] = pad
+		}
+	}
+	return nil
+}
+
+func encodingName(encoding config.StringEncoding) string {
+	if encoding == "" {
+		return string(config.EncodingASCII)
+	}
+	return string(encoding)
+}
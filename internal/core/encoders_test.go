@@ -0,0 +1,126 @@
+package core
+
+import (
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeFloat32ByteOrders(t *testing.T) {
+	cases := map[config.ByteOrder][]uint16{
+		config.ByteOrderABCD: {0x41BC, 0x0000},
+		config.ByteOrderCDAB: {0x0000, 0x41BC},
+		config.ByteOrderBADC: {0xBC41, 0x0000},
+		config.ByteOrderDCBA: {0x0000, 0xBC41},
+	}
+	for order, expected := range cases {
+		dec := config.DecoderConfig{Type: config.DecoderFloat32}
+		dec.SetByteOrder(order)
+		regs, err := Encode("23.5", dec, 0, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, regs, order)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	cases := []struct {
+		decoder config.DecoderConfig
+		text    string
+		value   interface{}
+	}{
+		{config.DecoderConfig{Type: config.DecoderUint16}, "0xBEEF", uint16(0xBEEF)},
+		{config.DecoderConfig{Type: config.DecoderInt16}, "-2", int16(-2)},
+		{config.DecoderConfig{Type: config.DecoderUint32, WordOrder: config.WordLowFirst}, "305419896", uint32(305419896)},
+		{config.DecoderConfig{Type: config.DecoderInt32}, "-123456", int32(-123456)},
+		{config.DecoderConfig{Type: config.DecoderUint64}, "18446744073709551615", uint64(18446744073709551615)},
+		{config.DecoderConfig{Type: config.DecoderInt64, Endianness: config.EndianLittle}, "-9", int64(-9)},
+		{config.DecoderConfig{Type: config.DecoderFloat16}, "-2", float32(-2)},
+		{config.DecoderConfig{Type: config.DecoderFloat64}, "0.1", 0.1},
+		{config.DecoderConfig{Type: config.DecoderQ16, FracBits: 15}, "-0.5", -0.5},
+		{config.DecoderConfig{Type: config.DecoderQ32, FracBits: 16, Unsigned: true}, "255.5", 255.5},
+		{config.DecoderConfig{Type: config.DecoderBCD16}, "1234", uint32(1234)},
+		{config.DecoderConfig{Type: config.DecoderBCD32}, "12345678", uint32(12345678)},
+		{config.DecoderConfig{Type: config.DecoderString, Registers: 3, Endianness: config.EndianLittle}, "ABCD", "ABCD"},
+	}
+	for _, tc := range cases {
+		regs, err := Encode(tc.text, tc.decoder, 0, nil)
+		require.NoError(t, err, tc.decoder.Type)
+		require.Len(t, regs, RegisterSpan(tc.decoder))
+		value, err := DecodeAt(regs, tc.decoder)
+		require.NoError(t, err)
+		require.Equal(t, tc.value, value, tc.decoder.Type)
+	}
+}
+
+func TestEncodeOutOfRange(t *testing.T) {
+	cases := []struct {
+		decoder config.DecoderConfig
+		text    string
+	}{
+		{config.DecoderConfig{Type: config.DecoderUint16}, "65536"},
+		{config.DecoderConfig{Type: config.DecoderUint16}, "-1"},
+		{config.DecoderConfig{Type: config.DecoderInt16}, "32768"},
+		{config.DecoderConfig{Type: config.DecoderFloat16}, "70000"},
+		{config.DecoderConfig{Type: config.DecoderFloat32}, "1e39"},
+		{config.DecoderConfig{Type: config.DecoderQ16, FracBits: 15}, "1"},
+		{config.DecoderConfig{Type: config.DecoderBCD16}, "10000"},
+		{config.DecoderConfig{Type: config.DecoderString, Registers: 1}, "ABC"},
+		{config.DecoderConfig{Type: config.DecoderString, Registers: 2}, "né"},
+		{config.DecoderConfig{Type: config.DecoderInt16, Scale: 0.1}, "3276.8"},
+	}
+	for _, tc := range cases {
+		_, err := Encode(tc.text, tc.decoder, 0, nil)
+		require.ErrorIs(t, err, ErrOutOfRange, "%s %s", tc.decoder.Type, tc.text)
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	_, err := Encode("abc", config.DecoderConfig{Type: config.DecoderUint16}, 0, nil)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrOutOfRange)
+}
+
+func TestEncodeScaled(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderInt16, Scale: 0.1, Offset: -40, Unit: "°C"}
+	regs, err := Encode("23.5 °C", dec, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{635}, regs)
+
+	sf := uint16(101)
+	dec = config.DecoderConfig{Type: config.DecoderUint16, ScaleFactor: &sf}
+	regs, err = Encode("1230", dec, 100, []uint16{0, 0xFFFF})
+	require.NoError(t, err)
+	require.Equal(t, []uint16{12300}, regs)
+
+	_, err = Encode("1230", dec, 100, nil)
+	require.ErrorIs(t, err, ErrScaleFactor)
+}
+
+func TestEncodeLabels(t *testing.T) {
+	bits := config.DecoderConfig{Type: config.DecoderBits, BitNames: map[int]string{0: "RUN", 3: "FAULT"}}
+	regs, err := Encode("RUN|fault", bits, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0x0009}, regs)
+
+	enum := config.DecoderConfig{Type: config.DecoderEnum, Labels: map[int]string{2: "Fault"}}
+	regs, err = Encode("Fault", enum, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{2}, regs)
+
+	_, err = Encode("Stopped", enum, 0, nil)
+	require.Error(t, err)
+}
+
+func TestEncodeTimestamps(t *testing.T) {
+	epoch := config.DecoderConfig{Type: config.DecoderEpoch32, Timezone: "Europe/Berlin"}
+	regs, err := Encode("2023-11-14T23:13:20", epoch, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0x6553, 0xF100}, regs)
+
+	packed := config.DecoderConfig{Type: config.DecoderDate, Registers: 3}
+	regs, err = Encode("2024-02-29T23:59:59Z", packed, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0x1802, 0x1D17, 0x3B3B}, regs)
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// jsonValue replaces NaN and ±Inf, which encoding/json rejects, with their
+// string spelling so a read result always serializes.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float32:
+		return jsonFloat(float64(v), value)
+	case float64:
+		return jsonFloat(v, value)
+	default:
+		return value
+	}
+}
+
+func jsonFloat(f float64, value interface{}) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return value
+}
+
+func (d DecodedValue) MarshalJSON() ([]byte, error) {
+	type plain DecodedValue
+	d.Value = jsonValue(d.Value)
+	return json.Marshal(plain(d))
+}
+
+func (v AddressValue) MarshalJSON() ([]byte, error) {
+	type plain AddressValue
+	v.Value = jsonValue(v.Value)
+	return json.Marshal(plain(v))
+}
+
+func (s Scaled) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Value interface{} `json:"value"`
+		Raw   interface{} `json:"raw"`
+		Unit  string      `json:"unit,omitempty"`
+		Text  string      `json:"text"`
+	}{jsonValue(s.Value), jsonValue(s.Raw), s.Unit, s.Text})
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadResultMarshalsNonFiniteFloats(t *testing.T) {
+	result := ReadResult{
+		Decoded: []DecodedValue{
+			{Type: config.DecoderFloat32, Value: float32(math.NaN())},
+			{Type: config.DecoderFloat64, Value: math.Inf(-1)},
+			{Type: config.DecoderFloat16, Value: Scaled{Value: math.Inf(1), Raw: float32(math.Inf(1)), Text: "+Inf"}},
+		},
+		Values: []AddressValue{{Address: 1, Span: 1, Type: config.DecoderFloat16, Value: float32(math.Inf(1))}},
+	}
+	data, err := json.Marshal(result)
+
+	require.NoError(t, err)
+	require.Contains(t, string(data), `{"type":"float32","value":"NaN"}`)
+	require.Contains(t, string(data), `{"type":"float64","value":"-Inf"}`)
+	require.Contains(t, string(data), `"value":{"value":"+Inf","raw":"+Inf","text":"+Inf"}`)
+	require.Contains(t, string(data), `"type":"float16","value":"+Inf"`)
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gomodmaster/internal/config"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+// LogFile appends log entries as JSON Lines and rotates the file by size
+// and age, keeping a bounded number of (optionally gzipped) backups.
+type LogFile struct {
+	mu       sync.Mutex
+	cfg      config.LogFileConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+	closed   bool
+	// rotated queues backups for the worker that compresses and prunes
+	// them one at a time, so it never sees a half-written .gz. Writers
+	// queue outside mu; sending counts them so Close can wait.
+	rotated chan string
+	sending sync.WaitGroup
+	done    chan struct{}
+}
+
+func OpenLogFile(cfg config.LogFileConfig) (*LogFile, error) {
+	lf := &LogFile{cfg: cfg, now: time.Now, rotated: make(chan string, 16), done: make(chan struct{})}
+	if err := lf.open(); err != nil {
+		return nil, err
+	}
+	go lf.work()
+	return lf, nil
+}
+
+func (lf *LogFile) Path() string {
+	return lf.cfg.Path
+}
+
+func (lf *LogFile) Write(entry LogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	lf.mu.Lock()
+	backup, err := lf.write(line)
+	if backup != "" {
+		lf.sending.Add(1)
+	}
+	lf.mu.Unlock()
+	// A busy worker must not hold up the writers waiting on mu.
+	if backup != "" {
+		lf.rotated <- backup
+		lf.sending.Done()
+	}
+	return err
+}
+
+// write appends line, rotating first when due, and returns the name of the
+// backup it rotated to, if any.
+func (lf *LogFile) write(line []byte) (string, error) {
+	if lf.closed {
+		return "", os.ErrClosed
+	}
+	if lf.file == nil {
+		if err := lf.open(); err != nil {
+			return "", err
+		}
+	}
+	var backup string
+	var err error
+	if lf.shouldRotate(int64(len(line))) {
+		backup, err = lf.rotate()
+		if lf.file == nil {
+			return backup, err
+		}
+	}
+	n, writeErr := lf.file.Write(line)
+	lf.size += int64(n)
+	return backup, errors.Join(err, writeErr)
+}
+
+func (lf *LogFile) Close() error {
+	lf.mu.Lock()
+	file := lf.file
+	lf.file = nil
+	closed := lf.closed
+	lf.closed = true
+	lf.mu.Unlock()
+	if !closed {
+		lf.sending.Wait()
+		close(lf.rotated)
+	}
+	<-lf.done
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
+func (lf *LogFile) open() error {
+	if dir := filepath.Dir(lf.cfg.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(lf.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	lf.file = file
+	lf.size = info.Size()
+	lf.openedAt = lf.now()
+	if info.Size() > 0 {
+		lf.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (lf *LogFile) shouldRotate(next int64) bool {
+	if lf.size == 0 {
+		return false
+	}
+	if lf.cfg.MaxSizeMB > 0 && lf.size+next > int64(lf.cfg.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	if lf.cfg.MaxAgeHours > 0 && lf.now().Sub(lf.openedAt) >= time.Duration(lf.cfg.MaxAgeHours)*time.Hour {
+		return true
+	}
+	return false
+}
+
+// rotate moves the file to a backup and opens a new one, returning the
+// backup's name. When the move fails the file is opened again, so entries
+// keep going to it instead of being dropped.
+func (lf *LogFile) rotate() (string, error) {
+	err := lf.file.Close()
+	lf.file = nil
+	if err == nil {
+		backup := lf.backupName(lf.now())
+		if err = os.Rename(lf.cfg.Path, backup); err == nil {
+			return backup, lf.open()
+		}
+	}
+	return "", errors.Join(err, lf.open())
+}
+
+func (lf *LogFile) work() {
+	defer close(lf.done)
+	for backup := range lf.rotated {
+		if lf.cfg.Compress {
+			_ = compressFile(backup)
+		}
+		lf.prune()
+	}
+}
+
+// backupName stamps the backup with t, adding a counter when a backup of
+// the same millisecond exists.
+func (lf *LogFile) backupName(t time.Time) string {
+	ext := filepath.Ext(lf.cfg.Path)
+	base := strings.TrimSuffix(lf.cfg.Path, ext)
+	stamp := t.Format(backupTimeFormat)
+	name := fmt.Sprintf("%s-%s%s", base, stamp, ext)
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%s-%d%s", base, stamp, i, ext)
+	}
+	return name
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// backups lists the uncompressed name of each backup, oldest first. A backup
+// being compressed has both names on disk but is listed once.
+func (lf *LogFile) backups() []string {
+	ext := filepath.Ext(lf.cfg.Path)
+	base := strings.TrimSuffix(lf.cfg.Path, ext)
+	matches, _ := filepath.Glob(base + "-*" + ext + "*")
+	keys := map[string]string{}
+	out := make([]string, 0, len(matches))
+	for _, match := range matches {
+		name := strings.TrimSuffix(match, ".gz")
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, base+"-"), ext)
+		counter := 0
+		if i := strings.LastIndex(stamp, "-"); i >= 0 {
+			if n, err := strconv.Atoi(stamp[i+1:]); err == nil {
+				stamp, counter = stamp[:i], n
+			}
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		if _, ok := keys[name]; ok {
+			continue
+		}
+		keys[name] = fmt.Sprintf("%s-%06d", stamp, counter)
+		out = append(out, name)
+	}
+	sort.Slice(out, func(i, j int) bool { return keys[out[i]] < keys[out[j]] })
+	return out
+}
+
+func (lf *LogFile) prune() {
+	if lf.cfg.MaxBackups <= 0 {
+		return
+	}
+	backups := lf.backups()
+	for len(backups) > lf.cfg.MaxBackups {
+		_ = os.Remove(backups[0])
+		_ = os.Remove(backups[0] + ".gz")
+		backups = backups[1:]
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
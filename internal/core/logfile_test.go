@@ -0,0 +1,140 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFileWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, lf.Write(LogEntry{Time: time.Now(), Direction: "tx", Message: "first"}))
+	require.NoError(t, lf.Write(LogEntry{Time: time.Now(), Direction: "rx", Message: "second"}))
+	require.NoError(t, lf.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	messages := []string{}
+	for scanner.Scan() {
+		var entry LogEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		messages = append(messages, entry.Message)
+	}
+	require.Equal(t, []string{"first", "second"}, messages)
+}
+
+func TestLogFileRotatesByAgeAndCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path, MaxAgeHours: 1, MaxBackups: 1, Compress: true})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lf.now = func() time.Time { return now }
+	lf.openedAt = now
+
+	require.NoError(t, lf.Write(LogEntry{Time: now, Direction: "sys", Message: "a"}))
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, lf.Write(LogEntry{Time: now, Direction: "sys", Message: "b"}))
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, lf.Write(LogEntry{Time: now, Direction: "sys", Message: "c"}))
+	require.NoError(t, lf.Close())
+
+	backups, err := filepath.Glob(filepath.Join(dir, "session-*.jsonl.gz"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	require.Equal(t, filepath.Join(dir, "session-20240101T040000.000.jsonl.gz"), backups[0])
+}
+
+func TestLogFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path, MaxSizeMB: 1})
+	require.NoError(t, err)
+	lf.size = 1024*1024 - 1
+
+	require.NoError(t, lf.Write(LogEntry{Time: time.Now(), Direction: "sys", Message: "overflow"}))
+	require.NoError(t, lf.Close())
+
+	backups, err := filepath.Glob(filepath.Join(dir, "session-*.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+}
+
+func TestLogFileRotationsInOneMillisecondKeepBoth(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2, Compress: true})
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lf.now = func() time.Time { return now }
+
+	for _, message := range []string{"a", "b", "c"} {
+		lf.size = 1024 * 1024
+		require.NoError(t, lf.Write(LogEntry{Time: now, Direction: "sys", Message: message}))
+	}
+	require.NoError(t, lf.Close())
+
+	backups, err := filepath.Glob(filepath.Join(dir, "session-*"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "session-20240101T000000.000-1.jsonl.gz"),
+		filepath.Join(dir, "session-20240101T000000.000-2.jsonl.gz"),
+	}, backups)
+}
+
+func TestLogFileKeepsWritingWhenRotationFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path, MaxSizeMB: 1})
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+
+	lf.size = 1024 * 1024
+	require.Error(t, lf.Write(LogEntry{Time: time.Now(), Direction: "sys", Message: "a"}))
+	require.NoError(t, lf.Write(LogEntry{Time: time.Now(), Direction: "sys", Message: "b"}))
+	require.NoError(t, lf.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"message":"a"`)
+	require.Contains(t, string(data), `"message":"b"`)
+}
+
+func TestLogFileBackupsListsCompressingBackupOnce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lf, err := OpenLogFile(config.LogFileConfig{Path: path, MaxBackups: 1})
+	require.NoError(t, err)
+	defer lf.Close()
+	for _, name := range []string{
+		"session-20240101T000000.000.jsonl",
+		"session-20240101T000000.000.jsonl.gz",
+		"session-20240101T000000.000-10.jsonl.gz",
+		"session-20240101T000000.000-2.jsonl",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "session-20240101T000000.000.jsonl"),
+		filepath.Join(dir, "session-20240101T000000.000-2.jsonl"),
+		filepath.Join(dir, "session-20240101T000000.000-10.jsonl"),
+	}, lf.backups())
+
+	lf.prune()
+	left, err := filepath.Glob(filepath.Join(dir, "session-*"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "session-20240101T000000.000-10.jsonl.gz")}, left)
+}
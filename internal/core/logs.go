@@ -1,50 +1,193 @@
 package core
 
 import (
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
 type LogEntry struct {
+	Seq       uint64    `json:"seq"`
 	Time      time.Time `json:"time"`
 	Direction string    `json:"direction"`
 	Message   string    `json:"message"`
 }
 
+type LogQuery struct {
+	Directions []string
+	Since      time.Time
+	Until      time.Time
+	Text       string
+	After      uint64
+	Limit      int
+	Tail       bool
+}
+
+type LogPage struct {
+	Entries    []LogEntry `json:"entries"`
+	NextCursor uint64     `json:"nextCursor,omitempty"`
+}
+
+func (q LogQuery) Match(entry LogEntry) bool {
+	if entry.Seq <= q.After {
+		return false
+	}
+	if len(q.Directions) > 0 {
+		found := false
+		for _, direction := range q.Directions {
+			if strings.EqualFold(direction, entry.Direction) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.Time.After(q.Until) {
+		return false
+	}
+	if q.Text != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(q.Text)) {
+		return false
+	}
+	return true
+}
+
+func FilterLogs(entries []LogEntry, q LogQuery) []LogEntry {
+	out := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if q.Match(entry) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// LogBuffer is a fixed-size ring of log entries. Sequence numbers increase
+// by one per entry, so the ring always holds a contiguous seq range and an
+// entry can be located directly from its seq.
 type LogBuffer struct {
 	mu      sync.Mutex
 	entries []LogEntry
+	head    int
+	count   int
 	max     int
+	seq     uint64
 }
 
 func NewLogBuffer(max int) *LogBuffer {
+	if max < 0 {
+		max = 0
+	}
 	return &LogBuffer{
-		entries: make([]LogEntry, 0, max),
+		entries: make([]LogEntry, max),
 		max:     max,
 	}
 }
 
-func (lb *LogBuffer) Add(entry LogEntry) {
+func (lb *LogBuffer) Add(entry LogEntry) LogEntry {
 	lb.mu.Lock()
 	defer lb.mu.Unlock()
 
-	if lb.max <= 0 {
-		return
+	lb.seq++
+	entry.Seq = lb.seq
+	if lb.max == 0 {
+		return entry
 	}
-	if len(lb.entries) >= lb.max {
-		copy(lb.entries, lb.entries[1:])
-		lb.entries[len(lb.entries)-1] = entry
-		return
+	if lb.count < lb.max {
+		lb.entries[(lb.head+lb.count)%lb.max] = entry
+		lb.count++
+		return entry
 	}
-	lb.entries = append(lb.entries, entry)
+	lb.entries[lb.head] = entry
+	lb.head = (lb.head + 1) % lb.max
+	return entry
 }
 
 func (lb *LogBuffer) Snapshot() []LogEntry {
 	lb.mu.Lock()
 	defer lb.mu.Unlock()
+	return lb.copyRange(0, lb.count)
+}
+
+// After returns the retained entries with a sequence number greater than seq.
+func (lb *LogBuffer) After(seq uint64) []LogEntry {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.copyRange(lb.indexAfter(seq), lb.count)
+}
 
-	out := make([]LogEntry, len(lb.entries))
-	copy(out, lb.entries)
+func (lb *LogBuffer) LastSeq() uint64 {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.seq
+}
+
+// Query returns matching entries in sequence order; with Tail set it returns
+// the newest Limit matches instead of paginating forward. The cursor is
+// resolved from the seq range; entries are stamped before they take the lock,
+// so they aren't strictly in time order and time bounds are matched per entry.
+func (lb *LogBuffer) Query(q LogQuery) LogPage {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	start := lb.indexAfter(q.After)
+	end := lb.count
+
+	page := LogPage{Entries: []LogEntry{}}
+	if q.Tail {
+		for i := end - 1; i >= start; i-- {
+			if q.Limit > 0 && len(page.Entries) == q.Limit {
+				break
+			}
+			if entry := lb.at(i); q.Match(entry) {
+				page.Entries = append(page.Entries, entry)
+			}
+		}
+		slices.Reverse(page.Entries)
+		return page
+	}
+	for i := start; i < end; i++ {
+		entry := lb.at(i)
+		if !q.Match(entry) {
+			continue
+		}
+		if q.Limit > 0 && len(page.Entries) == q.Limit {
+			page.NextCursor = page.Entries[len(page.Entries)-1].Seq
+			break
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page
+}
+
+func (lb *LogBuffer) at(i int) LogEntry {
+	return lb.entries[(lb.head+i)%lb.max]
+}
+
+func (lb *LogBuffer) indexAfter(seq uint64) int {
+	first := lb.seq - uint64(lb.count) + 1
+	if seq < first {
+		return 0
+	}
+	if seq >= lb.seq {
+		return lb.count
+	}
+	return int(seq - first + 1)
+}
+
+func (lb *LogBuffer) copyRange(from, to int) []LogEntry {
+	if from >= to {
+		return []LogEntry{}
+	}
+	out := make([]LogEntry, to-from)
+	for i := range out {
+		out[i] = lb.at(from + i)
+	}
 	return out
 }
 
@@ -23,3 +23,151 @@ func TestLogBufferMax(t *testing.T) {
 	buf := NewLogBuffer(5)
 	require.Equal(t, 5, buf.Max())
 }
+
+func TestLogBufferAssignsSequence(t *testing.T) {
+	buf := NewLogBuffer(1)
+	first := buf.Add(LogEntry{Message: "first"})
+	second := buf.Add(LogEntry{Message: "second"})
+
+	require.Equal(t, uint64(1), first.Seq)
+	require.Equal(t, uint64(2), second.Seq)
+	require.Equal(t, uint64(2), buf.Snapshot()[0].Seq)
+}
+
+func TestLogBufferQueryFilters(t *testing.T) {
+	buf := NewLogBuffer(10)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	buf.Add(LogEntry{Time: base, Direction: "tx", Message: "tx holding"})
+	buf.Add(LogEntry{Time: base.Add(time.Second), Direction: "rx", Message: "rx holding"})
+	buf.Add(LogEntry{Time: base.Add(2 * time.Second), Direction: "err", Message: "timeout"})
+	buf.Add(LogEntry{Time: base.Add(3 * time.Second), Direction: "tx", Message: "tx coils"})
+
+	page := buf.Query(LogQuery{Directions: []string{"tx"}})
+	require.Len(t, page.Entries, 2)
+
+	page = buf.Query(LogQuery{Text: "HOLDING"})
+	require.Len(t, page.Entries, 2)
+
+	page = buf.Query(LogQuery{Since: base.Add(time.Second), Until: base.Add(2 * time.Second)})
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, "rx holding", page.Entries[0].Message)
+	require.Equal(t, "timeout", page.Entries[1].Message)
+}
+
+func TestLogBufferQueryPaginates(t *testing.T) {
+	buf := NewLogBuffer(10)
+	for i := 0; i < 5; i++ {
+		buf.Add(LogEntry{Time: time.Now(), Direction: "sys"})
+	}
+
+	page := buf.Query(LogQuery{Limit: 2})
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, uint64(2), page.NextCursor)
+
+	page = buf.Query(LogQuery{After: page.NextCursor, Limit: 2})
+	require.Equal(t, uint64(3), page.Entries[0].Seq)
+	require.Equal(t, uint64(4), page.NextCursor)
+
+	page = buf.Query(LogQuery{After: page.NextCursor, Limit: 2})
+	require.Len(t, page.Entries, 1)
+	require.Zero(t, page.NextCursor)
+}
+
+func TestLogBufferQueryTail(t *testing.T) {
+	buf := NewLogBuffer(10)
+	for i := 0; i < 5; i++ {
+		buf.Add(LogEntry{Time: time.Now(), Direction: "sys"})
+	}
+
+	page := buf.Query(LogQuery{Limit: 2, Tail: true})
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, uint64(4), page.Entries[0].Seq)
+	require.Equal(t, uint64(5), page.Entries[1].Seq)
+}
+
+func TestLogBufferQueryTimeOutOfOrder(t *testing.T) {
+	buf := NewLogBuffer(10)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	buf.Add(LogEntry{Time: base.Add(2 * time.Second), Message: "late"})
+	buf.Add(LogEntry{Time: base.Add(time.Second), Message: "early"})
+	buf.Add(LogEntry{Time: base.Add(3 * time.Second), Message: "last"})
+
+	page := buf.Query(LogQuery{Since: base.Add(time.Second), Until: base.Add(time.Second)})
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, "early", page.Entries[0].Message)
+
+	page = buf.Query(LogQuery{Until: base.Add(2 * time.Second)})
+	require.Len(t, page.Entries, 2)
+}
+
+func TestLogBufferWrapsAround(t *testing.T) {
+	buf := NewLogBuffer(3)
+	for i := 0; i < 7; i++ {
+		buf.Add(LogEntry{Time: time.Now(), Direction: "tx"})
+	}
+
+	snap := buf.Snapshot()
+	require.Len(t, snap, 3)
+	require.Equal(t, []uint64{5, 6, 7}, []uint64{snap[0].Seq, snap[1].Seq, snap[2].Seq})
+	require.Equal(t, uint64(7), buf.LastSeq())
+}
+
+func TestLogBufferAfter(t *testing.T) {
+	buf := NewLogBuffer(3)
+	for i := 0; i < 5; i++ {
+		buf.Add(LogEntry{Time: time.Now(), Direction: "tx"})
+	}
+
+	require.Len(t, buf.After(0), 3)
+	require.Len(t, buf.After(2), 3)
+	after := buf.After(3)
+	require.Len(t, after, 2)
+	require.Equal(t, uint64(4), after[0].Seq)
+	require.Empty(t, buf.After(5))
+	require.Empty(t, buf.After(9))
+}
+
+func TestLogBufferZeroSizeKeepsNothing(t *testing.T) {
+	buf := NewLogBuffer(0)
+	entry := buf.Add(LogEntry{Message: "dropped"})
+
+	require.Equal(t, uint64(1), entry.Seq)
+	require.Empty(t, buf.Snapshot())
+	require.Empty(t, buf.After(0))
+	require.Empty(t, buf.Query(LogQuery{}).Entries)
+}
+
+func BenchmarkLogBufferAdd(b *testing.B) {
+	buf := NewLogBuffer(10000)
+	entry := LogEntry{Time: time.Now(), Direction: "tx", Message: "tx holding_registers fc=03 addr=0x0000 qty=0x000a unit=0x01"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Add(entry)
+	}
+}
+
+func BenchmarkLogBufferAddParallel(b *testing.B) {
+	buf := NewLogBuffer(10000)
+	entry := LogEntry{Time: time.Now(), Direction: "rx", Message: "rx holding_registers fc=03 addr=0x0000 qty=0x000a latency=3ms"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf.Add(entry)
+		}
+	})
+}
+
+func BenchmarkLogBufferAfter(b *testing.B) {
+	buf := NewLogBuffer(10000)
+	for i := 0; i < 25000; i++ {
+		buf.Add(LogEntry{Time: time.Now(), Direction: "tx"})
+	}
+	cursor := buf.LastSeq() - 50
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.After(cursor)
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"errors"
+
+	"gomodmaster/internal/config"
+)
+
+// DecodeBools is DecodeValues for coil and discrete-input reads: every
+// enabled packed decoder combines the first bits of the block into an
+// unsigned integer.
+func DecodeBools(bools []bool, decoders []config.DecoderConfig) []DecodedValue {
+	out := []DecodedValue{}
+	for _, decoder := range decoders {
+		if !decoder.Enabled || decoder.Type != config.DecoderPacked {
+			continue
+		}
+		value, err := DecodeBoolsAt(bools, decoder)
+		if errors.Is(err, ErrShortRegisters) {
+			continue
+		}
+		if err != nil {
+			out = append(out, DecodedValue{Type: decoder.Type, Error: err.Error()})
+			continue
+		}
+		out = append(out, DecodedValue{Type: decoder.Type, Value: value})
+	}
+	return out
+}
+
+// DecodeBoolsAt packs the first decoder.Bits values of bools into an
+// unsigned integer, LSB first unless the decoder is MSB first.
+func DecodeBoolsAt(bools []bool, decoder config.DecoderConfig) (interface{}, error) {
+	if decoder.Type != config.DecoderPacked {
+		return nil, ErrUnsupportedType
+	}
+	width := RegisterSpan(decoder)
+	if len(bools) < width {
+		return nil, ErrShortRegisters
+	}
+	var value uint64
+	for i, bit := range bools[:width] {
+		if !bit {
+			continue
+		}
+		if decoder.MSBFirst {
+			value |= 1 << (width - 1 - i)
+		} else {
+			value |= 1 << i
+		}
+	}
+	return value, nil
+}
+
+// DecodeBoolAssignments is DecodeAssignments for coil and discrete-input
+// reads; only packed decoders apply to them.
+func DecodeBoolAssignments(address uint16, bools []bool, assignments []config.Assignment) []AddressValue {
+	return assignedValues(address, len(bools), assignments, packedOnly, func(start uint16, span int, assignment config.Assignment) AddressValue {
+		decoded := AddressValue{Address: start, Span: span, Name: assignment.Name, Type: assignment.Decoder.Type}
+		value, err := DecodeBoolsAt(bools[start-address:], assignment.Decoder)
+		if errors.Is(err, ErrShortRegisters) {
+			decoded.Span = len(bools) - int(start-address)
+		}
+		if err != nil {
+			decoded.Error = err.Error()
+			return decoded
+		}
+		decoded.Value = value
+		return decoded
+	})
+}
+
+func packedOnly(decoder config.DecoderConfig) bool {
+	return decoder.Type == config.DecoderPacked
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBoolsAtBitOrder(t *testing.T) {
+	bools := []bool{true, false, true, true, false}
+
+	lsb, err := DecodeBoolsAt(bools, config.DecoderConfig{Type: config.DecoderPacked, Bits: 4})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0b1101), lsb)
+
+	msb, err := DecodeBoolsAt(bools, config.DecoderConfig{Type: config.DecoderPacked, Bits: 4, MSBFirst: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0b1011), msb)
+}
+
+func TestDecodeBoolsSkipsShortAndRegisterDecoders(t *testing.T) {
+	decoders := []config.DecoderConfig{
+		{Type: config.DecoderUint16, Enabled: true},
+		{Type: config.DecoderPacked, Enabled: true, Bits: 8},
+		{Type: config.DecoderPacked, Enabled: true, Bits: 2},
+	}
+	values := DecodeBools([]bool{false, true, true}, decoders)
+	require.Equal(t, []DecodedValue{{Type: config.DecoderPacked, Value: uint64(2)}}, values)
+}
+
+func TestDecodeBoolAssignments(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 12, Name: "mode", Decoder: config.DecoderConfig{Type: config.DecoderPacked, Bits: 3}},
+		{Address: 10, Decoder: config.DecoderConfig{Type: config.DecoderUint16}},
+		{Address: 16, Decoder: config.DecoderConfig{Type: config.DecoderPacked, Bits: 4}},
+	}
+	bools := []bool{false, false, true, true, false, false, true, true}
+
+	values := DecodeBoolAssignments(10, bools, assignments)
+	require.Len(t, values, 2)
+	require.Equal(t, AddressValue{Address: 12, Span: 3, Name: "mode", Type: config.DecoderPacked, Value: uint64(3)}, values[0])
+	require.Equal(t, uint16(16), values[1].Address)
+	require.Equal(t, 2, values[1].Span)
+	require.Equal(t, ErrShortRegisters.Error(), values[1].Error)
+}
+
+func TestDecodeAssignmentsIgnoresPacked(t *testing.T) {
+	assignments := []config.Assignment{
+		{Address: 0, Decoder: config.DecoderConfig{Type: config.DecoderPacked, Bits: 4}},
+	}
+	require.Empty(t, DecodeAssignments(0, []uint16{1, 2}, assignments))
+}
@@ -25,6 +25,7 @@ type ReadResult struct {
 	BoolValues   []bool         `json:"boolValues,omitempty"`
 	RegValues    []uint16       `json:"regValues,omitempty"`
 	Decoded      []DecodedValue `json:"decoded,omitempty"`
+	Values       []AddressValue `json:"values,omitempty"`
 	LatencyMs    int64          `json:"latencyMs"`
 	CompletedAt  time.Time      `json:"completedAt"`
 	ErrorMessage string         `json:"errorMessage,omitempty"`
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"gomodmaster/internal/config"
+)
+
+var ErrScaleFactor = errors.New("scale factor unavailable")
+
+// sunSpecNotImplemented marks an int16 point, including a scale factor, the
+// device does not implement.
+const sunSpecNotImplemented = -0x8000
+
+// Scaled is a raw value converted into engineering units.
+type Scaled struct {
+	Value float64     `json:"value"`
+	Raw   interface{} `json:"raw"`
+	Unit  string      `json:"unit,omitempty"`
+	Text  string      `json:"text"`
+}
+
+func (s Scaled) String() string {
+	return s.Text
+}
+
+// HasScaling reports whether the decoder converts raw values into
+// engineering units.
+func HasScaling(decoder config.DecoderConfig) bool {
+	return (decoder.Scale != 0 && decoder.Scale != 1) || decoder.Offset != 0 || decoder.Decimals != nil ||
+		decoder.Unit != "" || decoder.ScaleFactor != nil
+}
+
+// ApplyScaling computes raw × scale × 10^SF + offset for numeric values.
+// block is the whole read starting at address, so the scale-factor register
+// can be looked up; it must be part of the same read. Non-numeric values and
+// decoders without scaling are returned unchanged.
+func ApplyScaling(value interface{}, decoder config.DecoderConfig, address uint16, block []uint16) (interface{}, error) {
+	if !HasScaling(decoder) {
+		return value, nil
+	}
+	raw, ok := numericValue(value)
+	if !ok {
+		return value, nil
+	}
+	scaled := raw
+	if decoder.Scale != 0 {
+		scaled *= decoder.Scale
+	}
+	if decoder.ScaleFactor != nil {
+		exp, err := scaleFactor(*decoder.ScaleFactor, address, block)
+		if err != nil {
+			return nil, err
+		}
+		scaled *= math.Pow10(exp)
+	}
+	scaled += decoder.Offset
+	return Scaled{Value: scaled, Raw: value, Unit: decoder.Unit, Text: formatScaled(scaled, decoder)}, nil
+}
+
+func scaleFactor(register, address uint16, block []uint16) (int, error) {
+	if register < address || int(register-address) >= len(block) {
+		return 0, fmt.Errorf("%w: register %d not in read", ErrScaleFactor, register)
+	}
+	exp := int(int16(block[register-address]))
+	if exp == sunSpecNotImplemented {
+		return 0, fmt.Errorf("%w: register %d not implemented", ErrScaleFactor, register)
+	}
+	return exp, nil
+}
+
+func formatScaled(value float64, decoder config.DecoderConfig) string {
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+	if decoder.Decimals != nil {
+		text = strconv.FormatFloat(value, 'f', *decoder.Decimals, 64)
+	}
+	if decoder.Unit != "" {
+		text += " " + decoder.Unit
+	}
+	return text
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case uint16:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeValuesAppliesScaleOffsetAndUnit(t *testing.T) {
+	decimals := 1
+	dec := config.DecoderConfig{Type: config.DecoderInt16, Enabled: true, Scale: 0.1, Offset: -40, Decimals: &decimals, Unit: "°C"}
+	values := DecodeValues(0, []uint16{655}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	scaled := values[0].Value.(Scaled)
+	require.InDelta(t, 25.5, scaled.Value, 1e-9)
+	require.Equal(t, int16(655), scaled.Raw)
+	require.Equal(t, "25.5 °C", scaled.Text)
+}
+
+func TestDecodeValuesUsesScaleFactorRegister(t *testing.T) {
+	sf := uint16(40011)
+	dec := config.DecoderConfig{Type: config.DecoderUint16, Enabled: true, ScaleFactor: &sf, Unit: "W"}
+	values := DecodeValues(40010, []uint16{1234, 0xFFFE}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Equal(t, "12.34 W", values[0].Value.(Scaled).Text)
+}
+
+func TestDecodeValuesScaleFactorOutsideRead(t *testing.T) {
+	sf := uint16(5)
+	dec := config.DecoderConfig{Type: config.DecoderUint16, Enabled: true, ScaleFactor: &sf}
+	values := DecodeValues(0, []uint16{1, 2}, []config.DecoderConfig{dec})
+
+	require.Len(t, values, 1)
+	require.Contains(t, values[0].Error, "register 5 not in read")
+}
+
+func TestApplyScalingRejectsNotImplementedScaleFactor(t *testing.T) {
+	sf := uint16(1)
+	dec := config.DecoderConfig{Type: config.DecoderUint16, ScaleFactor: &sf}
+	_, err := ApplyScaling(uint16(7), dec, 0, []uint16{7, 0x8000})
+
+	require.ErrorIs(t, err, ErrScaleFactor)
+}
+
+func TestApplyScalingLeavesStringsAlone(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderString, Scale: 2}
+	value, err := ApplyScaling("abc", dec, 0, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, "abc", value)
+}
@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"gomodmaster/internal/capture"
 	"gomodmaster/internal/config"
 
 	"github.com/simonvetter/modbus"
@@ -23,11 +24,12 @@ const defaultLogSize = 0
 type EventType string
 
 const (
-	EventData   EventType = "data"
-	EventLog    EventType = "log"
-	EventStats  EventType = "stats"
-	EventError  EventType = "error"
-	EventStatus EventType = "status"
+	EventData    EventType = "data"
+	EventLog     EventType = "log"
+	EventStats   EventType = "stats"
+	EventError   EventType = "error"
+	EventStatus  EventType = "status"
+	EventCapture EventType = "capture"
 )
 
 type Event struct {
@@ -45,6 +47,8 @@ type Service struct {
 	connecting    bool
 	connectStop   chan struct{}
 	lastConnError string
+	capture       *capture.Recorder
+	logFile       *LogFile
 }
 
 type ConnectionStatus struct {
@@ -133,6 +137,17 @@ func (s *Service) Disconnect() error {
 }
 
 func (s *Service) Read(ctx context.Context, req ReadRequest) (ReadResult, error) {
+	return s.read(ctx, req, false)
+}
+
+// ReadRaw reads at the protocol address, ignoring the address base, and
+// neither decodes nor publishes the result, so discovery reads don't replace
+// the last result on screen. Requests are still logged and captured.
+func (s *Service) ReadRaw(ctx context.Context, req ReadRequest) (ReadResult, error) {
+	return s.read(ctx, req, true)
+}
+
+func (s *Service) read(ctx context.Context, req ReadRequest, raw bool) (ReadResult, error) {
 	start := time.Now()
 	result := ReadResult{
 		Kind:     req.Kind,
@@ -151,7 +166,7 @@ func (s *Service) Read(ctx context.Context, req ReadRequest) (ReadResult, error)
 
 	if client == nil {
 		err := ErrNotConnected
-		return s.finishWithError(result, start, err)
+		return s.finishWithError(result, start, err, !raw)
 	}
 
 	unit := req.UnitID
@@ -162,10 +177,14 @@ func (s *Service) Read(ctx context.Context, req ReadRequest) (ReadResult, error)
 		_ = client.SetUnitId(unit)
 	}
 
-	addr := applyAddressBase(req.Address, cfg.AddressBase)
+	addr := req.Address
+	if !raw {
+		addr = applyAddressBase(req.Address, cfg.AddressBase)
+	}
 
 	var err error
 	s.logRequest(req, addr, unit)
+	sent := time.Now()
 	switch req.Kind {
 	case ReadCoils:
 		result.BoolValues, err = client.ReadCoils(addr, req.Quantity)
@@ -180,18 +199,28 @@ func (s *Service) Read(ctx context.Context, req ReadRequest) (ReadResult, error)
 	}
 
 	if err != nil {
-		return s.finishWithError(result, start, err)
+		result, err = s.finishWithError(result, start, err, !raw)
+		s.recordCapture(cfg, req, addr, unit, sent, result, err)
+		return result, err
 	}
 
-	if len(result.RegValues) > 0 {
-		result.Decoded = DecodeValues(result.RegValues, cfg.Decoders)
+	if len(result.RegValues) > 0 && !raw {
+		result.Decoded = DecodeValues(req.Address, result.RegValues, cfg.Decoders)
+		result.Values = DecodeAssignments(req.Address, result.RegValues, AssignmentsFor(req.Kind, cfg.Assignments))
+	}
+	if len(result.BoolValues) > 0 && !raw {
+		result.Decoded = DecodeBools(result.BoolValues, cfg.Decoders)
+		result.Values = DecodeBoolAssignments(req.Address, result.BoolValues, AssignmentsFor(req.Kind, cfg.Assignments))
 	}
 	result.CompletedAt = time.Now()
 	result.LatencyMs = time.Since(start).Milliseconds()
 
 	s.updateStats(result.LatencyMs, "")
 	s.logResponse(req, result)
-	s.emit(Event{Type: EventData, Payload: result})
+	s.recordCapture(cfg, req, addr, unit, sent, result, nil)
+	if !raw {
+		s.emit(Event{Type: EventData, Payload: result})
+	}
 
 	return result, nil
 }
@@ -206,6 +235,10 @@ func (s *Service) Logs() []LogEntry {
 	return s.logs.Snapshot()
 }
 
+func (s *Service) QueryLogs(q LogQuery) LogPage {
+	return s.logs.Query(q)
+}
+
 func (s *Service) IsConnected() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -224,7 +257,7 @@ func (s *Service) LastConnectError() string {
 	return s.lastConnError
 }
 
-func (s *Service) finishWithError(result ReadResult, start time.Time, err error) (ReadResult, error) {
+func (s *Service) finishWithError(result ReadResult, start time.Time, err error, publish bool) (ReadResult, error) {
 	result.CompletedAt = time.Now()
 	result.LatencyMs = time.Since(start).Milliseconds()
 	result.ErrorMessage = err.Error()
@@ -232,7 +265,9 @@ func (s *Service) finishWithError(result ReadResult, start time.Time, err error)
 
 	s.updateStats(result.LatencyMs, result.ErrorMessage)
 	s.logError(err.Error())
-	s.emit(Event{Type: EventError, Payload: result})
+	if publish {
+		s.emit(Event{Type: EventError, Payload: result})
+	}
 	s.maybeReconnect(err)
 	return result, err
 }
@@ -258,16 +293,12 @@ func (s *Service) emit(event Event) {
 
 func (s *Service) logRequest(req ReadRequest, addr uint16, unit uint8) {
 	msg := fmt.Sprintf("tx %s fc=%s addr=0x%04x qty=0x%04x unit=0x%02x", req.Kind, functionCode(req.Kind), addr, req.Quantity, unit)
-	entry := LogEntry{Time: time.Now(), Direction: "tx", Message: msg}
-	s.logs.Add(entry)
-	s.emit(Event{Type: EventLog, Payload: entry})
+	s.addLog(LogEntry{Time: time.Now(), Direction: "tx", Message: msg})
 }
 
 func (s *Service) logResponse(req ReadRequest, result ReadResult) {
 	msg := fmt.Sprintf("rx %s fc=%s addr=0x%04x qty=0x%04x latency=%dms", req.Kind, functionCode(req.Kind), result.Address, result.Quantity, result.LatencyMs)
-	entry := LogEntry{Time: time.Now(), Direction: "rx", Message: msg}
-	s.logs.Add(entry)
-	s.emit(Event{Type: EventLog, Payload: entry})
+	s.addLog(LogEntry{Time: time.Now(), Direction: "rx", Message: msg})
 }
 
 func functionCode(kind ReadKind) string {
@@ -286,17 +317,51 @@ func functionCode(kind ReadKind) string {
 }
 
 func (s *Service) logError(msg string) {
-	entry := LogEntry{Time: time.Now(), Direction: "err", Message: msg}
-	s.logs.Add(entry)
-	s.emit(Event{Type: EventLog, Payload: entry})
+	s.addLog(LogEntry{Time: time.Now(), Direction: "err", Message: msg})
 }
 
 func (s *Service) logInfo(msg string) {
-	entry := LogEntry{Time: time.Now(), Direction: "sys", Message: msg}
-	s.logs.Add(entry)
+	s.addLog(LogEntry{Time: time.Now(), Direction: "sys", Message: msg})
+}
+
+func (s *Service) addLog(entry LogEntry) {
+	entry = s.logs.Add(entry)
+	s.mu.Lock()
+	logFile := s.logFile
+	s.mu.Unlock()
+	if logFile != nil {
+		_ = logFile.Write(entry)
+	}
 	s.emit(Event{Type: EventLog, Payload: entry})
 }
 
+func (s *Service) OpenLogFile(cfg config.LogFileConfig) error {
+	logFile, err := OpenLogFile(cfg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	previous := s.logFile
+	s.logFile = logFile
+	s.mu.Unlock()
+	if previous != nil {
+		_ = previous.Close()
+	}
+	s.logInfo(fmt.Sprintf("logging to %s", cfg.Path))
+	return nil
+}
+
+func (s *Service) CloseLogFile() error {
+	s.mu.Lock()
+	logFile := s.logFile
+	s.logFile = nil
+	s.mu.Unlock()
+	if logFile == nil {
+		return nil
+	}
+	return logFile.Close()
+}
+
 func (s *Service) connectLoop(stop <-chan struct{}) {
 	backoff := 500 * time.Millisecond
 	attempt := 0
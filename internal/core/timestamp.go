@@ -0,0 +1,79 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gomodmaster/internal/config"
+)
+
+// Timestamp is a decoded date/time, rendered as ISO-8601 in the decoder's
+// timezone. Millis keeps the millisecond part for epoch-ms sources.
+type Timestamp struct {
+	Time   time.Time
+	Millis bool
+}
+
+func (t Timestamp) String() string {
+	if t.Millis {
+		return t.Time.Format("2006-01-02T15:04:05.000Z07:00")
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func decodeEpoch(raw int64, decoder config.DecoderConfig) (Timestamp, error) {
+	loc, err := timezone(decoder.Timezone)
+	if err != nil {
+		return Timestamp{}, err
+	}
+	if decoder.Millis {
+		return Timestamp{Time: time.UnixMilli(raw).In(loc), Millis: true}, nil
+	}
+	return Timestamp{Time: time.Unix(raw, 0).In(loc)}, nil
+}
+
+// decodeDateTime reads wall-clock fields year, month, day, hour, minute,
+// second: one per register, or with three registers one per byte. Two-digit
+// years are taken as 20YY.
+func decodeDateTime(regs []uint16, decoder config.DecoderConfig) (Timestamp, error) {
+	loc, err := timezone(decoder.Timezone)
+	if err != nil {
+		return Timestamp{}, err
+	}
+	count := RegisterSpan(decoder)
+	bytes := orderedBytes(regs, count, decoder)
+	fields := make([]int, 6)
+	for i := range fields {
+		if count == 3 {
+			fields[i] = int(bytes[i])
+		} else {
+			fields[i] = int(binary.BigEndian.Uint16(bytes[i*2:]))
+		}
+	}
+	year, month, day, hour, minute, second := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+	if year < 100 {
+		year += 2000
+	}
+	value := time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+	if month < 1 || month > 12 || hour > 23 || minute > 59 || second > 59 || value.Day() != day {
+		return Timestamp{}, fmt.Errorf("invalid date %04d-%02d-%02d %02d:%02d:%02d", year, month, day, hour, minute, second)
+	}
+	return Timestamp{Time: value}, nil
+}
+
+func timezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
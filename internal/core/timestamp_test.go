@@ -0,0 +1,65 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEpoch32(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderEpoch32}
+	value, err := DecodeAt([]uint16{0x6553, 0xF100}, dec)
+	require.NoError(t, err)
+	require.Equal(t, "2023-11-14T22:13:20Z", value.(Timestamp).String())
+
+	dec.Timezone = "Europe/Berlin"
+	value, err = DecodeAt([]uint16{0x6553, 0xF100}, dec)
+	require.NoError(t, err)
+	require.Equal(t, "2023-11-14T23:13:20+01:00", value.(Timestamp).String())
+}
+
+func TestDecodeEpoch64Millis(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderEpoch64, Millis: true}
+	// 1700000000123 ms
+	value, err := DecodeAt([]uint16{0x0000, 0x018B, 0xCFE5, 0x687B}, dec)
+	require.NoError(t, err)
+	require.Equal(t, "2023-11-14T22:13:20.123Z", value.(Timestamp).String())
+}
+
+func TestDecodeDateTime(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderDate, Registers: 6, Timezone: "America/New_York"}
+	value, err := DecodeAt([]uint16{2024, 7, 4, 12, 30, 5}, dec)
+	require.NoError(t, err)
+	require.Equal(t, "2024-07-04T12:30:05-04:00", value.(Timestamp).String())
+
+	packed := config.DecoderConfig{Type: config.DecoderDate, Registers: 3}
+	value, err = DecodeAt([]uint16{0x1802, 0x1D17, 0x3B3B}, packed)
+	require.NoError(t, err)
+	require.Equal(t, "2024-02-29T23:59:59Z", value.(Timestamp).String())
+}
+
+func TestDecodeDateTimeInvalid(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderDate, Registers: 6}
+	_, err := DecodeAt([]uint16{2023, 2, 29, 0, 0, 0}, dec)
+	require.Error(t, err)
+
+	_, err = DecodeAt([]uint16{2023, 13, 1, 0, 0, 0}, dec)
+	require.Error(t, err)
+}
+
+func TestDecodeTimestampUnknownZone(t *testing.T) {
+	dec := config.DecoderConfig{Type: config.DecoderEpoch32, Timezone: "Mars/Olympus"}
+	_, err := DecodeAt([]uint16{0, 0}, dec)
+	require.Error(t, err)
+}
+
+func TestTimestampJSON(t *testing.T) {
+	value, err := DecodeAt([]uint16{0, 0}, config.DecoderConfig{Type: config.DecoderEpoch32})
+	require.NoError(t, err)
+	data, err := json.Marshal(DecodedValue{Type: config.DecoderEpoch32, Value: value})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"epoch32","value":"1970-01-01T00:00:00Z"}`, string(data))
+}
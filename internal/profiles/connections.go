@@ -0,0 +1,276 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gomodmaster/internal/config"
+)
+
+// ErrNotFound is returned for a profile name that isn't saved or in the
+// library.
+var ErrNotFound = errors.New("profile not found")
+
+// Connection is a named set of connection settings saved in the connection
+// profiles file.
+type Connection struct {
+	Name      string                 `json:"name"`
+	Protocol  config.Protocol        `json:"protocol"`
+	Serial    config.SerialConfig    `json:"serial"`
+	TCP       config.TCPConfig       `json:"tcp"`
+	UnitID    uint8                  `json:"unitId"`
+	TimeoutMs int64                  `json:"timeoutMs"`
+	Decoders  []config.DecoderConfig `json:"decoders,omitempty"`
+}
+
+type connectionsFile struct {
+	Version  int          `json:"version"`
+	Profiles []Connection `json:"profiles"`
+}
+
+// connectionMigrations upgrade connection profile files saved by earlier
+// builds.
+var connectionMigrations = config.Migrations{
+	connectionsV1,
+}
+
+// connectionsV1 lower-cases the protocol and parity of each profile, which
+// unversioned files could hold in any case.
+func connectionsV1(doc map[string]any) error {
+	list, _ := doc["profiles"].([]any)
+	for _, item := range list {
+		if connection, ok := item.(map[string]any); ok {
+			config.NormalizeConnection(connection)
+		}
+	}
+	return nil
+}
+
+// ConnectionsFile is where connection profiles are saved.
+func ConnectionsFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gmm", "connections.yaml"), nil
+}
+
+// NewConnection captures the connection settings and decoders of cfg.
+func NewConnection(name string, cfg config.Config) Connection {
+	return Connection{
+		Name:      name,
+		Protocol:  cfg.Protocol,
+		Serial:    cfg.Serial,
+		TCP:       cfg.TCP,
+		UnitID:    cfg.UnitID,
+		TimeoutMs: cfg.TimeoutMs,
+		Decoders:  slices.Clone(cfg.Decoders),
+	}
+}
+
+// Validate checks the profile can be saved and applied: it needs a name, and
+// the default configuration with the profile applied must pass
+// config.Validate, whose field errors are returned. Unlike Apply, the
+// protocol and the address it connects to are taken even when zero.
+func (c Connection) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("profile name is required")
+	}
+	cfg := config.DefaultConfig()
+	c.Apply(&cfg, nil)
+	cfg.Protocol = c.Protocol
+	switch c.Protocol {
+	case config.ProtocolTCP:
+		cfg.TCP = c.TCP
+	case config.ProtocolRTU:
+		cfg.Serial.Device = c.Serial.Device
+		cfg.Serial.Speed = c.Serial.Speed
+	}
+	return cfg.Validate()
+}
+
+// Apply copies the profile's settings into cfg; zero fields leave it alone.
+// keep works like it does for Profile.Apply, and decoders are asked by their
+// flag name (u16, f32, ...).
+func (c Connection) Apply(cfg *config.Config, keep func(flag string) bool) {
+	set := func(flag string, ok bool) bool {
+		return ok && (keep == nil || !keep(flag))
+	}
+	if set("protocol", c.Protocol != "") {
+		cfg.Protocol = c.Protocol
+	}
+	if set("serial", c.Serial.Device != "") {
+		cfg.Serial.Device = c.Serial.Device
+	}
+	if set("speed", c.Serial.Speed != 0) {
+		cfg.Serial.Speed = c.Serial.Speed
+	}
+	if set("databits", c.Serial.DataBits != 0) {
+		cfg.Serial.DataBits = c.Serial.DataBits
+	}
+	if set("parity", c.Serial.Parity != "") {
+		cfg.Serial.Parity = c.Serial.Parity
+	}
+	if set("stopbits", c.Serial.StopBits != 0) {
+		cfg.Serial.StopBits = c.Serial.StopBits
+	}
+	if set("host", c.TCP.Host != "") {
+		cfg.TCP.Host = c.TCP.Host
+	}
+	if set("port", c.TCP.Port != 0) {
+		cfg.TCP.Port = c.TCP.Port
+	}
+	if set("unit-id", c.UnitID != 0) {
+		cfg.UnitID = c.UnitID
+	}
+	if set("timeout", c.TimeoutMs != 0) {
+		cfg.TimeoutMs = c.TimeoutMs
+	}
+	for _, decoder := range c.Decoders {
+		if !set(config.DecoderFlag(decoder.Type), true) {
+			continue
+		}
+		for i := range cfg.Decoders {
+			if cfg.Decoders[i].Type == decoder.Type {
+				cfg.Decoders[i] = decoder
+			}
+		}
+	}
+	cfg.Profile = c.Name
+}
+
+// LoadConnections reads the connection profiles saved in path, upgrading
+// files saved by earlier builds. A missing file has none.
+func LoadConnections(path string) ([]Connection, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Connection{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file connectionsFile
+	if err := connectionMigrations.Unmarshal(path, data, &file); err != nil {
+		return nil, err
+	}
+	if file.Profiles == nil {
+		file.Profiles = []Connection{}
+	}
+	return file.Profiles, nil
+}
+
+// SaveConnections writes the connection profiles to path, sorted by name.
+func SaveConnections(path string, connections []Connection) error {
+	connections = slices.Clone(connections)
+	slices.SortFunc(connections, func(a, b Connection) int { return strings.Compare(a.Name, b.Name) })
+	data, err := config.MarshalFile(path, connectionsFile{Version: connectionMigrations.Version(), Profiles: connections})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// FindConnection returns the connection profile called name.
+func FindConnection(connections []Connection, name string) (Connection, error) {
+	for _, connection := range connections {
+		if strings.EqualFold(connection.Name, name) {
+			return connection, nil
+		}
+	}
+	return Connection{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+}
+
+// PutConnection adds connection to path, replacing a profile of the same
+// name.
+func PutConnection(path string, connection Connection) error {
+	if err := connection.Validate(); err != nil {
+		return err
+	}
+	connections, err := LoadConnections(path)
+	if err != nil {
+		return err
+	}
+	connections = slices.DeleteFunc(connections, func(c Connection) bool {
+		return strings.EqualFold(c.Name, connection.Name)
+	})
+	return SaveConnections(path, append(connections, connection))
+}
+
+// DeleteConnection removes the profile called name from path.
+func DeleteConnection(path, name string) error {
+	connections, err := LoadConnections(path)
+	if err != nil {
+		return err
+	}
+	if _, err := FindConnection(connections, name); err != nil {
+		return err
+	}
+	connections = slices.DeleteFunc(connections, func(c Connection) bool {
+		return strings.EqualFold(c.Name, name)
+	})
+	return SaveConnections(path, connections)
+}
+
+// Settings is a connection or device profile.
+type Settings interface {
+	Apply(cfg *config.Config, keep func(flag string) bool)
+}
+
+// Lookup returns the connection profile called name or, when there is none,
+// the device profile of that name.
+func Lookup(name string) (Settings, error) {
+	path, err := ConnectionsFile()
+	if err != nil {
+		return nil, err
+	}
+	connections, err := LoadConnections(path)
+	if err != nil {
+		return nil, err
+	}
+	if connection, err := FindConnection(connections, name); err == nil {
+		return connection, nil
+	}
+	device, err := Find(name)
+	if err != nil {
+		if len(connections) > 0 {
+			names := []string{}
+			for _, connection := range connections {
+				names = append(names, connection.Name)
+			}
+			return nil, fmt.Errorf("%w; saved connections: %s", err, strings.Join(names, ", "))
+		}
+		return nil, err
+	}
+	return device, nil
+}
+
+// Switch applies settings to cfg the way the TUI and web UI do. A device
+// profile replaces the mapped points of a previous profile or map file, and
+// so does a connection profile replacing a device profile: only the profile
+// name is saved, so its points would be lost on the next load.
+func Switch(cfg *config.Config, settings Settings) {
+	_, device := settings.(Profile)
+	if !device && cfg.Profile != "" {
+		if previous, err := Lookup(cfg.Profile); err == nil {
+			_, device = previous.(Profile)
+		}
+	}
+	if device {
+		assignments := []config.Assignment{}
+		for _, assignment := range cfg.Assignments {
+			if !assignment.Mapped {
+				assignments = append(assignments, assignment)
+			}
+		}
+		cfg.Assignments = assignments
+		cfg.MapFile = ""
+	}
+	settings.Apply(cfg, nil)
+}
@@ -0,0 +1,151 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionsCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gmm", "connections.yaml")
+	connections, err := LoadConnections(path)
+	require.NoError(t, err)
+	require.Empty(t, connections)
+
+	cfg := config.DefaultConfig()
+	cfg.TCP.Host = "10.0.0.7"
+	cfg.UnitID = 3
+	cfg.Decoders[5].Enabled = true
+	require.NoError(t, PutConnection(path, NewConnection("boiler", cfg)))
+	require.NoError(t, PutConnection(path, Connection{Name: "bench", Protocol: config.ProtocolRTU, Serial: config.SerialConfig{Device: "/dev/ttyUSB1", Speed: 19200}}))
+	require.Error(t, PutConnection(path, Connection{Name: " ", Protocol: config.ProtocolTCP}))
+	require.Error(t, PutConnection(path, Connection{Name: "x", Protocol: "ascii"}))
+
+	var invalid config.ValidationError
+	require.ErrorAs(t, PutConnection(path, Connection{Name: "x", Protocol: config.ProtocolTCP, TCP: config.TCPConfig{Host: "plc"}}), &invalid)
+	require.Equal(t, "must be 1-65535", invalid.Message("tcp.port"))
+	require.ErrorAs(t, PutConnection(path, Connection{Name: "x", Protocol: config.ProtocolRTU, Serial: config.SerialConfig{Device: "/dev/ttyUSB1"}}), &invalid)
+	require.Equal(t, "must be > 0", invalid.Message("serial.speed"))
+
+	connections, err = LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+	require.Equal(t, "bench", connections[0].Name)
+	boiler, err := FindConnection(connections, "BOILER")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.7", boiler.TCP.Host)
+	require.True(t, boiler.Decoders[5].Enabled)
+
+	require.NoError(t, PutConnection(path, Connection{Name: "boiler", Protocol: config.ProtocolTCP, TCP: config.TCPConfig{Host: "10.0.0.8", Port: 502}}))
+	connections, err = LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+	boiler, err = FindConnection(connections, "boiler")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.8", boiler.TCP.Host)
+
+	require.NoError(t, DeleteConnection(path, "bench"))
+	require.ErrorIs(t, DeleteConnection(path, "bench"), ErrNotFound)
+	connections, err = LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+}
+
+func TestConnectionApply(t *testing.T) {
+	saved := config.DefaultConfig()
+	saved.Protocol = config.ProtocolRTU
+	saved.Serial.Device = "/dev/ttyS2"
+	saved.Serial.Parity = "even"
+	saved.UnitID = 9
+	saved.Decoders[0].Enabled = true
+	saved.Decoders[5].Enabled = true
+	connection := NewConnection("meter", saved)
+
+	cfg := config.DefaultConfig()
+	connection.Apply(&cfg, func(flag string) bool { return flag == "unit-id" || flag == "f32" })
+	require.Equal(t, config.ProtocolRTU, cfg.Protocol)
+	require.Equal(t, "/dev/ttyS2", cfg.Serial.Device)
+	require.Equal(t, "even", cfg.Serial.Parity)
+	require.Equal(t, uint8(1), cfg.UnitID)
+	require.True(t, cfg.Decoders[0].Enabled)
+	require.False(t, cfg.Decoders[5].Enabled)
+	require.Equal(t, "meter", cfg.Profile)
+
+	cfg = config.DefaultConfig()
+	Connection{Name: "partial", TCP: config.TCPConfig{Host: "plc"}}.Apply(&cfg, nil)
+	require.Equal(t, "plc", cfg.TCP.Host)
+	require.Equal(t, 502, cfg.TCP.Port)
+	require.Equal(t, config.DefaultConfig().Serial, cfg.Serial)
+}
+
+func TestLookupPrefersConnections(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	path, err := ConnectionsFile()
+	require.NoError(t, err)
+	require.NoError(t, PutConnection(path, Connection{Name: "eastron-sdm120", Protocol: config.ProtocolTCP, TCP: config.TCPConfig{Host: "plc", Port: 502}}))
+
+	settings, err := Lookup("eastron-sdm120")
+	require.NoError(t, err)
+	require.IsType(t, Connection{}, settings)
+	settings, err = Lookup("eastron-sdm630")
+	require.NoError(t, err)
+	require.IsType(t, Profile{}, settings)
+	_, err = Lookup("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorContains(t, err, "saved connections: eastron-sdm120")
+}
+
+func TestConnectionsV1(t *testing.T) {
+	doc := map[string]any{"profiles": []any{
+		map[string]any{"name": "bench", "protocol": "RTU", "serial": map[string]any{"parity": "Odd"}},
+		map[string]any{"name": "plc", "protocol": "tcp"},
+	}}
+	require.NoError(t, connectionsV1(doc))
+	bench := doc["profiles"].([]any)[0].(map[string]any)
+	require.Equal(t, "rtu", bench["protocol"])
+	require.Equal(t, "odd", bench["serial"].(map[string]any)["parity"])
+	require.NoError(t, connectionsV1(map[string]any{}))
+}
+
+func TestLoadConnectionsMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connections.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  - name: bench\n    protocol: RTU\n    serial:\n      device: /dev/ttyUSB1\n      parity: Even\n"), 0o644))
+
+	connections, err := LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+	require.Equal(t, config.ProtocolRTU, connections[0].Protocol)
+	require.Equal(t, "even", connections[0].Serial.Parity)
+
+	require.NoError(t, SaveConnections(path, connections))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "version: 1\n")
+}
+
+func TestSwitchDropsDeviceProfilePoints(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	device, err := Find("eastron-sdm120")
+	require.NoError(t, err)
+	pinned := config.Assignment{Address: 5, Decoder: config.DecoderConfig{Type: config.DecoderUint16}}
+
+	cfg := config.DefaultConfig()
+	cfg.Assignments = []config.Assignment{pinned}
+	Switch(&cfg, device)
+	require.Greater(t, len(cfg.Assignments), 1)
+
+	Switch(&cfg, Connection{Name: "bench", Protocol: config.ProtocolTCP})
+	require.Equal(t, []config.Assignment{pinned}, cfg.Assignments)
+	require.Equal(t, "bench", cfg.Profile)
+
+	cfg.MapFile = "meter.yaml"
+	cfg.Assignments = append(cfg.Assignments, config.Assignment{Address: 9, Mapped: true})
+	Switch(&cfg, Connection{Name: "plc", Protocol: config.ProtocolTCP})
+	require.Len(t, cfg.Assignments, 2)
+	require.Equal(t, "meter.yaml", cfg.MapFile)
+}
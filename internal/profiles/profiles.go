@@ -0,0 +1,211 @@
+// Package profiles is the device profile library: connection defaults and a
+// register map per device model, built in or added as YAML files to the user
+// profile directory.
+package profiles
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/regmap"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed library/*.yaml
+var library embed.FS
+
+// SourceBuiltin is the Source of profiles shipped with gmm.
+const SourceBuiltin = "builtin"
+
+// Profile is a device profile. Zero fields leave the configuration alone.
+type Profile struct {
+	Name      string          `json:"name" yaml:"name"`
+	Title     string          `json:"title,omitempty" yaml:"title,omitempty"`
+	Source    string          `json:"source" yaml:"-"`
+	Protocol  config.Protocol `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Serial    SerialDefaults  `json:"serial,omitempty" yaml:"serial,omitempty"`
+	TCP       TCPDefaults     `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	UnitID    uint8           `json:"unitId,omitempty" yaml:"unitId,omitempty"`
+	TimeoutMs int64           `json:"timeoutMs,omitempty" yaml:"timeoutMs,omitempty"`
+	Read      ReadDefaults    `json:"read,omitempty" yaml:"read,omitempty"`
+	Map       regmap.Map      `json:"map" yaml:"-"`
+}
+
+type SerialDefaults struct {
+	Speed    uint   `json:"speed,omitempty" yaml:"speed,omitempty"`
+	DataBits uint   `json:"dataBits,omitempty" yaml:"databits,omitempty"`
+	Parity   string `json:"parity,omitempty" yaml:"parity,omitempty"`
+	StopBits uint   `json:"stopBits,omitempty" yaml:"stopbits,omitempty"`
+}
+
+type TCPDefaults struct {
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+type ReadDefaults struct {
+	Function string `json:"function,omitempty" yaml:"function,omitempty"`
+	Address  uint16 `json:"address,omitempty" yaml:"address,omitempty"`
+	Count    uint16 `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+// Parse reads a profile file: the profile fields next to the points list of
+// a YAML register map. name defaults to the file name without extension.
+func Parse(data []byte, name string) (Profile, error) {
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return Profile{}, err
+	}
+	if profile.Name == "" {
+		profile.Name = name
+	}
+	switch profile.Protocol {
+	case "", config.ProtocolTCP, config.ProtocolRTU:
+	default:
+		return Profile{}, fmt.Errorf("unsupported protocol %q (use tcp or rtu)", profile.Protocol)
+	}
+	switch profile.Serial.Parity {
+	case "", "none", "even", "odd":
+	default:
+		return Profile{}, fmt.Errorf("unsupported parity %q (use none, even or odd)", profile.Serial.Parity)
+	}
+	if profile.Read.Function != "" {
+		kind, err := config.ParseReadKind(profile.Read.Function)
+		if err != nil {
+			return Profile{}, err
+		}
+		profile.Read.Function = kind
+	}
+	m, err := regmap.ParseYAML(data)
+	if err != nil {
+		return Profile{}, err
+	}
+	profile.Map = m
+	return profile, nil
+}
+
+// UserDir is the directory user profiles are loaded from.
+func UserDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gmm", "profiles"), nil
+}
+
+// Library lists the built-in profiles and those in the user profile
+// directory, sorted by name. A user profile replaces a built-in one of the
+// same name; user files that fail to parse are returned as errors next to
+// the profiles that did.
+func Library() ([]Profile, []error) {
+	byName := map[string]Profile{}
+	errs := []error{}
+	entries, _ := fs.Glob(library, "library/*.yaml")
+	for _, entry := range entries {
+		data, _ := library.ReadFile(entry)
+		profile, err := Parse(data, strings.TrimSuffix(filepath.Base(entry), ".yaml"))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry, err))
+			continue
+		}
+		profile.Source = SourceBuiltin
+		byName[profile.Name] = profile
+	}
+	if dir, err := UserDir(); err == nil {
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			paths, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, path := range paths {
+				profile, err := load(path)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				byName[profile.Name] = profile
+			}
+		}
+	}
+	out := make([]Profile, 0, len(byName))
+	for _, profile := range byName {
+		out = append(out, profile)
+	}
+	slices.SortFunc(out, func(a, b Profile) int { return strings.Compare(a.Name, b.Name) })
+	return out, errs
+}
+
+func load(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	profile, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if err != nil {
+		return Profile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	profile.Source = path
+	return profile, nil
+}
+
+// Find returns the library profile called name.
+func Find(name string) (Profile, error) {
+	all, _ := Library()
+	names := make([]string, 0, len(all))
+	for _, profile := range all {
+		if strings.EqualFold(profile.Name, name) {
+			return profile, nil
+		}
+		names = append(names, profile.Name)
+	}
+	return Profile{}, fmt.Errorf("%w: %q (available: %s)", ErrNotFound, name, strings.Join(names, ", "))
+}
+
+// Apply copies the profile's defaults into cfg and appends its points as
+// mapped assignments. keep, when set, is asked with the CLI flag name of each
+// setting (protocol, speed, databits, parity, stopbits, port, unit-id,
+// timeout, function, address, count) and leaves the setting alone when it
+// returns true, so explicit flags win over the profile.
+func (p Profile) Apply(cfg *config.Config, keep func(flag string) bool) {
+	set := func(flag string, ok bool) bool {
+		return ok && (keep == nil || !keep(flag))
+	}
+	if set("protocol", p.Protocol != "") {
+		cfg.Protocol = p.Protocol
+	}
+	if set("speed", p.Serial.Speed != 0) {
+		cfg.Serial.Speed = p.Serial.Speed
+	}
+	if set("databits", p.Serial.DataBits != 0) {
+		cfg.Serial.DataBits = p.Serial.DataBits
+	}
+	if set("parity", p.Serial.Parity != "") {
+		cfg.Serial.Parity = p.Serial.Parity
+	}
+	if set("stopbits", p.Serial.StopBits != 0) {
+		cfg.Serial.StopBits = p.Serial.StopBits
+	}
+	if set("port", p.TCP.Port != 0) {
+		cfg.TCP.Port = p.TCP.Port
+	}
+	if set("unit-id", p.UnitID != 0) {
+		cfg.UnitID = p.UnitID
+	}
+	if set("timeout", p.TimeoutMs != 0) {
+		cfg.TimeoutMs = p.TimeoutMs
+	}
+	if set("function", p.Read.Function != "") {
+		cfg.ReadKind = p.Read.Function
+	}
+	if set("address", p.Read.Function != "" || p.Read.Address != 0) {
+		cfg.ReadAddress = p.Read.Address
+	}
+	if set("count", p.Read.Count != 0) {
+		cfg.ReadQuantity = p.Read.Count
+	}
+	cfg.Profile = p.Name
+	cfg.Assignments = append(cfg.Assignments, p.Map.Assignments()...)
+}
@@ -0,0 +1,89 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinProfilesParse(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	library, errs := Library()
+	require.Empty(t, errs)
+	require.NotEmpty(t, library)
+	for _, profile := range library {
+		require.Equal(t, SourceBuiltin, profile.Source)
+		require.NotEmpty(t, profile.Title, profile.Name)
+		require.NotEmpty(t, profile.Map.Points, profile.Name)
+	}
+}
+
+func TestUserProfileOverridesBuiltin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	dir := filepath.Join(home, "gmm", "profiles")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eastron-sdm120.yaml"), []byte(`title: Patched SDM120
+protocol: rtu
+serial: { speed: 9600 }
+points:
+  - { name: voltage, kind: input, address: 0, type: f32, unit: V }
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yml"), []byte("protocol: ascii\n"), 0o644))
+
+	library, errs := Library()
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "broken.yml")
+
+	profile, err := Find("EASTRON-SDM120")
+	require.NoError(t, err)
+	require.Equal(t, "Patched SDM120", profile.Title)
+	require.Equal(t, filepath.Join(dir, "eastron-sdm120.yaml"), profile.Source)
+	require.Len(t, profile.Map.Points, 1)
+	require.Len(t, library, len(builtinFiles(t)))
+
+	_, err = Find("missing")
+	require.ErrorContains(t, err, "available:")
+}
+
+func builtinFiles(t *testing.T) []string {
+	t.Helper()
+	entries, err := library.ReadDir("library")
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestApplyKeepsExplicitFlags(t *testing.T) {
+	profile, err := Parse([]byte(`protocol: rtu
+serial: { speed: 19200, databits: 8, parity: even, stopbits: 1 }
+unitId: 3
+read: { function: holding, address: 100, count: 4 }
+points:
+  - { name: speed, kind: holding, address: 101, type: i16, unit: rpm }
+`), "drive")
+	require.NoError(t, err)
+	require.Equal(t, "holding_registers", profile.Read.Function)
+
+	cfg := config.DefaultConfig()
+	cfg.UnitID = 7
+	profile.Apply(&cfg, func(flag string) bool { return flag == "unit-id" })
+	require.Equal(t, config.ProtocolRTU, cfg.Protocol)
+	require.Equal(t, uint(19200), cfg.Serial.Speed)
+	require.Equal(t, "even", cfg.Serial.Parity)
+	require.Equal(t, uint8(7), cfg.UnitID)
+	require.Equal(t, "holding_registers", cfg.ReadKind)
+	require.Equal(t, uint16(100), cfg.ReadAddress)
+	require.Equal(t, uint16(4), cfg.ReadQuantity)
+	require.Equal(t, "drive", cfg.Profile)
+	require.Len(t, cfg.Assignments, 1)
+	require.True(t, cfg.Assignments[0].Mapped)
+	require.Equal(t, "speed", cfg.Assignments[0].Name)
+}
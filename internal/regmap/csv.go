@@ -0,0 +1,91 @@
+package regmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps accepted header names to rawPoint fields.
+var csvColumns = map[string]func(*rawPoint) *string{
+	"name":        func(p *rawPoint) *string { return &p.Name },
+	"kind":        func(p *rawPoint) *string { return &p.Kind },
+	"address":     func(p *rawPoint) *string { return &p.Address },
+	"type":        func(p *rawPoint) *string { return &p.Type },
+	"order":       func(p *rawPoint) *string { return &p.Order },
+	"byte_order":  func(p *rawPoint) *string { return &p.Order },
+	"length":      func(p *rawPoint) *string { return &p.Length },
+	"scale":       func(p *rawPoint) *string { return &p.Scale },
+	"unit":        func(p *rawPoint) *string { return &p.Unit },
+	"access":      func(p *rawPoint) *string { return &p.Access },
+	"block":       func(p *rawPoint) *string { return &p.Block },
+	"description": func(p *rawPoint) *string { return &p.Description },
+}
+
+// ParseCSV reads a map with a header row naming the columns (name, kind,
+// address, type, order, length, scale, unit, access, block, description;
+// case-insensitive, any order). Other columns are ignored, as are blank rows.
+func ParseCSV(data []byte) (Map, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Map{}, err
+	}
+	if len(records) == 0 {
+		return Map{}, fmt.Errorf("missing header row")
+	}
+	columns := make([]func(*rawPoint) *string, len(records[0]))
+	found := map[string]bool{}
+	for i, header := range records[0] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		columns[i] = csvColumns[name]
+		found[name] = true
+	}
+	for _, required := range []string{"address", "type"} {
+		if !found[required] {
+			return Map{}, fmt.Errorf("missing %q column", required)
+		}
+	}
+	m := Map{Points: []Point{}}
+	for line, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		var raw rawPoint
+		for i, value := range record {
+			if i < len(columns) && columns[i] != nil {
+				*columns[i](&raw) = value
+			}
+		}
+		point, err := raw.point()
+		if err != nil {
+			return Map{}, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		m.Points = append(m.Points, point)
+	}
+	return m, nil
+}
+
+// FormatCSV writes a map in the form ParseCSV reads.
+func FormatCSV(m Map) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"name", "kind", "address", "type", "order", "length", "scale", "unit", "access", "block", "description"})
+	for _, point := range m.Points {
+		var length, scale string
+		if point.Length > 0 {
+			length = strconv.Itoa(point.Length)
+		}
+		if point.Scale != 0 {
+			scale = strconv.FormatFloat(point.Scale, 'g', -1, 64)
+		}
+		_ = writer.Write([]string{point.Name, point.Kind, strconv.Itoa(int(point.Address)), string(point.Type),
+			string(point.ByteOrder), length, scale, point.Unit, point.Access, point.Block, point.Description})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -0,0 +1,177 @@
+package regmap
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"gomodmaster/internal/core"
+)
+
+// DocOptions controls generated documentation. Values, when set, holds a
+// live value per point (as returned by LiveValues) read at ReadAt.
+type DocOptions struct {
+	Title  string
+	Values []string
+	ReadAt time.Time
+}
+
+type docGroup struct {
+	Name string
+	Rows []docRow
+}
+
+type docRow struct {
+	Address     string
+	Name        string
+	Type        string
+	Size        string
+	Unit        string
+	Scale       string
+	Access      string
+	Description string
+	Value       string
+}
+
+var kindTitles = map[string]string{
+	"coils":             "Coils",
+	"discrete_inputs":   "Discrete inputs",
+	"holding_registers": "Holding registers",
+	"input_registers":   "Input registers",
+}
+
+var accessTitles = map[string]string{"r": "R", "rw": "R/W", "w": "W"}
+
+// docGroups groups the points by block, or by kind for points without one,
+// in the order they first appear.
+func docGroups(m Map, values []string) []docGroup {
+	groups := []docGroup{}
+	index := map[string]int{}
+	for i, assignment := range m.Assignments() {
+		point := m.Points[i]
+		name := point.Block
+		if name == "" {
+			name = kindTitles[point.Kind]
+		}
+		if _, ok := index[name]; !ok {
+			index[name] = len(groups)
+			groups = append(groups, docGroup{Name: name})
+		}
+		typ := string(point.Type)
+		if point.ByteOrder != "" {
+			typ += " " + string(point.ByteOrder)
+		}
+		span := core.RegisterSpan(assignment.Decoder)
+		size := fmt.Sprintf("%d", span)
+		if point.Kind == "coils" || point.Kind == "discrete_inputs" {
+			size += " bits"
+			if span == 1 {
+				size = "1 bit"
+			}
+		}
+		row := docRow{
+			Address:     fmt.Sprintf("%d (0x%04X)", point.Address, point.Address),
+			Name:        point.Name,
+			Type:        typ,
+			Size:        size,
+			Unit:        point.Unit,
+			Access:      accessTitles[point.Access],
+			Description: point.Description,
+		}
+		if point.Scale != 0 {
+			row.Scale = fmt.Sprint(point.Scale)
+		}
+		if i < len(values) {
+			row.Value = values[i]
+		}
+		groups[index[name]].Rows = append(groups[index[name]].Rows, row)
+	}
+	return groups
+}
+
+func docHeaders(live bool) []string {
+	headers := []string{"Address", "Name", "Type", "Size", "Unit", "Scale", "Access", "Description"}
+	if live {
+		headers = append(headers, "Value")
+	}
+	return headers
+}
+
+func (row docRow) cells(live bool) []string {
+	cells := []string{row.Address, row.Name, row.Type, row.Size, row.Unit, row.Scale, row.Access, row.Description}
+	if live {
+		cells = append(cells, row.Value)
+	}
+	return cells
+}
+
+// RenderMarkdown renders the map as one Markdown table per block.
+func RenderMarkdown(m Map, opts DocOptions) []byte {
+	var b strings.Builder
+	live := opts.Values != nil
+	fmt.Fprintf(&b, "# %s\n\n", opts.Title)
+	if live {
+		fmt.Fprintf(&b, "Values read %s.\n\n", opts.ReadAt.Format(time.RFC3339))
+	}
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, group := range docGroups(m, opts.Values) {
+		fmt.Fprintf(&b, "## %s\n\n", group.Name)
+		headers := docHeaders(live)
+		b.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+		for _, row := range group.Rows {
+			cells := row.cells(live)
+			for i, cell := range cells {
+				cells[i] = escape.Replace(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+var htmlDoc = template.Must(template.New("doc").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; font-size: 0.9rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+td:first-child, td.value { font-family: ui-monospace, monospace; white-space: nowrap; }
+.read { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Live}}<p class="read">Values read {{.ReadAt}}.</p>
+{{end}}{{range .Groups}}<h2>{{.Name}}</h2>
+<table>
+<thead><tr>{{range $.Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.Address}}</td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.Size}}</td><td>{{.Unit}}</td><td>{{.Scale}}</td><td>{{.Access}}</td><td>{{.Description}}</td>{{if $.Live}}<td class="value">{{.Value}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}</body>
+</html>
+`))
+
+// RenderHTML renders the map as a standalone HTML page with one table per
+// block.
+func RenderHTML(m Map, opts DocOptions) ([]byte, error) {
+	live := opts.Values != nil
+	var buf bytes.Buffer
+	err := htmlDoc.Execute(&buf, map[string]interface{}{
+		"Title":   opts.Title,
+		"Live":    live,
+		"ReadAt":  opts.ReadAt.Format(time.RFC3339),
+		"Headers": docHeaders(live),
+		"Groups":  docGroups(m, opts.Values),
+	})
+	return buf.Bytes(), err
+}
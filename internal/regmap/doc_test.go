@@ -0,0 +1,76 @@
+package regmap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+var docMap = Map{Points: []Point{
+	{Name: "voltage", Kind: "input_registers", Address: 0, Type: config.DecoderUint16, Scale: 0.5, Unit: "V", Access: "r", Block: "Grid"},
+	{Name: "energy", Kind: "input_registers", Address: 2, Type: config.DecoderUint32, ByteOrder: config.ByteOrderCDAB, Unit: "kWh", Access: "r", Block: "Grid"},
+	{Name: "limit", Kind: "holding_registers", Address: 100, Type: config.DecoderInt16, Unit: "%", Access: "rw", Description: "Power limit | derating"},
+	{Name: "start", Kind: "coils", Address: 5, Type: config.DecoderPacked, Length: 1, Access: "rw"},
+}}
+
+func TestRenderMarkdown(t *testing.T) {
+	doc := string(RenderMarkdown(docMap, DocOptions{Title: "Inverter"}))
+	require.Contains(t, doc, "# Inverter\n\n## Grid\n\n| Address | Name | Type | Size | Unit | Scale | Access | Description |\n")
+	require.Contains(t, doc, "| 2 (0x0002) | energy | uint32 CDAB | 2 | kWh |  | R |  |\n")
+	require.Contains(t, doc, "## Holding registers\n")
+	require.Contains(t, doc, `| Power limit \| derating |`)
+	require.Contains(t, doc, "| 5 (0x0005) | start | packed | 1 bit |  |  | R/W |  |\n")
+	require.NotContains(t, doc, "Value")
+}
+
+func TestRenderHTMLWithValues(t *testing.T) {
+	readAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	doc, err := RenderHTML(docMap, DocOptions{Title: "Inverter <A>", Values: []string{"230.1 V", "", "50", "true"}, ReadAt: readAt})
+	require.NoError(t, err)
+	html := string(doc)
+	require.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	require.Contains(t, html, "<title>Inverter &lt;A&gt;</title>")
+	require.Contains(t, html, "Values read 2026-10-18T12:00:00Z.")
+	require.Contains(t, html, "<th>Value</th>")
+	require.Contains(t, html, `<td class="value">230.1 V</td>`)
+	require.Equal(t, 3, strings.Count(html, "<table>"))
+}
+
+func TestReadPlanCombinesNeighbours(t *testing.T) {
+	m := Map{Points: append([]Point{
+		{Kind: "input_registers", Address: 200, Type: config.DecoderUint16},
+		{Kind: "input_registers", Address: 12, Type: config.DecoderFloat32},
+	}, docMap.Points...)}
+	require.Equal(t, []core.ReadRequest{
+		{Kind: core.ReadInput, Address: 0, Quantity: 14},
+		{Kind: core.ReadInput, Address: 200, Quantity: 1},
+		{Kind: core.ReadHolding, Address: 100, Quantity: 1},
+		{Kind: core.ReadCoils, Address: 5, Quantity: 1},
+	}, readPlan(m))
+}
+
+func TestLiveValues(t *testing.T) {
+	read := func(_ context.Context, req core.ReadRequest) (core.ReadResult, error) {
+		result := core.ReadResult{Kind: req.Kind, Address: req.Address, Quantity: req.Quantity}
+		switch req.Kind {
+		case core.ReadInput:
+			result.RegValues = []uint16{461, 0, 0x86A0, 0x0001}
+		case core.ReadCoils:
+			result.BoolValues = []bool{true}
+		default:
+			return result, core.ErrOutOfRange
+		}
+		return result, nil
+	}
+	values, warnings, err := LiveValues(context.Background(), read, docMap)
+	require.NoError(t, err)
+	require.Equal(t, []string{"230.5 V", "100000 kWh", "", "1"}, values)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "holding_registers 100-100")
+}
@@ -0,0 +1,424 @@
+package regmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gomodmaster/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportFields are the point fields a vendor column can be mapped to.
+var ImportFields = []string{"address", "name", "type", "scale", "unit", "access", "kind", "order", "length", "block", "description"}
+
+// Address styles of a Mapping.
+const (
+	// AddressAuto treats 5- and 6-digit addresses starting with 0, 1, 3 or 4
+	// as Modicon references and everything else as plain addresses.
+	AddressAuto = "auto"
+	// AddressModicon requires Modicon references (40001, 300001, ...).
+	AddressModicon = "modicon"
+	// AddressPlain takes addresses as they are.
+	AddressPlain = "plain"
+)
+
+// Mapping describes the layout of a vendor register list. Columns maps point
+// fields to header names (case-insensitive) or to 1-based column numbers
+// written as "#N"; Types maps vendor type names to decoder types, optionally
+// followed by a byte order ("u32,CDAB").
+type Mapping struct {
+	Columns     map[string]string `json:"columns" yaml:"columns"`
+	Types       map[string]string `json:"types,omitempty" yaml:"types,omitempty"`
+	HeaderRow   int               `json:"headerRow,omitempty" yaml:"headerRow,omitempty"`
+	Delimiter   string            `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	Addresses   string            `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	DivideScale bool              `json:"divideScale,omitempty" yaml:"divideScale,omitempty"`
+}
+
+// LoadMapping reads a YAML (or JSON) mapping file.
+func LoadMapping(path string) (Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, err
+	}
+	var mapping Mapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return Mapping{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// SaveMapping writes a mapping file that LoadMapping reads back.
+func SaveMapping(path string, mapping Mapping) error {
+	data, err := yaml.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Table is a vendor CSV split into its header and data rows. FirstLine is
+// the file line of Rows[0].
+type Table struct {
+	Header    []string
+	Rows      [][]string
+	FirstLine int
+}
+
+// ReadTable parses a vendor CSV whose header is on line headerRow (1-based,
+// 0 meaning 1). An empty delimiter picks whichever of , ; or tab occurs most
+// often in the header line.
+func ReadTable(data []byte, headerRow int, delimiter string) (Table, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if headerRow < 1 {
+		headerRow = 1
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines) < headerRow {
+		return Table{}, fmt.Errorf("missing header row %d", headerRow)
+	}
+	comma, err := tableDelimiter(delimiter, lines[headerRow-1])
+	if err != nil {
+		return Table{}, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.Join(lines[headerRow-1:], nil)))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Table{}, err
+	}
+	if len(records) == 0 {
+		return Table{}, fmt.Errorf("missing header row %d", headerRow)
+	}
+	return Table{Header: records[0], Rows: records[1:], FirstLine: headerRow + 1}, nil
+}
+
+func tableDelimiter(delimiter string, header []byte) (rune, error) {
+	switch delimiter {
+	case "":
+		best, count := ',', bytes.Count(header, []byte(","))
+		for _, candidate := range []rune{';', '\t'} {
+			if n := bytes.Count(header, []byte(string(candidate))); n > count {
+				best, count = candidate, n
+			}
+		}
+		return best, nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+	if len([]rune(delimiter)) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character")
+	}
+	return []rune(delimiter)[0], nil
+}
+
+// importAliases are header names commonly used for each field, used to
+// preselect columns.
+var importAliases = map[string][]string{
+	"address":     {"address", "addr", "register", "reg", "register address", "modbus address", "start address", "offset"},
+	"name":        {"name", "tag", "parameter", "point", "signal", "variable", "label"},
+	"type":        {"type", "data type", "datatype", "format", "data format"},
+	"scale":       {"scale", "gain", "factor", "multiplier", "scaling", "resolution"},
+	"unit":        {"unit", "units", "uom", "engineering unit"},
+	"access":      {"access", "r/w", "rw", "mode", "read/write", "permission"},
+	"kind":        {"kind", "function", "function code", "fc", "table"},
+	"order":       {"order", "byte order", "byte_order", "endianness"},
+	"length":      {"length", "size", "words", "registers", "count", "quantity", "number of registers"},
+	"block":       {"block", "group", "section", "category"},
+	"description": {"description", "comment", "comments", "notes", "remark", "remarks"},
+}
+
+// GuessColumns preselects a column for every field whose usual header name
+// appears in header.
+func GuessColumns(header []string) map[string]string {
+	columns := map[string]string{}
+	used := map[int]bool{}
+	for _, field := range ImportFields {
+		for i, name := range header {
+			if !used[i] && slices.Contains(importAliases[field], strings.ToLower(strings.TrimSpace(name))) {
+				columns[field] = strings.TrimSpace(name)
+				used[i] = true
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// Import converts a vendor register list into a register map. Rows that do
+// not describe a point (section titles, unknown types, ...) are skipped and
+// reported as warnings. Modicon references are converted to the address
+// entered for --address with the given address base.
+func Import(data []byte, mapping Mapping, base config.AddressBase) (Map, []string, error) {
+	table, err := ReadTable(data, mapping.HeaderRow, mapping.Delimiter)
+	if err != nil {
+		return Map{}, nil, err
+	}
+	return ImportTable(table, mapping, base)
+}
+
+// ImportTable is Import for an already parsed table.
+func ImportTable(table Table, mapping Mapping, base config.AddressBase) (Map, []string, error) {
+	switch mapping.Addresses {
+	case "", AddressAuto, AddressModicon, AddressPlain:
+	default:
+		return Map{}, nil, fmt.Errorf("unknown address style %q (use auto, modicon or plain)", mapping.Addresses)
+	}
+	if mapping.Columns["address"] == "" {
+		return Map{}, nil, fmt.Errorf("no column is mapped to address")
+	}
+	columns := map[string]int{}
+	for field, column := range mapping.Columns {
+		if !slices.Contains(ImportFields, field) {
+			return Map{}, nil, fmt.Errorf("unknown field %q (use %s)", field, strings.Join(ImportFields, ", "))
+		}
+		if column == "" {
+			continue
+		}
+		index, err := columnIndex(table.Header, column)
+		if err != nil {
+			return Map{}, nil, fmt.Errorf("%s: %w", field, err)
+		}
+		columns[field] = index
+	}
+	types := map[string]string{}
+	for vendor, native := range mapping.Types {
+		types[strings.ToLower(strings.TrimSpace(vendor))] = native
+	}
+
+	m := Map{Points: []Point{}}
+	warnings := []string{}
+	for i, record := range table.Rows {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		point, err := importPoint(cell, types, mapping, base)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: skipped: %v", table.FirstLine+i, err))
+			continue
+		}
+		m.Points = append(m.Points, point)
+	}
+	return m, warnings, nil
+}
+
+func columnIndex(header []string, column string) (int, error) {
+	if number, ok := strings.CutPrefix(column, "#"); ok {
+		index, err := strconv.Atoi(number)
+		if err != nil || index < 1 || index > len(header) {
+			return 0, fmt.Errorf("column %s must be #1-#%d", column, len(header))
+		}
+		return index - 1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(column)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("column %q is not in the header", column)
+}
+
+func importPoint(cell func(string) string, types map[string]string, mapping Mapping, base config.AddressBase) (Point, error) {
+	raw := rawPoint{
+		Name:        cell("name"),
+		Kind:        vendorKind(cell("kind")),
+		Order:       cell("order"),
+		Length:      cell("length"),
+		Unit:        cell("unit"),
+		Access:      cell("access"),
+		Block:       cell("block"),
+		Description: cell("description"),
+	}
+	if cell("address") == "" {
+		return Point{}, fmt.Errorf("no address")
+	}
+	kind, address, err := NormalizeAddress(cell("address"), mapping.Addresses)
+	if err != nil {
+		return Point{}, err
+	}
+	if kind != "" {
+		if raw.Kind != "" {
+			if explicit, err := config.ParseReadKind(raw.Kind); err == nil && explicit != kind {
+				return Point{}, fmt.Errorf("address %s is %s but kind says %s", cell("address"), kind, explicit)
+			}
+		}
+		raw.Kind = kind
+		if base == config.AddressBaseOne {
+			address++
+		}
+	}
+	raw.Address = strconv.Itoa(address)
+	raw.Type = cell("type")
+	if native, ok := types[strings.ToLower(raw.Type)]; ok {
+		typ, order, _ := strings.Cut(native, ",")
+		raw.Type = strings.TrimSpace(typ)
+		if raw.Order == "" {
+			raw.Order = order
+		}
+	} else {
+		raw.Type = vendorType(raw.Type, raw.Kind)
+	}
+	if decoder, ok := config.DefaultDecoder(raw.Type); ok {
+		raw.Type = string(decoder.Type)
+	}
+	switch config.DecoderType(raw.Type) {
+	case config.DecoderString:
+	case config.DecoderPacked:
+		if raw.Length == "" {
+			raw.Length = "1"
+		}
+	default:
+		// Vendor size columns count registers of every type; only strings
+		// and packed bits keep a length.
+		raw.Length = ""
+	}
+	scale, err := vendorScale(cell("scale"), mapping.DivideScale)
+	if err != nil {
+		return Point{}, err
+	}
+	raw.Scale = scale
+	return raw.point()
+}
+
+// NormalizeAddress converts a Modicon reference (00001-09999 coils,
+// 10001-19999 discrete inputs, 30001-39999 input registers, 40001-49999
+// holding registers, or their 6-digit forms up to 465536) into its read kind
+// and zero-based address. Plain addresses come back with an empty kind.
+func NormalizeAddress(value, style string) (string, int, error) {
+	value = strings.TrimSpace(value)
+	plain := func() (string, int, error) {
+		address, err := parseAddress(value)
+		return "", int(address), err
+	}
+	if style == AddressPlain || strings.HasPrefix(strings.ToLower(value), "0x") {
+		if style == AddressModicon {
+			return "", 0, fmt.Errorf("address %q is not a Modicon reference", value)
+		}
+		return plain()
+	}
+	kind, offset, ok := modiconReference(value)
+	switch {
+	case ok:
+		return kind, offset, nil
+	case style == AddressModicon:
+		return "", 0, fmt.Errorf("address %q is not a Modicon reference", value)
+	default:
+		return plain()
+	}
+}
+
+func modiconReference(value string) (string, int, bool) {
+	if len(value) != 5 && len(value) != 6 {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return "", 0, false
+	}
+	kinds := map[byte]string{'0': "coils", '1': "discrete_inputs", '3': "input_registers", '4': "holding_registers"}
+	kind, ok := kinds[value[0]]
+	if !ok {
+		return "", 0, false
+	}
+	span := 10000
+	if len(value) == 6 {
+		span = 100000
+	}
+	reference := number % span
+	if reference < 1 || reference > 65536 {
+		return "", 0, false
+	}
+	return kind, reference - 1, true
+}
+
+// vendorKind reads table names such as "Holding Register" or "Coils".
+func vendorKind(value string) string {
+	lower := strings.ToLower(value)
+	for _, kind := range []string{"coil", "discrete", "input", "holding"} {
+		if strings.Contains(lower, kind) {
+			kind, _ := config.ParseReadKind(kind)
+			return kind
+		}
+	}
+	return value
+}
+
+// vendorTypes maps the type names found in vendor lists to decoder types.
+var vendorTypes = map[string]config.DecoderType{
+	"uint": config.DecoderUint16, "word": config.DecoderUint16, "unsigned": config.DecoderUint16,
+	"ushort": config.DecoderUint16, "unsignedshort": config.DecoderUint16, "u16": config.DecoderUint16,
+	"int": config.DecoderInt16, "short": config.DecoderInt16, "signed": config.DecoderInt16,
+	"sint16": config.DecoderInt16, "s16": config.DecoderInt16, "sunssf": config.DecoderInt16,
+	"dword": config.DecoderUint32, "ulong": config.DecoderUint32, "udint": config.DecoderUint32,
+	"unsignedlong": config.DecoderUint32, "acc32": config.DecoderUint32,
+	"long": config.DecoderInt32, "dint": config.DecoderInt32, "sint32": config.DecoderInt32, "s32": config.DecoderInt32,
+	"float": config.DecoderFloat32, "real": config.DecoderFloat32, "single": config.DecoderFloat32, "ieee754": config.DecoderFloat32,
+	"double": config.DecoderFloat64, "lreal": config.DecoderFloat64,
+	"ulint": config.DecoderUint64, "acc64": config.DecoderUint64, "lint": config.DecoderInt64,
+	"ascii": config.DecoderString, "char": config.DecoderString, "chars": config.DecoderString, "text": config.DecoderString,
+	"bool": config.DecoderPacked, "boolean": config.DecoderPacked, "bit": config.DecoderPacked, "coil": config.DecoderPacked,
+	"bitfield": config.DecoderBits, "bitfield16": config.DecoderBits, "bitmap": config.DecoderBits, "flags": config.DecoderBits,
+	"enum16": config.DecoderEnum, "bcd": config.DecoderBCD16,
+}
+
+// vendorType returns the decoder type for a vendor type name, defaulting to
+// uint16 for registers and a single packed bit for coils and discrete
+// inputs.
+func vendorType(name, kind string) string {
+	key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(name))
+	if key == "" {
+		if kind == "coils" || kind == "discrete_inputs" {
+			return string(config.DecoderPacked)
+		}
+		return string(config.DecoderUint16)
+	}
+	if typ, ok := vendorTypes[key]; ok {
+		return string(typ)
+	}
+	return key
+}
+
+// vendorScale reads gains written as 0.1, x10, /10 or 1/10. With divide set,
+// plain numbers are divisors.
+func vendorScale(value string, divide bool) (string, error) {
+	value = strings.ReplaceAll(strings.ToLower(value), " ", "")
+	if value == "" {
+		return "", nil
+	}
+	invert := divide
+	switch {
+	case strings.HasPrefix(value, "1/"):
+		value, invert = value[2:], true
+	case strings.HasPrefix(value, "/"):
+		value, invert = value[1:], true
+	case strings.HasPrefix(value, "x"), strings.HasPrefix(value, "*"):
+		value, invert = value[1:], false
+	}
+	scale, err := strconv.ParseFloat(value, 64)
+	if err != nil || scale == 0 {
+		return "", fmt.Errorf("invalid scale %q", value)
+	}
+	if invert {
+		scale = 1 / scale
+	}
+	if scale == 1 {
+		return "", nil
+	}
+	return strconv.FormatFloat(scale, 'g', -1, 64), nil
+}
@@ -0,0 +1,110 @@
+package regmap
+
+import (
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	cases := []struct {
+		value   string
+		style   string
+		kind    string
+		address int
+	}{
+		{"40001", AddressAuto, "holding_registers", 0},
+		{"30010", AddressAuto, "input_registers", 9},
+		{"10001", AddressAuto, "discrete_inputs", 0},
+		{"00017", AddressAuto, "coils", 16},
+		{"465536", AddressAuto, "holding_registers", 65535},
+		{"40000", AddressAuto, "", 40000},
+		{"123", AddressAuto, "", 123},
+		{"0x9C41", AddressAuto, "", 0x9C41},
+		{"40001", AddressPlain, "", 40001},
+		{"300001", AddressModicon, "input_registers", 0},
+	}
+	for _, tc := range cases {
+		kind, address, err := NormalizeAddress(tc.value, tc.style)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.kind, kind, tc.value)
+		require.Equal(t, tc.address, address, tc.value)
+	}
+
+	_, _, err := NormalizeAddress("123", AddressModicon)
+	require.Error(t, err)
+}
+
+func TestImport(t *testing.T) {
+	vendor := "Inverter register list rev 3\n" +
+		"Register;Parameter;Data Type;Gain;Unit;R/W;Words;Comment\n" +
+		"Measurements;;;;;;;\n" +
+		"30001;Grid voltage;U16;10;V;RO;1;Phase L1\n" +
+		"30003;Energy;U32 (CDAB);1000;kWh;R;2;\n" +
+		"40001;Serial number;STRING;;;R;8;\n" +
+		"40009;Power limit;S16;;%;R/W;1;\n" +
+		"00001;Remote start;BOOL;;;RW;;\n" +
+		"40010;Mode;BITFIELD32;;;RW;2;\n"
+	mapping := Mapping{
+		Columns: map[string]string{
+			"address": "register", "name": "Parameter", "type": "Data Type", "scale": "Gain",
+			"unit": "Unit", "access": "R/W", "length": "Words", "description": "#8",
+		},
+		Types:       map[string]string{"U32 (CDAB)": "u32,CDAB", "S16": "int16"},
+		HeaderRow:   2,
+		DivideScale: true,
+	}
+	m, warnings, err := Import([]byte(vendor), mapping, config.AddressBaseZero)
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 3: skipped: address \"measurements\" must be 0-65535", "line 9: skipped: unknown type \"bitfield32\""}, warnings)
+	require.Equal(t, []Point{
+		{Name: "Grid voltage", Kind: "input_registers", Address: 0, Type: config.DecoderUint16, Scale: 0.1, Unit: "V", Access: "r", Description: "Phase L1"},
+		{Name: "Energy", Kind: "input_registers", Address: 2, Type: config.DecoderUint32, ByteOrder: config.ByteOrderCDAB, Scale: 0.001, Unit: "kWh", Access: "r"},
+		{Name: "Serial number", Kind: "holding_registers", Address: 0, Type: config.DecoderString, Length: 8, Access: "r"},
+		{Name: "Power limit", Kind: "holding_registers", Address: 8, Type: config.DecoderInt16, Unit: "%", Access: "rw"},
+		{Name: "Remote start", Kind: "coils", Address: 0, Type: config.DecoderPacked, Length: 1, Access: "rw"},
+	}, m.Points)
+
+	m, _, err = Import([]byte(vendor), mapping, config.AddressBaseOne)
+	require.NoError(t, err)
+	require.Equal(t, uint16(1), m.Points[0].Address)
+}
+
+func TestImportMappingErrors(t *testing.T) {
+	data := []byte("Addr,Name\n1,x\n")
+	_, _, err := Import(data, Mapping{Columns: map[string]string{"name": "Name"}}, config.AddressBaseZero)
+	require.ErrorContains(t, err, "no column is mapped to address")
+
+	_, _, err = Import(data, Mapping{Columns: map[string]string{"address": "Register"}}, config.AddressBaseZero)
+	require.ErrorContains(t, err, `column "Register" is not in the header`)
+
+	_, _, err = Import(data, Mapping{Columns: map[string]string{"address": "Addr", "gain": "Name"}}, config.AddressBaseZero)
+	require.ErrorContains(t, err, `unknown field "gain"`)
+}
+
+func TestGuessColumns(t *testing.T) {
+	columns := GuessColumns([]string{"Register", "Name", "Data Type", "Gain", "Units", "Notes", "Extra"})
+	require.Equal(t, map[string]string{
+		"address": "Register", "name": "Name", "type": "Data Type", "scale": "Gain", "unit": "Units", "description": "Notes",
+	}, columns)
+}
+
+func TestImportedMapRoundTrips(t *testing.T) {
+	m := Map{Points: []Point{
+		{Name: "energy", Kind: "input_registers", Address: 2, Type: config.DecoderUint32, ByteOrder: config.ByteOrderCDAB, Scale: 0.001, Unit: "kWh", Access: "r"},
+		{Name: "start", Kind: "coils", Address: 0, Type: config.DecoderPacked, Length: 1, Access: "rw", Description: "Remote, start"},
+	}}
+	data, err := FormatYAML(m)
+	require.NoError(t, err)
+	parsed, err := ParseYAML(data)
+	require.NoError(t, err)
+	require.Equal(t, m, parsed)
+
+	data, err = FormatCSV(m)
+	require.NoError(t, err)
+	parsed, err = ParseCSV(data)
+	require.NoError(t, err)
+	require.Equal(t, m, parsed)
+}
@@ -0,0 +1,95 @@
+package regmap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"gomodmaster/internal/core"
+)
+
+const (
+	maxRegisterRead = 125
+	maxBitRead      = 2000
+	// maxReadGap is the largest run of unmapped addresses read through to
+	// combine neighbouring points into one request.
+	maxReadGap = 8
+)
+
+// ReadFunc performs a read like core.Service.Read.
+type ReadFunc func(ctx context.Context, req core.ReadRequest) (core.ReadResult, error)
+
+// LiveValues reads every point, combining neighbours of the same kind into as
+// few requests as possible, and returns the decoded values formatted as text
+// in point order. Points of failed reads are left empty and the failures are
+// returned as warnings.
+func LiveValues(ctx context.Context, read ReadFunc, m Map) ([]string, []string, error) {
+	values := make([]string, len(m.Points))
+	warnings := []string{}
+	assignments := m.Assignments()
+	for _, req := range readPlan(m) {
+		result, err := read(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil || result.ErrorKind == "connection" {
+				return nil, nil, err
+			}
+			warnings = append(warnings, fmt.Sprintf("%s %d-%d: %v", req.Kind, req.Address, int(req.Address)+int(req.Quantity)-1, err))
+			continue
+		}
+		kindAssignments := core.AssignmentsFor(req.Kind, assignments)
+		decoded := core.DecodeAssignments(req.Address, result.RegValues, kindAssignments)
+		if len(result.BoolValues) > 0 {
+			decoded = core.DecodeBoolAssignments(req.Address, result.BoolValues, kindAssignments)
+		}
+		for _, value := range decoded {
+			for i, point := range m.Points {
+				if point.Kind != string(req.Kind) || point.Address != value.Address || values[i] != "" {
+					continue
+				}
+				if value.Error != "" {
+					values[i] = "error: " + value.Error
+				} else {
+					values[i] = fmt.Sprint(value.Value)
+				}
+			}
+		}
+	}
+	return values, warnings, nil
+}
+
+// readPlan groups the points into read requests per kind.
+func readPlan(m Map) []core.ReadRequest {
+	type span struct{ start, end int }
+	spans := map[string][]span{}
+	kinds := []string{}
+	for _, assignment := range m.Assignments() {
+		if _, ok := spans[assignment.Kind]; !ok {
+			kinds = append(kinds, assignment.Kind)
+		}
+		start := int(assignment.Address)
+		spans[assignment.Kind] = append(spans[assignment.Kind], span{start, min(start+core.RegisterSpan(assignment.Decoder), 0x10000)})
+	}
+	requests := []core.ReadRequest{}
+	for _, kind := range kinds {
+		limit := maxRegisterRead
+		if kind == string(core.ReadCoils) || kind == string(core.ReadDiscreteInputs) {
+			limit = maxBitRead
+		}
+		points := spans[kind]
+		slices.SortFunc(points, func(a, b span) int { return a.start - b.start })
+		current := points[0]
+		flush := func() {
+			requests = append(requests, core.ReadRequest{Kind: core.ReadKind(kind), Address: uint16(current.start), Quantity: uint16(current.end - current.start)})
+		}
+		for _, point := range points[1:] {
+			if point.start <= current.end+maxReadGap && max(point.end, current.end)-current.start <= limit {
+				current.end = max(current.end, point.end)
+				continue
+			}
+			flush()
+			current = point
+		}
+		flush()
+	}
+	return requests
+}
@@ -0,0 +1,213 @@
+// Package regmap loads register maps: per-device lists of named points with
+// their read kind, address, data type, byte order, scaling and unit, kept as
+// YAML or as CSV exported from a spreadsheet.
+package regmap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gomodmaster/internal/config"
+)
+
+// Point is one named value of a register map. Address is the address as
+// entered for --address; Length is the register count of string points and
+// the bit count of packed ones. Block optionally groups points in generated
+// documentation.
+type Point struct {
+	Name        string             `json:"name" yaml:"name"`
+	Kind        string             `json:"kind" yaml:"kind"`
+	Address     uint16             `json:"address" yaml:"address"`
+	Type        config.DecoderType `json:"type" yaml:"type"`
+	ByteOrder   config.ByteOrder   `json:"order,omitempty" yaml:"order,omitempty"`
+	Length      int                `json:"length,omitempty" yaml:"length,omitempty"`
+	Scale       float64            `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Unit        string             `json:"unit,omitempty" yaml:"unit,omitempty"`
+	Access      string             `json:"access,omitempty" yaml:"access,omitempty"`
+	Block       string             `json:"block,omitempty" yaml:"block,omitempty"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Map struct {
+	Points []Point `json:"points" yaml:"points"`
+}
+
+// Load reads a register map, choosing the format from the file extension
+// (.yaml, .yml or .csv).
+func Load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Map{}, err
+	}
+	var m Map
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		m, err = ParseYAML(data)
+	case ".csv":
+		m, err = ParseCSV(data)
+	default:
+		return Map{}, fmt.Errorf("%s: unsupported register map format (use .yaml or .csv)", path)
+	}
+	if err != nil {
+		return Map{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Save writes a register map in the format chosen by the file extension.
+func Save(path string, m Map) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = FormatYAML(m)
+	case ".csv":
+		data, err = FormatCSV(m)
+	default:
+		return fmt.Errorf("%s: unsupported register map format (use .yaml or .csv)", path)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Assignments turns the points into mapped decoder assignments.
+func (m Map) Assignments() []config.Assignment {
+	out := make([]config.Assignment, 0, len(m.Points))
+	for _, point := range m.Points {
+		decoder, _ := config.DefaultDecoder(string(point.Type))
+		decoder.Enabled = true
+		if point.ByteOrder != "" {
+			decoder.SetByteOrder(point.ByteOrder)
+		}
+		if point.Length > 0 && point.Type == config.DecoderPacked {
+			decoder.Bits = point.Length
+		} else if point.Length > 0 {
+			decoder.Registers = point.Length
+		}
+		decoder.Scale = point.Scale
+		decoder.Unit = point.Unit
+		out = append(out, config.Assignment{
+			Address:     point.Address,
+			Name:        point.Name,
+			Kind:        point.Kind,
+			Description: point.Description,
+			Mapped:      true,
+			Decoder:     decoder,
+		})
+	}
+	return out
+}
+
+// rawPoint holds the fields of a point as written in the file.
+type rawPoint struct {
+	Name        string `yaml:"name"`
+	Kind        string `yaml:"kind"`
+	Address     string `yaml:"address"`
+	Type        string `yaml:"type"`
+	Order       string `yaml:"order"`
+	Length      string `yaml:"length"`
+	Scale       string `yaml:"scale"`
+	Unit        string `yaml:"unit"`
+	Access      string `yaml:"access"`
+	Block       string `yaml:"block"`
+	Description string `yaml:"description"`
+}
+
+func (raw rawPoint) point() (Point, error) {
+	point := Point{
+		Name:        strings.TrimSpace(raw.Name),
+		Unit:        strings.TrimSpace(raw.Unit),
+		Block:       strings.TrimSpace(raw.Block),
+		Description: strings.TrimSpace(raw.Description),
+	}
+	access, err := parseAccess(raw.Access)
+	if err != nil {
+		return Point{}, err
+	}
+	point.Access = access
+	if raw.Kind == "" {
+		point.Kind = "holding_registers"
+	} else {
+		kind, err := config.ParseReadKind(raw.Kind)
+		if err != nil {
+			return Point{}, err
+		}
+		point.Kind = kind
+	}
+	address, err := parseAddress(raw.Address)
+	if err != nil {
+		return Point{}, err
+	}
+	point.Address = address
+	decoder, ok := config.DefaultDecoder(raw.Type)
+	if !ok {
+		return Point{}, fmt.Errorf("unknown type %q", raw.Type)
+	}
+	point.Type = decoder.Type
+	if strings.TrimSpace(raw.Order) != "" {
+		order, ok := config.ParseByteOrder(strings.TrimSpace(raw.Order))
+		if !ok {
+			return Point{}, fmt.Errorf("unknown byte order %q (use ABCD, CDAB, BADC or DCBA)", raw.Order)
+		}
+		point.ByteOrder = order
+	}
+	if strings.TrimSpace(raw.Length) != "" {
+		length, err := strconv.Atoi(strings.TrimSpace(raw.Length))
+		limit := 125
+		if point.Type == config.DecoderPacked {
+			limit = 64
+		}
+		if err != nil || length < 1 || length > limit {
+			return Point{}, fmt.Errorf("length must be 1-%d", limit)
+		}
+		point.Length = length
+	}
+	if strings.TrimSpace(raw.Scale) != "" {
+		scale, err := strconv.ParseFloat(strings.TrimSpace(raw.Scale), 64)
+		if err != nil || scale == 0 {
+			return Point{}, fmt.Errorf("invalid scale %q", raw.Scale)
+		}
+		point.Scale = scale
+	}
+	return point, nil
+}
+
+// parseAccess reduces the usual spellings (R, RO, R/W, read-write, WO, ...)
+// to r, rw or w.
+func parseAccess(value string) (string, error) {
+	switch strings.NewReplacer("/", "", "-", "", " ", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(value))) {
+	case "":
+		return "", nil
+	case "r", "ro", "read", "readonly":
+		return "r", nil
+	case "rw", "readwrite", "wr":
+		return "rw", nil
+	case "w", "wo", "write", "writeonly":
+		return "w", nil
+	default:
+		return "", fmt.Errorf("unknown access %q (use r, rw or w)", value)
+	}
+}
+
+func parseAddress(value string) (uint16, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, fmt.Errorf("address is required")
+	}
+	digits, base := value, 10
+	if hex, ok := strings.CutPrefix(value, "0x"); ok {
+		digits, base = hex, 16
+	}
+	address, err := strconv.ParseUint(digits, base, 16)
+	if err != nil {
+		return 0, fmt.Errorf("address %q must be 0-65535", value)
+	}
+	return uint16(address), nil
+}
@@ -0,0 +1,86 @@
+package regmap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomodmaster/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseYAML(t *testing.T) {
+	m, err := ParseYAML([]byte(`
+points:
+  - name: grid_voltage
+    kind: input
+    address: 0x0010
+    type: uint16
+    scale: 0.1
+    unit: V
+    description: Grid voltage L1
+  - name: energy
+    address: 200
+    type: f32
+    order: CDAB
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Point{
+		{Name: "grid_voltage", Kind: "input_registers", Address: 0x10, Type: config.DecoderUint16, Scale: 0.1, Unit: "V", Description: "Grid voltage L1"},
+		{Name: "energy", Kind: "holding_registers", Address: 200, Type: config.DecoderFloat32, ByteOrder: config.ByteOrderCDAB},
+	}, m.Points)
+}
+
+func TestParseCSV(t *testing.T) {
+	m, err := ParseCSV([]byte("\xef\xbb\xbfName,Address,Type,Byte_Order,Scale,Unit,Notes\n" +
+		"temp,100,int16,,0.1,°C,ignored\n" +
+		",,,,,,\n" +
+		"serial,0x20,string,,,,\n"))
+	require.NoError(t, err)
+	require.Len(t, m.Points, 2)
+	require.Equal(t, Point{Name: "temp", Kind: "holding_registers", Address: 100, Type: config.DecoderInt16, Scale: 0.1, Unit: "°C"}, m.Points[0])
+	require.Equal(t, config.DecoderString, m.Points[1].Type)
+}
+
+func TestParseErrorsNameTheLine(t *testing.T) {
+	_, err := ParseCSV([]byte("name,address,type\nok,1,uint16\nbad,70000,uint16\n"))
+	require.ErrorContains(t, err, "line 3")
+
+	_, err = ParseCSV([]byte("name,type\nx,uint16\n"))
+	require.ErrorContains(t, err, `missing "address" column`)
+
+	_, err = ParseYAML([]byte("points:\n  - name: x\n    address: 1\n    type: float128\n"))
+	require.ErrorContains(t, err, "point 1 (x)")
+}
+
+func TestAssignments(t *testing.T) {
+	m := Map{Points: []Point{
+		{Name: "energy", Kind: "input_registers", Address: 200, Type: config.DecoderFloat32, ByteOrder: config.ByteOrderDCBA, Scale: 0.001, Unit: "kWh"},
+		{Name: "serial", Kind: "holding_registers", Address: 10, Type: config.DecoderString, Length: 4},
+	}}
+	assignments := m.Assignments()
+	require.Len(t, assignments, 2)
+
+	energy := assignments[0]
+	require.True(t, energy.Mapped)
+	require.Equal(t, "input_registers", energy.Kind)
+	require.True(t, energy.Decoder.Enabled)
+	require.Equal(t, config.ByteOrderDCBA, energy.Decoder.ByteOrder())
+	require.Equal(t, 0.001, energy.Decoder.Scale)
+	require.Equal(t, "kWh", energy.Decoder.Unit)
+
+	require.Equal(t, 4, assignments[1].Decoder.Registers)
+}
+
+func TestLoadChoosesFormatByExtension(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "device.csv")
+	require.NoError(t, os.WriteFile(path, []byte("address,type\n1,uint16\n"), 0o644))
+	m, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, m.Points, 1)
+
+	_, err = Load(filepath.Join(dir, "device.txt"))
+	require.Error(t, err)
+}
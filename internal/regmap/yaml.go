@@ -0,0 +1,46 @@
+package regmap
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYAML reads a map of the form
+//
+//	points:
+//	  - name: grid_voltage
+//	    kind: input
+//	    address: 0x0010
+//	    type: uint16
+//	    scale: 0.1
+//	    unit: V
+func ParseYAML(data []byte) (Map, error) {
+	var doc struct {
+		Points []rawPoint `yaml:"points"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Map{}, err
+	}
+	m := Map{Points: make([]Point, 0, len(doc.Points))}
+	for i, raw := range doc.Points {
+		point, err := raw.point()
+		if err != nil {
+			return Map{}, fmt.Errorf("point %d (%s): %w", i+1, raw.Name, err)
+		}
+		m.Points = append(m.Points, point)
+	}
+	return m, nil
+}
+
+// FormatYAML writes a map in the form ParseYAML reads.
+func FormatYAML(m Map) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
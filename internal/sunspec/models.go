@@ -0,0 +1,185 @@
+package sunspec
+
+// pointType is the SunSpec type of a point.
+type pointType string
+
+const (
+	typeUint16     pointType = "uint16"
+	typeInt16      pointType = "int16"
+	typeUint32     pointType = "uint32"
+	typeAcc32      pointType = "acc32"
+	typeEnum16     pointType = "enum16"
+	typeBitfield32 pointType = "bitfield32"
+	typeSunSSF     pointType = "sunssf"
+	typeString     pointType = "string"
+)
+
+// pointDef describes a point at Offset registers after the model header.
+// SF names the scale factor point of the same model block.
+type pointDef struct {
+	Name   string
+	Offset int
+	Type   pointType
+	Size   int
+	SF     string
+	Unit   string
+	Labels map[uint16]string
+}
+
+type modelDef struct {
+	Name   string
+	Points []pointDef
+	// Repeating blocks follow the fixed points, Repeat registers each.
+	Repeat       int
+	RepeatPoints []pointDef
+}
+
+var operatingStates = map[uint16]string{
+	1: "OFF", 2: "SLEEPING", 3: "STARTING", 4: "MPPT", 5: "THROTTLED",
+	6: "SHUTTING_DOWN", 7: "FAULT", 8: "STANDBY", 9: "TEST",
+}
+
+var commonModel = modelDef{
+	Name: "Common",
+	Points: []pointDef{
+		{Name: "Mn", Offset: 0, Type: typeString, Size: 16},
+		{Name: "Md", Offset: 16, Type: typeString, Size: 16},
+		{Name: "Opt", Offset: 32, Type: typeString, Size: 8},
+		{Name: "Vr", Offset: 40, Type: typeString, Size: 8},
+		{Name: "SN", Offset: 48, Type: typeString, Size: 16},
+		{Name: "DA", Offset: 64, Type: typeUint16},
+	},
+}
+
+// inverterPoints are shared by the integer + scale factor inverter models
+// 101 (single phase), 102 (split phase) and 103 (three phase).
+var inverterPoints = []pointDef{
+	{Name: "A", Offset: 0, Type: typeUint16, SF: "A_SF", Unit: "A"},
+	{Name: "AphA", Offset: 1, Type: typeUint16, SF: "A_SF", Unit: "A"},
+	{Name: "AphB", Offset: 2, Type: typeUint16, SF: "A_SF", Unit: "A"},
+	{Name: "AphC", Offset: 3, Type: typeUint16, SF: "A_SF", Unit: "A"},
+	{Name: "A_SF", Offset: 4, Type: typeSunSSF},
+	{Name: "PPVphAB", Offset: 5, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "PPVphBC", Offset: 6, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "PPVphCA", Offset: 7, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphA", Offset: 8, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphB", Offset: 9, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphC", Offset: 10, Type: typeUint16, SF: "V_SF", Unit: "V"},
+	{Name: "V_SF", Offset: 11, Type: typeSunSSF},
+	{Name: "W", Offset: 12, Type: typeInt16, SF: "W_SF", Unit: "W"},
+	{Name: "W_SF", Offset: 13, Type: typeSunSSF},
+	{Name: "Hz", Offset: 14, Type: typeUint16, SF: "Hz_SF", Unit: "Hz"},
+	{Name: "Hz_SF", Offset: 15, Type: typeSunSSF},
+	{Name: "VA", Offset: 16, Type: typeInt16, SF: "VA_SF", Unit: "VA"},
+	{Name: "VA_SF", Offset: 17, Type: typeSunSSF},
+	{Name: "VAr", Offset: 18, Type: typeInt16, SF: "VAr_SF", Unit: "var"},
+	{Name: "VAr_SF", Offset: 19, Type: typeSunSSF},
+	{Name: "PF", Offset: 20, Type: typeInt16, SF: "PF_SF", Unit: "%"},
+	{Name: "PF_SF", Offset: 21, Type: typeSunSSF},
+	{Name: "WH", Offset: 22, Type: typeAcc32, SF: "WH_SF", Unit: "Wh"},
+	{Name: "WH_SF", Offset: 24, Type: typeSunSSF},
+	{Name: "DCA", Offset: 25, Type: typeUint16, SF: "DCA_SF", Unit: "A"},
+	{Name: "DCA_SF", Offset: 26, Type: typeSunSSF},
+	{Name: "DCV", Offset: 27, Type: typeUint16, SF: "DCV_SF", Unit: "V"},
+	{Name: "DCV_SF", Offset: 28, Type: typeSunSSF},
+	{Name: "DCW", Offset: 29, Type: typeInt16, SF: "DCW_SF", Unit: "W"},
+	{Name: "DCW_SF", Offset: 30, Type: typeSunSSF},
+	{Name: "TmpCab", Offset: 31, Type: typeInt16, SF: "Tmp_SF", Unit: "°C"},
+	{Name: "TmpSnk", Offset: 32, Type: typeInt16, SF: "Tmp_SF", Unit: "°C"},
+	{Name: "TmpTrns", Offset: 33, Type: typeInt16, SF: "Tmp_SF", Unit: "°C"},
+	{Name: "TmpOt", Offset: 34, Type: typeInt16, SF: "Tmp_SF", Unit: "°C"},
+	{Name: "Tmp_SF", Offset: 35, Type: typeSunSSF},
+	{Name: "St", Offset: 36, Type: typeEnum16, Labels: operatingStates},
+	{Name: "StVnd", Offset: 37, Type: typeEnum16},
+	{Name: "Evt1", Offset: 38, Type: typeBitfield32},
+	{Name: "Evt2", Offset: 40, Type: typeBitfield32},
+	{Name: "EvtVnd1", Offset: 42, Type: typeBitfield32},
+	{Name: "EvtVnd2", Offset: 44, Type: typeBitfield32},
+	{Name: "EvtVnd3", Offset: 46, Type: typeBitfield32},
+	{Name: "EvtVnd4", Offset: 48, Type: typeBitfield32},
+}
+
+// meterPoints are shared by the integer + scale factor meter models 201
+// (single phase), 202 (split phase), 203 (wye) and 204 (delta).
+var meterPoints = []pointDef{
+	{Name: "A", Offset: 0, Type: typeInt16, SF: "A_SF", Unit: "A"},
+	{Name: "AphA", Offset: 1, Type: typeInt16, SF: "A_SF", Unit: "A"},
+	{Name: "AphB", Offset: 2, Type: typeInt16, SF: "A_SF", Unit: "A"},
+	{Name: "AphC", Offset: 3, Type: typeInt16, SF: "A_SF", Unit: "A"},
+	{Name: "A_SF", Offset: 4, Type: typeSunSSF},
+	{Name: "PhV", Offset: 5, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphA", Offset: 6, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphB", Offset: 7, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PhVphC", Offset: 8, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PPV", Offset: 9, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PPVphAB", Offset: 10, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PPVphBC", Offset: 11, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "PPVphCA", Offset: 12, Type: typeInt16, SF: "V_SF", Unit: "V"},
+	{Name: "V_SF", Offset: 13, Type: typeSunSSF},
+	{Name: "Hz", Offset: 14, Type: typeInt16, SF: "Hz_SF", Unit: "Hz"},
+	{Name: "Hz_SF", Offset: 15, Type: typeSunSSF},
+	{Name: "W", Offset: 16, Type: typeInt16, SF: "W_SF", Unit: "W"},
+	{Name: "WphA", Offset: 17, Type: typeInt16, SF: "W_SF", Unit: "W"},
+	{Name: "WphB", Offset: 18, Type: typeInt16, SF: "W_SF", Unit: "W"},
+	{Name: "WphC", Offset: 19, Type: typeInt16, SF: "W_SF", Unit: "W"},
+	{Name: "W_SF", Offset: 20, Type: typeSunSSF},
+	{Name: "VA", Offset: 21, Type: typeInt16, SF: "VA_SF", Unit: "VA"},
+	{Name: "VA_SF", Offset: 25, Type: typeSunSSF},
+	{Name: "VAR", Offset: 26, Type: typeInt16, SF: "VAR_SF", Unit: "var"},
+	{Name: "VAR_SF", Offset: 30, Type: typeSunSSF},
+	{Name: "PF", Offset: 31, Type: typeInt16, SF: "PF_SF", Unit: "%"},
+	{Name: "PF_SF", Offset: 35, Type: typeSunSSF},
+	{Name: "TotWhExp", Offset: 36, Type: typeAcc32, SF: "TotWh_SF", Unit: "Wh"},
+	{Name: "TotWhImp", Offset: 44, Type: typeAcc32, SF: "TotWh_SF", Unit: "Wh"},
+	{Name: "TotWh_SF", Offset: 52, Type: typeSunSSF},
+	{Name: "TotVAhExp", Offset: 53, Type: typeAcc32, SF: "TotVAh_SF", Unit: "VAh"},
+	{Name: "TotVAhImp", Offset: 61, Type: typeAcc32, SF: "TotVAh_SF", Unit: "VAh"},
+	{Name: "TotVAh_SF", Offset: 69, Type: typeSunSSF},
+	{Name: "TotVArhImpQ1", Offset: 70, Type: typeAcc32, SF: "TotVArh_SF", Unit: "varh"},
+	{Name: "TotVArhImpQ2", Offset: 78, Type: typeAcc32, SF: "TotVArh_SF", Unit: "varh"},
+	{Name: "TotVArhExpQ3", Offset: 86, Type: typeAcc32, SF: "TotVArh_SF", Unit: "varh"},
+	{Name: "TotVArhExpQ4", Offset: 94, Type: typeAcc32, SF: "TotVArh_SF", Unit: "varh"},
+	{Name: "TotVArh_SF", Offset: 102, Type: typeSunSSF},
+	{Name: "Evt", Offset: 103, Type: typeBitfield32},
+}
+
+// mpptModel is model 160: scale factors shared by every module, then one
+// 20-register block per module.
+var mpptModel = modelDef{
+	Name: "Multiple MPPT Inverter Extension",
+	Points: []pointDef{
+		{Name: "DCA_SF", Offset: 0, Type: typeSunSSF},
+		{Name: "DCV_SF", Offset: 1, Type: typeSunSSF},
+		{Name: "DCW_SF", Offset: 2, Type: typeSunSSF},
+		{Name: "DCWH_SF", Offset: 3, Type: typeSunSSF},
+		{Name: "Evt", Offset: 4, Type: typeBitfield32},
+		{Name: "N", Offset: 6, Type: typeUint16},
+		{Name: "TmsPer", Offset: 7, Type: typeUint16},
+	},
+	Repeat: 20,
+	RepeatPoints: []pointDef{
+		{Name: "ID", Offset: 0, Type: typeUint16},
+		{Name: "IDStr", Offset: 1, Type: typeString, Size: 8},
+		{Name: "DCA", Offset: 9, Type: typeUint16, SF: "DCA_SF", Unit: "A"},
+		{Name: "DCV", Offset: 10, Type: typeUint16, SF: "DCV_SF", Unit: "V"},
+		{Name: "DCW", Offset: 11, Type: typeUint16, SF: "DCW_SF", Unit: "W"},
+		{Name: "DCWH", Offset: 12, Type: typeAcc32, SF: "DCWH_SF", Unit: "Wh"},
+		{Name: "Tms", Offset: 14, Type: typeUint32, Unit: "s"},
+		{Name: "Tmp", Offset: 16, Type: typeInt16, Unit: "°C"},
+		{Name: "DCSt", Offset: 17, Type: typeEnum16, Labels: operatingStates},
+		{Name: "DCEvt", Offset: 18, Type: typeBitfield32},
+	},
+}
+
+// models are the models Discover decodes; others are listed by ID only.
+var models = map[uint16]modelDef{
+	1:   commonModel,
+	101: {Name: "Inverter (Single Phase)", Points: inverterPoints},
+	102: {Name: "Inverter (Split Phase)", Points: inverterPoints},
+	103: {Name: "Inverter (Three Phase)", Points: inverterPoints},
+	160: mpptModel,
+	201: {Name: "Meter (Single Phase)", Points: meterPoints},
+	202: {Name: "Meter (Split Single Phase)", Points: meterPoints},
+	203: {Name: "Meter (Wye-Connect Three Phase)", Points: meterPoints},
+	204: {Name: "Meter (Delta-Connect Three Phase)", Points: meterPoints},
+}
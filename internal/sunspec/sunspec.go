@@ -0,0 +1,270 @@
+// Package sunspec locates the SunSpec model chain of a device and decodes
+// the common, inverter (101-103), MPPT (160) and meter (201-204) models.
+package sunspec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gomodmaster/internal/core"
+)
+
+// BaseAddresses are the protocol addresses probed for the "SunS" marker.
+var BaseAddresses = []uint16{40000, 50000, 0}
+
+var ErrNotFound = errors.New(`SunSpec "SunS" marker not found at 40000, 50000 or 0`)
+
+const (
+	markerHigh = 0x5375 // "Su"
+	markerLow  = 0x6E53 // "nS"
+	endModel   = 0xFFFF
+	maxModels  = 64
+	maxRead    = 125
+)
+
+// ReadFunc reads holding registers at a protocol address.
+type ReadFunc func(ctx context.Context, address, quantity uint16) ([]uint16, error)
+
+// ServiceReader reads through the service without replacing its last result.
+func ServiceReader(service *core.Service) ReadFunc {
+	return func(ctx context.Context, address, quantity uint16) ([]uint16, error) {
+		result, err := service.ReadRaw(ctx, core.ReadRequest{Kind: core.ReadHolding, Address: address, Quantity: quantity})
+		if err != nil {
+			return nil, err
+		}
+		return result.RegValues, nil
+	}
+}
+
+type Device struct {
+	BaseAddress uint16  `json:"baseAddress"`
+	Models      []Model `json:"models"`
+}
+
+// Model is one block of the chain. Address is the protocol address of its ID
+// register; Points is empty for models this package doesn't know.
+type Model struct {
+	ID      uint16  `json:"id"`
+	Name    string  `json:"name,omitempty"`
+	Address uint16  `json:"address"`
+	Length  uint16  `json:"length"`
+	Points  []Point `json:"points,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Point is a decoded value. Value is nil for points the device marks as not
+// implemented; numbers with a scale factor are already scaled.
+type Point struct {
+	Name    string      `json:"name"`
+	Address uint16      `json:"address"`
+	Value   interface{} `json:"value"`
+	Text    string      `json:"text"`
+	Unit    string      `json:"unit,omitempty"`
+}
+
+// Discover finds the "SunS" marker, walks the model chain to its end marker
+// and decodes the known models.
+func Discover(ctx context.Context, read ReadFunc) (Device, error) {
+	for _, base := range BaseAddresses {
+		regs, err := read(ctx, base, 2)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, core.ErrNotConnected) {
+				return Device{}, err
+			}
+			continue
+		}
+		if len(regs) == 2 && regs[0] == markerHigh && regs[1] == markerLow {
+			return walk(ctx, read, base)
+		}
+	}
+	return Device{}, ErrNotFound
+}
+
+func walk(ctx context.Context, read ReadFunc, base uint16) (Device, error) {
+	device := Device{BaseAddress: base, Models: []Model{}}
+	address := int(base) + 2
+	for len(device.Models) < maxModels && address+2 <= 0x10000 {
+		header, err := read(ctx, uint16(address), 2)
+		if err != nil {
+			return device, fmt.Errorf("model header at %d: %w", address, err)
+		}
+		if len(header) < 2 || header[0] == endModel {
+			return device, nil
+		}
+		model := Model{ID: header[0], Address: uint16(address), Length: header[1]}
+		if def, ok := models[model.ID]; ok {
+			model.Name = def.Name
+			regs, err := readBlock(ctx, read, address+2, int(model.Length))
+			if err != nil {
+				model.Error = err.Error()
+			} else {
+				model.Points = def.decode(uint16(address+2), regs)
+			}
+		}
+		device.Models = append(device.Models, model)
+		address += 2 + int(model.Length)
+	}
+	return device, nil
+}
+
+func readBlock(ctx context.Context, read ReadFunc, address, length int) ([]uint16, error) {
+	if address+length > 0x10000 {
+		return nil, fmt.Errorf("model runs past address 65535")
+	}
+	regs := make([]uint16, 0, length)
+	for len(regs) < length {
+		chunk := min(length-len(regs), maxRead)
+		values, err := read(ctx, uint16(address+len(regs)), uint16(chunk))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) < chunk {
+			return nil, fmt.Errorf("short read at %d", address+len(regs))
+		}
+		regs = append(regs, values[:chunk]...)
+	}
+	return regs, nil
+}
+
+// decode decodes the fixed points and then every complete repeating block.
+func (def modelDef) decode(address uint16, regs []uint16) []Point {
+	points := []Point{}
+	factors := scaleFactors(def.Points, regs)
+	for _, point := range def.Points {
+		if decoded, ok := point.decode(address, regs, factors); ok {
+			points = append(points, decoded)
+		}
+	}
+	fixed := 0
+	for _, point := range def.Points {
+		fixed = max(fixed, point.Offset+point.size())
+	}
+	for block := 0; def.Repeat > 0 && fixed+(block+1)*def.Repeat <= len(regs); block++ {
+		start := fixed + block*def.Repeat
+		for _, point := range def.RepeatPoints {
+			decoded, ok := point.decode(address+uint16(start), regs[start:], factors)
+			if ok {
+				decoded.Name = fmt.Sprintf("%s[%d]", point.Name, block+1)
+				points = append(points, decoded)
+			}
+		}
+	}
+	return points
+}
+
+func scaleFactors(points []pointDef, regs []uint16) map[string]*int {
+	factors := map[string]*int{}
+	for _, point := range points {
+		if point.Type != typeSunSSF || point.Offset >= len(regs) || regs[point.Offset] == 0x8000 {
+			continue
+		}
+		sf := int(int16(regs[point.Offset]))
+		if sf >= -10 && sf <= 10 {
+			factors[point.Name] = &sf
+		}
+	}
+	return factors
+}
+
+func (def pointDef) size() int {
+	switch def.Type {
+	case typeString:
+		return def.Size
+	case typeUint32, typeAcc32, typeBitfield32:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// decode returns false when the block is too short for the point.
+func (def pointDef) decode(address uint16, regs []uint16, factors map[string]*int) (Point, bool) {
+	if def.Offset+def.size() > len(regs) {
+		return Point{}, false
+	}
+	regs = regs[def.Offset : def.Offset+def.size()]
+	point := Point{Name: def.Name, Address: address + uint16(def.Offset), Unit: def.Unit}
+	var (
+		raw         float64
+		implemented bool
+	)
+	switch def.Type {
+	case typeString:
+		text := decodeString(regs)
+		if text != "" {
+			point.Value, point.Text = text, text
+		}
+		return point, true
+	case typeUint16:
+		raw, implemented = float64(regs[0]), regs[0] != 0xFFFF
+	case typeInt16:
+		raw, implemented = float64(int16(regs[0])), regs[0] != 0x8000
+	case typeSunSSF:
+		if regs[0] != 0x8000 {
+			point.Value = int16(regs[0])
+			point.Text = strconv.Itoa(int(int16(regs[0])))
+		}
+		return point, true
+	case typeUint32:
+		value := uint32(regs[0])<<16 | uint32(regs[1])
+		raw, implemented = float64(value), value != 0xFFFFFFFF
+	case typeAcc32:
+		value := uint32(regs[0])<<16 | uint32(regs[1])
+		raw, implemented = float64(value), value != 0
+	case typeEnum16:
+		if regs[0] != 0xFFFF {
+			point.Value = regs[0]
+			point.Text = strconv.Itoa(int(regs[0]))
+			if label, ok := def.Labels[regs[0]]; ok {
+				point.Text = fmt.Sprintf("%s (%d)", label, regs[0])
+			}
+		}
+		return point, true
+	case typeBitfield32:
+		value := uint32(regs[0])<<16 | uint32(regs[1])
+		if value != 0xFFFFFFFF {
+			point.Value = value
+			point.Text = fmt.Sprintf("0x%08X", value)
+		}
+		return point, true
+	}
+	if !implemented {
+		return point, true
+	}
+	if def.SF != "" {
+		sf := factors[def.SF]
+		if sf == nil {
+			// A missing or unimplemented scale factor leaves the value
+			// unusable.
+			return point, true
+		}
+		raw = scale(raw, *sf)
+	}
+	point.Value = raw
+	point.Text = strconv.FormatFloat(raw, 'f', -1, 64)
+	return point, true
+}
+
+// scale divides rather than multiplies by fractions of ten so 2301 with an
+// SF of -1 reads 230.1 rather than 230.10000000000002.
+func scale(raw float64, sf int) float64 {
+	if sf < 0 {
+		return raw / math.Pow10(-sf)
+	}
+	return raw * math.Pow10(sf)
+}
+
+func decodeString(regs []uint16) string {
+	buf := make([]byte, 0, len(regs)*2)
+	for _, reg := range regs {
+		buf = append(buf, byte(reg>>8), byte(reg))
+	}
+	if i := strings.IndexByte(string(buf), 0); i >= 0 {
+		buf = buf[:i]
+	}
+	return strings.TrimSpace(string(buf))
+}
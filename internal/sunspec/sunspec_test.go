@@ -0,0 +1,149 @@
+package sunspec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// image is a sparse register space; reads of unset registers fail like an
+// illegal data address exception.
+type image map[int]uint16
+
+func (img image) read(_ context.Context, address, quantity uint16) ([]uint16, error) {
+	regs := make([]uint16, quantity)
+	for i := range regs {
+		value, ok := img[int(address)+i]
+		if !ok {
+			return nil, errors.New("illegal data address")
+		}
+		regs[i] = value
+	}
+	return regs, nil
+}
+
+func (img image) model(address, id int, regs ...uint16) int {
+	img[address], img[address+1] = uint16(id), uint16(len(regs))
+	for i, reg := range regs {
+		img[address+2+i] = reg
+	}
+	return address + 2 + len(regs)
+}
+
+func (img image) end(address int) {
+	img[address], img[address+1] = 0xFFFF, 0
+}
+
+func text(value string, size int) []uint16 {
+	regs := make([]uint16, size)
+	for i := 0; i < len(value); i++ {
+		regs[i/2] |= uint16(value[i]) << (8 * (1 - i%2))
+	}
+	return regs
+}
+
+func block(length int, values map[int]uint16) []uint16 {
+	regs := make([]uint16, length)
+	for offset, value := range values {
+		regs[offset] = value
+	}
+	return regs
+}
+
+func pointsByName(points []Point) map[string]Point {
+	out := map[string]Point{}
+	for _, point := range points {
+		out[point.Name] = point
+	}
+	return out
+}
+
+func TestDiscoverWalksModelChain(t *testing.T) {
+	img := image{40000: 0x5375, 40001: 0x6E53}
+	common := append(append(append(text("SunSpecCo", 16), text("Inverter 5k", 16)...), make([]uint16, 16)...), append(text("SN-0042", 16), 1, 0)...)
+	next := img.model(40002, 1, common...)
+	inverter := block(50, map[int]uint16{
+		0: 1234, 4: 0xFFFE, // A = 12.34
+		8: 2301, 11: 0xFFFF, // PhVphA = 230.1
+		12: 5000, 13: 0, // W
+		14: 5000, 15: 0xFFFE, // Hz = 50
+		22: 0x0001, 23: 0xE240, 24: 0, // WH = 123456
+		31: 0x8000, 35: 0xFFFF, // TmpCab not implemented
+		36: 4,
+		38: 0, 39: 0x0001,
+	})
+	inverter[1] = 0xFFFF
+	next = img.model(next, 103, inverter...)
+	mppt := block(48, map[int]uint16{
+		0: 0xFFFE, 1: 0xFFFF, 2: 0, 3: 0, 6: 2,
+		8 + 9: 512, 8 + 10: 4100, 8 + 17: 4,
+		28 + 9: 498, 28 + 10: 4050, 28 + 17: 8,
+	})
+	next = img.model(next, 160, mppt...)
+	next = img.model(next, 203, block(105, map[int]uint16{16: 0xFC18, 20: 0, 36: 0, 37: 1000, 52: 1})...)
+	next = img.model(next, 64001, 1, 2, 3, 4)
+	img.end(next)
+
+	device, err := Discover(context.Background(), img.read)
+	require.NoError(t, err)
+	require.Equal(t, uint16(40000), device.BaseAddress)
+	ids := []uint16{}
+	for _, model := range device.Models {
+		ids = append(ids, model.ID)
+	}
+	require.Equal(t, []uint16{1, 103, 160, 203, 64001}, ids)
+
+	common1 := pointsByName(device.Models[0].Points)
+	require.Equal(t, "SunSpecCo", common1["Mn"].Value)
+	require.Equal(t, "SN-0042", common1["SN"].Value)
+	require.Nil(t, common1["Opt"].Value)
+	require.Equal(t, uint16(40004), common1["Mn"].Address)
+
+	inv := pointsByName(device.Models[1].Points)
+	require.Equal(t, "Inverter (Three Phase)", device.Models[1].Name)
+	require.Equal(t, 12.34, inv["A"].Value)
+	require.Nil(t, inv["AphA"].Value)
+	require.Equal(t, 230.1, inv["PhVphA"].Value)
+	require.Equal(t, "230.1", inv["PhVphA"].Text)
+	require.Equal(t, "V", inv["PhVphA"].Unit)
+	require.Equal(t, float64(5000), inv["W"].Value)
+	require.Equal(t, float64(50), inv["Hz"].Value)
+	require.Equal(t, float64(123456), inv["WH"].Value)
+	require.Nil(t, inv["TmpCab"].Value)
+	require.Equal(t, "MPPT (4)", inv["St"].Text)
+	require.Equal(t, "0x00000001", inv["Evt1"].Text)
+
+	modules := pointsByName(device.Models[2].Points)
+	require.Equal(t, 5.12, modules["DCA[1]"].Value)
+	require.Equal(t, 405.0, modules["DCV[2]"].Value)
+	require.Equal(t, "STANDBY (8)", modules["DCSt[2]"].Text)
+
+	meter := pointsByName(device.Models[3].Points)
+	require.Equal(t, float64(-1000), meter["W"].Value)
+	require.Equal(t, float64(10000), meter["TotWhExp"].Value)
+
+	require.Empty(t, device.Models[4].Points)
+	require.Empty(t, device.Models[4].Name)
+}
+
+func TestDiscoverTriesEachBase(t *testing.T) {
+	img := image{50000: 0x5375, 50001: 0x6E53}
+	img.end(50002)
+	device, err := Discover(context.Background(), img.read)
+	require.NoError(t, err)
+	require.Equal(t, uint16(50000), device.BaseAddress)
+	require.Empty(t, device.Models)
+
+	_, err = Discover(context.Background(), image{0: 1, 1: 2}.read)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDiscoverReportsShortModels(t *testing.T) {
+	img := image{0: 0x5375, 1: 0x6E53, 2: 103, 3: 50, 4: 1}
+	device, err := Discover(context.Background(), img.read)
+	require.Error(t, err)
+	require.Len(t, device.Models, 1)
+	require.NotEmpty(t, device.Models[0].Error)
+}
@@ -1,13 +1,19 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"gomodmaster/internal/config"
 	"gomodmaster/internal/core"
+	"gomodmaster/internal/profiles"
+	"gomodmaster/internal/sunspec"
 	"gomodmaster/internal/transport/ws"
 	"gomodmaster/internal/version"
 
@@ -20,10 +26,24 @@ type configResponse struct {
 	InvocationFull    string        `json:"invocationFull"`
 }
 
+type saveConfigResponse struct {
+	Path string `json:"path"`
+}
+
+type profilesResponse struct {
+	Connections []profiles.Connection `json:"connections"`
+	Devices     []profiles.Profile    `json:"devices"`
+}
+
 type errorResponse struct {
 	Error string `json:"error"`
 }
 
+type validationResponse struct {
+	Error  string              `json:"error"`
+	Fields []config.FieldError `json:"fields"`
+}
+
 type serialDevicesResponse struct {
 	Devices []string `json:"devices"`
 }
@@ -32,6 +52,35 @@ type versionResponse struct {
 	Version string `json:"version"`
 }
 
+const (
+	defaultLogLimit = 200
+	maxLogLimit     = 1000
+)
+
+type byteOrderRequest struct {
+	Type      config.DecoderType    `json:"type"`
+	Decoder   *config.DecoderConfig `json:"decoder,omitempty"`
+	Expected  float64               `json:"expected"`
+	Registers []uint16              `json:"registers"`
+}
+
+type byteOrderResponse struct {
+	Candidates []core.ByteOrderCandidate `json:"candidates"`
+}
+
+// encodeRequest carries the last read (address and registers) so scale-factor
+// registers can be resolved like they are when decoding.
+type encodeRequest struct {
+	Decoder   config.DecoderConfig `json:"decoder"`
+	Value     string               `json:"value"`
+	Address   uint16               `json:"address"`
+	Registers []uint16             `json:"registers"`
+}
+
+type encodeResponse struct {
+	Registers []uint16 `json:"registers"`
+}
+
 func routes(e *echo.Echo, service *core.Service, hub *ws.Hub) {
 	e.GET("/api/config", func(c echo.Context) error {
 		cfg := service.Config()
@@ -51,6 +100,14 @@ func routes(e *echo.Echo, service *core.Service, hub *ws.Hub) {
 		if cfg.RequireToken && cfg.Token == "" {
 			cfg.Token = current.Token
 		}
+		// Files are written where the command line says, never where a
+		// client asks.
+		cfg.ConfigFile = current.ConfigFile
+		cfg.LogFile.Path = current.LogFile.Path
+		cfg.CapturePath = current.CapturePath
+		if err := cfg.Validate(); err != nil {
+			return invalidConfig(c, err)
+		}
 		service.UpdateConfig(cfg)
 		return c.JSON(http.StatusOK, configResponse{
 			Config:         cfg,
@@ -59,6 +116,17 @@ func routes(e *echo.Echo, service *core.Service, hub *ws.Hub) {
 		})
 	})
 
+	e.POST("/api/config/save", func(c echo.Context) error {
+		cfg := service.Config()
+		path, err := config.Save(cfg)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		cfg.ConfigFile = path
+		service.UpdateConfig(cfg)
+		return c.JSON(http.StatusOK, saveConfigResponse{Path: path})
+	})
+
 	e.POST("/api/connect", func(c echo.Context) error {
 		if err := service.Connect(); err != nil {
 			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
@@ -85,6 +153,173 @@ func routes(e *echo.Echo, service *core.Service, hub *ws.Hub) {
 		return c.JSON(http.StatusOK, result)
 	})
 
+	e.GET("/api/capture", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, service.CaptureStatus())
+	})
+
+	e.POST("/api/capture/start", func(c echo.Context) error {
+		// Captures started from the web get a fresh name next to the
+		// --capture file (or in the working directory); clients can't pick
+		// the path.
+		dir := filepath.Dir(service.Config().CapturePath)
+		path := filepath.Join(dir, core.DefaultCapturePath(time.Now()))
+		if err := service.StartCapture(path); err != nil {
+			if errors.Is(err, core.ErrCaptureActive) {
+				return c.JSON(http.StatusConflict, errorResponse{Error: err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, service.CaptureStatus())
+	})
+
+	e.POST("/api/capture/stop", func(c echo.Context) error {
+		if err := service.StopCapture(); err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, service.CaptureStatus())
+	})
+
+	e.POST("/api/byte-order/detect", func(c echo.Context) error {
+		var req byteOrderRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		if req.Type == "" {
+			req.Type = config.DecoderFloat32
+		}
+		decoder := config.DecoderConfig{Type: req.Type}
+		if req.Decoder != nil {
+			decoder = *req.Decoder
+		} else if configured, ok := findDecoder(service.Config().Decoders, req.Type); ok {
+			decoder = configured
+		}
+		candidates, err := core.DetectByteOrder(req.Registers, decoder, req.Expected)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, byteOrderResponse{Candidates: candidates})
+	})
+
+	e.POST("/api/encode", func(c echo.Context) error {
+		var req encodeRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		regs, err := core.Encode(req.Value, req.Decoder, req.Address, req.Registers)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, encodeResponse{Registers: regs})
+	})
+
+	e.GET("/api/sunspec", func(c echo.Context) error {
+		device, err := sunspec.Discover(c.Request().Context(), sunspec.ServiceReader(service))
+		if errors.Is(err, sunspec.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, device)
+	})
+
+	e.GET("/api/profiles", func(c echo.Context) error {
+		path, err := profiles.ConnectionsFile()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		connections, err := profiles.LoadConnections(path)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		devices, _ := profiles.Library()
+		return c.JSON(http.StatusOK, profilesResponse{Connections: connections, Devices: devices})
+	})
+
+	e.GET("/api/profiles/:name", func(c echo.Context) error {
+		path, err := profiles.ConnectionsFile()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		connections, err := profiles.LoadConnections(path)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		connection, err := profiles.FindConnection(connections, c.Param("name"))
+		if err != nil {
+			return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, connection)
+	})
+
+	// POST creates a profile, from the current connection settings when the
+	// body only has a name; PUT replaces one.
+	e.POST("/api/profiles", func(c echo.Context) error {
+		var connection profiles.Connection
+		if err := c.Bind(&connection); err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		if connection.Protocol == "" {
+			connection = profiles.NewConnection(connection.Name, service.Config())
+		}
+		return putProfile(c, connection, false)
+	})
+
+	e.PUT("/api/profiles/:name", func(c echo.Context) error {
+		var connection profiles.Connection
+		if err := c.Bind(&connection); err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		connection.Name = c.Param("name")
+		return putProfile(c, connection, true)
+	})
+
+	e.DELETE("/api/profiles/:name", func(c echo.Context) error {
+		path, err := profiles.ConnectionsFile()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		err = profiles.DeleteConnection(path, c.Param("name"))
+		if errors.Is(err, profiles.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	// Applying a device profile replaces the points of the previous profile
+	// or map file with its own.
+	e.POST("/api/profiles/:name/apply", func(c echo.Context) error {
+		settings, err := profiles.Lookup(c.Param("name"))
+		if errors.Is(err, profiles.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, errorResponse{Error: err.Error()})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		}
+		cfg := service.Config()
+		profiles.Switch(&cfg, settings)
+		if err := cfg.Validate(); err != nil {
+			return invalidConfig(c, err)
+		}
+		service.UpdateConfig(cfg)
+		return c.JSON(http.StatusOK, configResponse{
+			Config:         cfg,
+			Invocation:     cfg.Invocation(),
+			InvocationFull: cfg.InvocationFull(),
+		})
+	})
+
+	e.GET("/api/logs", func(c echo.Context) error {
+		query, err := parseLogQuery(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, service.QueryLogs(query))
+	})
+
 	e.GET("/api/stats", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, service.Stats())
 	})
@@ -108,6 +343,102 @@ func routes(e *echo.Echo, service *core.Service, hub *ws.Hub) {
 	})
 }
 
+func putProfile(c echo.Context, connection profiles.Connection, replace bool) error {
+	if err := connection.Validate(); err != nil {
+		return invalidConfig(c, err)
+	}
+	path, err := profiles.ConnectionsFile()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+	}
+	connections, err := profiles.LoadConnections(path)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+	}
+	_, err = profiles.FindConnection(connections, connection.Name)
+	exists := err == nil
+	if exists && !replace {
+		return c.JSON(http.StatusConflict, errorResponse{Error: fmt.Sprintf("profile %q already exists", connection.Name)})
+	}
+	if err := profiles.PutConnection(path, connection); err != nil {
+		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+	}
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, connection)
+}
+
+// invalidConfig answers a configuration that fails validation with 422 and
+// the message of each invalid field.
+func invalidConfig(c echo.Context, err error) error {
+	var invalid config.ValidationError
+	if !errors.As(err, &invalid) {
+		return c.JSON(http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusUnprocessableEntity, validationResponse{Error: err.Error(), Fields: invalid})
+}
+
+func findDecoder(decoders []config.DecoderConfig, typ config.DecoderType) (config.DecoderConfig, bool) {
+	for _, decoder := range decoders {
+		if decoder.Type == typ {
+			return decoder, true
+		}
+	}
+	return config.DecoderConfig{}, false
+}
+
+func parseLogQuery(c echo.Context) (core.LogQuery, error) {
+	query := core.LogQuery{
+		Text:  c.QueryParam("q"),
+		Limit: defaultLogLimit,
+		Tail:  c.QueryParam("tail") == "true",
+	}
+	for _, value := range c.QueryParams()["direction"] {
+		for _, direction := range strings.Split(value, ",") {
+			direction = strings.ToLower(strings.TrimSpace(direction))
+			switch direction {
+			case "":
+				continue
+			case "tx", "rx", "err", "sys":
+				query.Directions = append(query.Directions, direction)
+			default:
+				return query, fmt.Errorf("unsupported direction: %s", direction)
+			}
+		}
+	}
+	if value := c.QueryParam("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("since must be RFC3339")
+		}
+		query.Since = since
+	}
+	if value := c.QueryParam("until"); value != "" {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("until must be RFC3339")
+		}
+		query.Until = until
+	}
+	if value := c.QueryParam("cursor"); value != "" {
+		cursor, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("cursor must be a sequence number")
+		}
+		query.After = cursor
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLogLimit {
+			return query, fmt.Errorf("limit must be 1-%d", maxLogLimit)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
 func serialDeviceOptions() []string {
 	switch runtime.GOOS {
 	case "darwin":
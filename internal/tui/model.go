@@ -2,12 +2,16 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gomodmaster/internal/config"
 	"gomodmaster/internal/core"
+	"gomodmaster/internal/sunspec"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -35,6 +39,8 @@ const (
 	viewConnection
 	viewFunctionSelect
 	viewDeviceSelect
+	viewSunSpec
+	viewProfileSelect
 )
 
 const (
@@ -51,6 +57,13 @@ const (
 	focusConnStopBits
 	focusConnTimeout
 	focusConnUnitID
+	focusLogSearch
+	focusAssign
+	focusUnassign
+	focusDetectOrder
+	focusTimezone
+	focusEncode
+	focusProfileName
 )
 
 var readKinds = []readKindOption{
@@ -60,6 +73,10 @@ var readKinds = []readKindOption{
 	{label: "Input Registers", kind: core.ReadInput, code: "04"},
 }
 
+var logDirections = []string{"", "tx", "rx", "err", "sys"}
+
+var logWindows = []time.Duration{0, time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour}
+
 func readKindIndex(kind string) int {
 	normalized := strings.TrimSpace(strings.ToLower(kind))
 	for idx, option := range readKinds {
@@ -83,8 +100,12 @@ type model struct {
 	lastResult         *core.ReadResult
 	logs               []core.LogEntry
 	logLimit           int
+	logDirectionIdx    int
+	logWindowIdx       int
+	logSearch          string
 	stats              core.Stats
 	status             core.ConnectionStatus
+	capture            core.CaptureStatus
 	autoConnect        bool
 	pendingRead        *core.ReadRequest
 	addressError       string
@@ -95,6 +116,9 @@ type model struct {
 	editInput          inputModel
 	editError          string
 	decoderCursor      int
+	detectCandidates   []core.ByteOrderCandidate
+	detectMessage      string
+	encodeMessage      string
 	functionCursor     int
 	deviceCursor       int
 	deviceList         []string
@@ -104,6 +128,15 @@ type model struct {
 	connectionBoxKey   string
 	resultBoxCache     string
 	resultBoxKey       string
+	sunspecDevice      *sunspec.Device
+	sunspecError       string
+	sunspecBusy        bool
+	sunspecAll         bool
+	sunspecScroll      int
+	profileCursor      int
+	profileList        []profileItem
+	profileError       string
+	configMessage      string
 	printInvocation    bool
 }
 
@@ -134,6 +167,7 @@ func newModel(cfg config.Config, service *core.Service) model {
 		logLimit:      logBufferSize,
 		autoConnect:   true,
 		status:        service.StatusSnapshot(),
+		capture:       service.CaptureStatus(),
 		logs:          service.Logs(),
 		editInput:     newInputModel(),
 	}
@@ -163,6 +197,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case errorMsg:
 		return m, nil
+	case sunspecMsg:
+		m.sunspecBusy = false
+		m.sunspecError = ""
+		if msg.err != nil {
+			m.sunspecError = msg.err.Error()
+		}
+		if msg.err == nil || len(msg.device.Models) > 0 {
+			m.sunspecDevice = &msg.device
+		} else {
+			m.sunspecDevice = nil
+		}
+		return m, nil
 	case tea.KeyMsg:
 		if msg.Type == tea.KeyCtrlC {
 			m.printInvocation = true
@@ -201,6 +247,12 @@ func (m model) handleEvent(event core.Event) (tea.Model, tea.Cmd) {
 				m.logs = m.logs[len(m.logs)-m.logLimit:]
 			}
 		}
+	case core.EventCapture:
+		capture, ok := event.Payload.(core.CaptureStatus)
+		if ok {
+			m.capture = capture
+			m.updateMainCaches()
+		}
 	case core.EventStats:
 		stats, ok := event.Payload.(core.Stats)
 		if ok {
@@ -219,6 +271,7 @@ func (m model) handleEvent(event core.Event) (tea.Model, tea.Cmd) {
 
 func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
+	m.configMessage = ""
 
 	if m.view == viewHelp {
 		if key == "esc" || key == "?" {
@@ -227,10 +280,7 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	if m.view == viewLogs {
-		if key == "esc" || key == "l" {
-			m.view = viewMain
-		}
-		return m, nil
+		return m.handleLogKeys(key)
 	}
 	if m.view == viewDecoder {
 		return m.handleDecoderKeys(key)
@@ -267,6 +317,8 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m.beginEdit(focusConnStopBits)
 		case "a":
 			return m.openDeviceSelect()
+		case "o":
+			return m.openProfileSelect()
 		case "u":
 			return m.beginEdit(focusConnTimeout)
 		case "i":
@@ -281,6 +333,12 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.view == viewDeviceSelect {
 		return m.handleDeviceKeys(key)
 	}
+	if m.view == viewSunSpec {
+		return m.handleSunSpecKeys(key)
+	}
+	if m.view == viewProfileSelect {
+		return m.handleProfileKeys(key)
+	}
 
 	switch key {
 	case "q":
@@ -298,6 +356,8 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "s":
 		m.view = viewConnection
 		return m, nil
+	case "u":
+		return m.openSunSpec()
 	case "c":
 		if m.status.Connected || m.status.Connecting {
 			_ = m.service.Disconnect()
@@ -309,6 +369,9 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.autoConnect = !m.autoConnect
 		m.updateMainCaches()
 		return m, nil
+	case "p":
+		m.toggleCapture()
+		return m, nil
 	case "r":
 		return m.triggerRead()
 	case "f":
@@ -327,11 +390,25 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.beginEdit(focusQuantity)
 	case "i":
 		return m.beginEdit(focusUnitID)
+	case "w":
+		m.saveConfig()
+		return m, nil
 	}
 
 	return m, nil
 }
 
+func (m *model) saveConfig() {
+	path, err := config.Save(m.cfg)
+	if err != nil {
+		m.configMessage = errorStyle.Render("save config: " + err.Error())
+		return
+	}
+	m.cfg.ConfigFile = path
+	m.service.UpdateConfig(m.cfg)
+	m.configMessage = "saved " + path
+}
+
 func (m model) updateInputs(msg tea.Msg) (model, tea.Cmd) {
 	if !m.editActive {
 		return m, nil
@@ -375,6 +452,19 @@ func (m model) beginEdit(field fieldFocus) (tea.Model, tea.Cmd) {
 		value = fmt.Sprintf("%d", m.cfg.TimeoutMs)
 	case focusConnUnitID:
 		value = fmt.Sprintf("%d", m.cfg.UnitID)
+	case focusLogSearch:
+		value = m.logSearch
+	case focusAssign, focusUnassign:
+		value = m.addressValue
+	case focusDetectOrder, focusEncode:
+		value = ""
+	case focusProfileName:
+		value = m.cfg.Profile
+	case focusTimezone:
+		if m.decoderCursor < 0 || m.decoderCursor >= len(m.cfg.Decoders) || !isTimestamp(m.cfg.Decoders[m.decoderCursor].Type) {
+			return m, nil
+		}
+		value = m.cfg.Decoders[m.decoderCursor].Timezone
 	default:
 		return m, nil
 	}
@@ -398,6 +488,8 @@ func (m model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m model) applyEdit() (tea.Model, tea.Cmd) {
 	value := strings.TrimSpace(m.editInput.Value())
+	previous := m
+	update, reconnect := false, false
 	switch m.editField {
 	case focusAddress:
 		if parseAddress(value) == nil {
@@ -407,12 +499,12 @@ func (m model) applyEdit() (tea.Model, tea.Cmd) {
 		m.addressValue = value
 		m.cfg.ReadAddress = *parseAddress(value)
 	case focusQuantity:
-		if !validQuantity(value) {
-			m.editError = "Quantity must be 1-65535"
+		parsed, ok := parseUint16(value)
+		if !ok {
+			m.editError = "Quantity must be a number"
 			return m, nil
 		}
 		m.quantityValue = value
-		parsed, _ := parseUint16(value)
 		m.cfg.ReadQuantity = parsed
 	case focusUnitID:
 		if !validUnitID(value) {
@@ -421,71 +513,56 @@ func (m model) applyEdit() (tea.Model, tea.Cmd) {
 		}
 		m.unitValue = value
 		m.cfg.UnitID = parseUint8(value)
-		m.updateConfig(false)
+		update = true
 	case focusConnHost:
-		if strings.TrimSpace(value) == "" {
-			m.editError = "Host cannot be empty"
-			return m, nil
-		}
-		m.cfg.TCP.Host = strings.TrimSpace(value)
-		m.updateConfig(true)
+		m.cfg.TCP.Host = value
+		update, reconnect = true, true
 	case focusConnPort:
 		port, ok := parseUint16(value)
-		if !ok || port == 0 {
+		if !ok {
 			m.editError = "Port must be 1-65535"
 			return m, nil
 		}
 		m.cfg.TCP.Port = int(port)
-		m.updateConfig(true)
+		update, reconnect = true, true
 	case focusConnDevice:
-		if strings.TrimSpace(value) == "" {
-			m.editError = "Device cannot be empty"
-			return m, nil
-		}
-		m.cfg.Serial.Device = strings.TrimSpace(value)
-		m.updateConfig(true)
+		m.cfg.Serial.Device = value
+		update, reconnect = true, true
 	case focusConnSpeed:
 		speed, ok := parseUint32(value)
-		if !ok || speed == 0 {
-			m.editError = "Speed must be > 0"
+		if !ok {
+			m.editError = "Speed must be a number"
 			return m, nil
 		}
 		m.cfg.Serial.Speed = uint(speed)
-		m.updateConfig(true)
+		update, reconnect = true, true
 	case focusConnDataBits:
 		bits, ok := parseUint32(value)
-		if !ok || bits == 0 {
-			m.editError = "Data bits must be > 0"
+		if !ok {
+			m.editError = "Data bits must be a number"
 			return m, nil
 		}
 		m.cfg.Serial.DataBits = uint(bits)
-		m.updateConfig(true)
+		update, reconnect = true, true
 	case focusConnParity:
-		normalized := strings.ToLower(strings.TrimSpace(value))
-		switch normalized {
-		case "none", "even", "odd":
-			m.cfg.Serial.Parity = normalized
-			m.updateConfig(true)
-		default:
-			m.editError = "Parity must be none/even/odd"
-			return m, nil
-		}
+		m.cfg.Serial.Parity = strings.ToLower(value)
+		update, reconnect = true, true
 	case focusConnStopBits:
 		bits, ok := parseUint32(value)
-		if !ok || bits == 0 {
-			m.editError = "Stop bits must be > 0"
+		if !ok {
+			m.editError = "Stop bits must be a number"
 			return m, nil
 		}
 		m.cfg.Serial.StopBits = uint(bits)
-		m.updateConfig(true)
+		update, reconnect = true, true
 	case focusConnTimeout:
 		timeout, ok := parseUint32(value)
-		if !ok || timeout == 0 {
-			m.editError = "Timeout must be > 0"
+		if !ok {
+			m.editError = "Timeout must be a number"
 			return m, nil
 		}
 		m.cfg.TimeoutMs = int64(timeout)
-		m.updateConfig(true)
+		update, reconnect = true, true
 	case focusConnUnitID:
 		if !validUnitID(value) {
 			m.editError = "Unit ID must be 0-255"
@@ -493,12 +570,89 @@ func (m model) applyEdit() (tea.Model, tea.Cmd) {
 		}
 		m.cfg.UnitID = parseUint8(value)
 		m.unitValue = value
-		m.updateConfig(true)
+		update, reconnect = true, true
+	case focusLogSearch:
+		m.logSearch = value
+	case focusAssign:
+		if !m.assignDecoder(value) {
+			m.editError = "Use ADDR or ADDR:COUNT"
+			return m, nil
+		}
+	case focusUnassign:
+		address := parseAddress(value)
+		if address == nil {
+			m.editError = "Invalid address"
+			return m, nil
+		}
+		m.unassign(*address)
+	case focusDetectOrder:
+		expected, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			m.editError = "Expected value must be a number"
+			return m, nil
+		}
+		m.detectByteOrder(expected)
+	case focusEncode:
+		m.encodeDecoderValue(value)
+	case focusProfileName:
+		if err := m.saveConnectionProfile(value); err != nil {
+			m.editError = err.Error()
+			return m, nil
+		}
+	case focusTimezone:
+		if _, err := time.LoadLocation(value); value != "" && err != nil {
+			m.editError = "Unknown timezone (use e.g. Europe/Berlin)"
+			return m, nil
+		}
+		m.cfg.Decoders[m.decoderCursor].Timezone = value
+		m.updateConfig(false)
+		m.updateValueTableCache()
+	}
+	if message := editConfigError(m.cfg, m.editField); message != "" {
+		previous.editError = message
+		return previous, nil
+	}
+	if update {
+		m.updateConfig(reconnect)
 	}
 	m.finishEdit()
 	return m, nil
 }
 
+// editConfigFields maps edit fields to the setting config.Validate reports
+// them under.
+var editConfigFields = map[fieldFocus]string{
+	focusAddress:      "readAddress",
+	focusQuantity:     "readQuantity",
+	focusConnHost:     "tcp.host",
+	focusConnPort:     "tcp.port",
+	focusConnDevice:   "serial.device",
+	focusConnSpeed:    "serial.speed",
+	focusConnDataBits: "serial.dataBits",
+	focusConnParity:   "serial.parity",
+	focusConnStopBits: "serial.stopBits",
+	focusConnTimeout:  "timeoutMs",
+}
+
+// editConfigError is the validation message for the edited setting of cfg,
+// or "" when it is valid.
+func editConfigError(cfg config.Config, field fieldFocus) string {
+	name, ok := editConfigFields[field]
+	if !ok {
+		return ""
+	}
+	var invalid config.ValidationError
+	if !errors.As(cfg.Validate(), &invalid) {
+		return ""
+	}
+	message := invalid.Message(name)
+	if message == "" {
+		return ""
+	}
+	label := fieldLabel(field)
+	return strings.ToUpper(label[:1]) + label[1:] + " " + message
+}
+
 func (m *model) cancelEdit() {
 	m.editError = ""
 	m.finishEdit()
@@ -594,11 +748,6 @@ func parseAddress(input string) *uint16 {
 	return &result
 }
 
-func validQuantity(value string) bool {
-	parsed, err := strconv.Atoi(strings.TrimSpace(value))
-	return err == nil && parsed >= 1 && parsed <= 0xffff
-}
-
 func validUnitID(value string) bool {
 	parsed, err := strconv.Atoi(strings.TrimSpace(value))
 	return err == nil && parsed >= 0 && parsed <= 255
@@ -641,8 +790,10 @@ func editCharLimit(field fieldFocus) int {
 		return 3
 	case focusConnDevice:
 		return 128
-	case focusConnHost:
+	case focusConnHost, focusLogSearch, focusTimezone, focusEncode, focusProfileName:
 		return 64
+	case focusAssign:
+		return 12
 	default:
 		return 16
 	}
@@ -703,7 +854,7 @@ func (m *model) updateMainCaches() {
 		m.resultBoxKey = ""
 		return
 	}
-	connKey := fmt.Sprintf("w=%d|status=%s|err=%s|proto=%s|target=%s|unit=%d|timeout=%d|auto=%t",
+	connKey := fmt.Sprintf("w=%d|status=%s|err=%s|proto=%s|target=%s|unit=%d|timeout=%d|auto=%t|capture=%t:%d",
 		m.width,
 		connectionLabel(m.status),
 		m.status.LastError,
@@ -712,6 +863,8 @@ func (m *model) updateMainCaches() {
 		m.cfg.UnitID,
 		m.cfg.TimeoutMs,
 		m.autoConnect,
+		m.capture.Active,
+		m.capture.Packets,
 	)
 	if connKey != m.connectionBoxKey {
 		m.connectionBoxCache = renderBox("connection [s]ettings", renderConnectionSummary(*m), m.width)
@@ -757,9 +910,10 @@ func (m *model) valueTableCacheKey() string {
 		m.cfg.AddressFormat,
 		m.cfg.ValueBase,
 	)
-	for _, decoder := range m.cfg.Decoders {
-		fmt.Fprintf(&b, "%s:%t:%s:%s|", decoder.Type, decoder.Enabled, decoder.Endianness, decoder.WordOrder)
-	}
+	decoders, _ := json.Marshal(m.cfg.Decoders)
+	b.Write(decoders)
+	assignments, _ := json.Marshal(m.cfg.Assignments)
+	b.Write(assignments)
 	return b.String()
 }
 
@@ -793,10 +947,112 @@ func (m model) handleDecoderKeys(key string) (tea.Model, tea.Cmd) {
 		m.updateValueTableCache()
 		m.updateMainCaches()
 		return m, nil
+	case "+", "=", "-", "t", "c":
+		m.adjustStringDecoder(m.decoderCursor, key)
+		m.adjustTimestampDecoder(m.decoderCursor, key)
+		m.adjustPackedDecoder(m.decoderCursor, key)
+		m.updateValueTableCache()
+		m.updateMainCaches()
+		return m, nil
+	case "b":
+		m.adjustPackedDecoder(m.decoderCursor, key)
+		m.updateValueTableCache()
+		m.updateMainCaches()
+		return m, nil
+	case "m":
+		m.adjustTimestampDecoder(m.decoderCursor, key)
+		m.updateValueTableCache()
+		m.updateMainCaches()
+		return m, nil
+	case "o":
+		m.cycleDecoderByteOrder(m.decoderCursor)
+		m.updateValueTableCache()
+		m.updateMainCaches()
+		return m, nil
+	case "g":
+		return m.beginEdit(focusDetectOrder)
+	case "a":
+		return m.beginEdit(focusAssign)
+	case "x":
+		return m.beginEdit(focusUnassign)
+	case "z":
+		return m.beginEdit(focusTimezone)
+	case "n":
+		return m.beginEdit(focusEncode)
 	}
 	return m, nil
 }
 
+// assignDecoder pins a copy of the selected decoder to ADDR[:COUNT],
+// replacing any assignment that starts at the same address.
+func (m *model) assignDecoder(value string) bool {
+	if m.decoderCursor < 0 || m.decoderCursor >= len(m.cfg.Decoders) {
+		return false
+	}
+	target, countValue, hasCount := strings.Cut(value, ":")
+	address := parseAddress(target)
+	if address == nil {
+		return false
+	}
+	assignment := config.Assignment{Address: *address, Decoder: m.cfg.Decoders[m.decoderCursor]}
+	assignment.Decoder.Enabled = true
+	if hasCount {
+		count, err := strconv.Atoi(strings.TrimSpace(countValue))
+		if err != nil || count < 1 || count > 0xffff {
+			return false
+		}
+		assignment.Count = count
+	}
+	m.unassign(assignment.Address)
+	m.cfg.Assignments = append(m.cfg.Assignments, assignment)
+	m.updateConfig(false)
+	m.updateValueTableCache()
+	m.updateMainCaches()
+	return true
+}
+
+func (m *model) unassign(address uint16) {
+	kept := m.cfg.Assignments[:0:0]
+	for _, assignment := range m.cfg.Assignments {
+		if assignment.Address != address {
+			kept = append(kept, assignment)
+		}
+	}
+	m.cfg.Assignments = kept
+	m.updateConfig(false)
+	m.updateValueTableCache()
+	m.updateMainCaches()
+}
+
+func (m model) handleLogKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc", "l":
+		m.view = viewMain
+	case "f":
+		m.logDirectionIdx = (m.logDirectionIdx + 1) % len(logDirections)
+	case "m":
+		m.logWindowIdx = (m.logWindowIdx + 1) % len(logWindows)
+	case "/":
+		return m.beginEdit(focusLogSearch)
+	case "x":
+		m.logDirectionIdx = 0
+		m.logWindowIdx = 0
+		m.logSearch = ""
+	}
+	return m, nil
+}
+
+func (m model) logQuery() core.LogQuery {
+	query := core.LogQuery{Text: m.logSearch}
+	if direction := logDirections[m.logDirectionIdx]; direction != "" {
+		query.Directions = []string{direction}
+	}
+	if window := logWindows[m.logWindowIdx]; window > 0 {
+		query.Since = time.Now().Add(-window)
+	}
+	return query
+}
+
 func (m model) handleFunctionKeys(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "esc", "f":
@@ -893,6 +1149,165 @@ func (m *model) toggleDecoderWordOrder(idx int) {
 	m.service.UpdateConfig(m.cfg)
 }
 
+func (m *model) cycleDecoderByteOrder(idx int) {
+	if idx < 0 || idx >= len(m.cfg.Decoders) {
+		return
+	}
+	dec := m.cfg.Decoders[idx]
+	dec.SetByteOrder(config.ByteOrders[(indexOf(config.ByteOrders, dec.ByteOrder())+1)%len(config.ByteOrders)])
+	m.cfg.Decoders[idx] = dec
+	m.service.UpdateConfig(m.cfg)
+}
+
+// encodeDecoderValue shows the register words value encodes to with the
+// selected decoder. Scale-factor registers are taken from the last read.
+func (m *model) encodeDecoderValue(value string) {
+	if m.decoderCursor < 0 || m.decoderCursor >= len(m.cfg.Decoders) {
+		return
+	}
+	dec := m.cfg.Decoders[m.decoderCursor]
+	var (
+		address uint16
+		block   []uint16
+	)
+	if m.lastResult != nil {
+		address, block = m.lastResult.Address, m.lastResult.RegValues
+	}
+	regs, err := core.Encode(value, dec, address, block)
+	if err != nil {
+		m.encodeMessage = err.Error()
+		return
+	}
+	words := make([]string, len(regs))
+	for i, reg := range regs {
+		words[i] = fmt.Sprintf("0x%04X", reg)
+	}
+	m.encodeMessage = fmt.Sprintf("%s %s → %s", dec.Type, value, strings.Join(words, " "))
+}
+
+// detectByteOrder tries every preset for the selected decoder on the last
+// read and applies the closest match when it starts at the first register;
+// a match further in only says where to read or pin the decoder.
+func (m *model) detectByteOrder(expected float64) {
+	m.detectCandidates = nil
+	m.detectMessage = ""
+	if m.decoderCursor < 0 || m.decoderCursor >= len(m.cfg.Decoders) {
+		return
+	}
+	if m.lastResult == nil || len(m.lastResult.RegValues) == 0 {
+		m.detectMessage = "Read registers first"
+		return
+	}
+	dec := m.cfg.Decoders[m.decoderCursor]
+	candidates, err := core.DetectByteOrder(m.lastResult.RegValues, dec, expected)
+	if err != nil {
+		m.detectMessage = err.Error()
+		return
+	}
+	if len(candidates) == 0 {
+		m.detectMessage = "No order decodes to a finite value"
+		return
+	}
+	m.detectCandidates = candidates[:min(4, len(candidates))]
+	if best := candidates[0]; best.Offset != 0 {
+		m.detectMessage = fmt.Sprintf("Best match %s starts at +%d; not applied, read or assign from there", best.Order, best.Offset)
+		return
+	}
+	dec.SetByteOrder(candidates[0].Order)
+	m.cfg.Decoders[m.decoderCursor] = dec
+	m.service.UpdateConfig(m.cfg)
+	m.detectMessage = fmt.Sprintf("Applied %s to %s", candidates[0].Order, dec.Type)
+	m.updateValueTableCache()
+	m.updateMainCaches()
+}
+
+var (
+	stringTrims     = []config.StringTrim{config.TrimNone, config.TrimNull, config.TrimSpace, config.TrimBoth}
+	stringEncodings = []config.StringEncoding{config.EncodingASCII, config.EncodingUTF8, config.EncodingLatin1}
+)
+
+func (m *model) adjustStringDecoder(idx int, key string) {
+	if idx < 0 || idx >= len(m.cfg.Decoders) || m.cfg.Decoders[idx].Type != config.DecoderString {
+		return
+	}
+	dec := m.cfg.Decoders[idx]
+	switch key {
+	case "+", "=":
+		if dec.Registers < 125 {
+			dec.Registers++
+		}
+	case "-":
+		if dec.Registers > 1 {
+			dec.Registers--
+		}
+	case "t":
+		dec.Trim = stringTrims[(indexOf(stringTrims, dec.Trim)+1)%len(stringTrims)]
+	case "c":
+		dec.Encoding = stringEncodings[(indexOf(stringEncodings, dec.Encoding)+1)%len(stringEncodings)]
+	}
+	m.cfg.Decoders[idx] = dec
+	m.service.UpdateConfig(m.cfg)
+}
+
+// adjustTimestampDecoder toggles epoch milliseconds ([m]) and switches packed
+// date/time between one field per register and one per byte ([+]/[-]).
+func (m *model) adjustTimestampDecoder(idx int, key string) {
+	if idx < 0 || idx >= len(m.cfg.Decoders) {
+		return
+	}
+	dec := m.cfg.Decoders[idx]
+	switch {
+	case key == "m" && (dec.Type == config.DecoderEpoch32 || dec.Type == config.DecoderEpoch64):
+		dec.Millis = !dec.Millis
+	case (key == "+" || key == "=") && dec.Type == config.DecoderDate:
+		dec.Registers = 6
+	case key == "-" && dec.Type == config.DecoderDate:
+		dec.Registers = 3
+	default:
+		return
+	}
+	m.cfg.Decoders[idx] = dec
+	m.service.UpdateConfig(m.cfg)
+}
+
+// adjustPackedDecoder changes how many coils a packed decoder combines
+// ([+]/[-]) and whether the first one is the LSB or MSB ([b]).
+func (m *model) adjustPackedDecoder(idx int, key string) {
+	if idx < 0 || idx >= len(m.cfg.Decoders) || m.cfg.Decoders[idx].Type != config.DecoderPacked {
+		return
+	}
+	dec := m.cfg.Decoders[idx]
+	switch key {
+	case "+", "=":
+		if dec.Bits < 64 {
+			dec.Bits++
+		}
+	case "-":
+		if dec.Bits > 1 {
+			dec.Bits--
+		}
+	case "b":
+		dec.MSBFirst = !dec.MSBFirst
+	default:
+		return
+	}
+	m.cfg.Decoders[idx] = dec
+	m.service.UpdateConfig(m.cfg)
+}
+
+func isTimestamp(typ config.DecoderType) bool {
+	return typ == config.DecoderEpoch32 || typ == config.DecoderEpoch64 || typ == config.DecoderDate
+}
+
+func indexOf[T comparable](values []T, value T) int {
+	for idx, candidate := range values {
+		if candidate == value {
+			return idx
+		}
+	}
+	return -1
+}
+
 func (m model) View() string {
 	if m.width > 0 && (m.width < minWidth || m.height < minHeight) {
 		return renderTooSmall(m.width, m.height)
@@ -914,6 +1329,10 @@ func renderBase(m model) string {
 		return renderFunctionSelect(m)
 	case viewDeviceSelect:
 		return renderDeviceSelect(m)
+	case viewSunSpec:
+		return renderSunSpec(m)
+	case viewProfileSelect:
+		return renderProfileSelect(m)
 	default:
 		return renderMain(m)
 	}
@@ -935,6 +1354,16 @@ func (m *model) toggleProtocol() {
 	m.updateConfig(true)
 }
 
+func (m *model) toggleCapture() {
+	if m.capture.Active {
+		_ = m.service.StopCapture()
+	} else {
+		_ = m.service.StartCapture(m.cfg.CapturePath)
+	}
+	m.capture = m.service.CaptureStatus()
+	m.updateMainCaches()
+}
+
 func (m *model) toggleAddressBase() {
 	if m.cfg.AddressBase == config.AddressBaseZero {
 		m.cfg.AddressBase = config.AddressBaseOne
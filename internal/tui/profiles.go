@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"gomodmaster/internal/profiles"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// profileItem is a row of the profile picker: a saved connection profile or
+// a device profile.
+type profileItem struct {
+	connection *profiles.Connection
+	device     *profiles.Profile
+}
+
+func (item profileItem) name() string {
+	if item.connection != nil {
+		return item.connection.Name
+	}
+	return item.device.Name
+}
+
+func (m *model) openProfileSelect() (tea.Model, tea.Cmd) {
+	m.loadProfiles()
+	m.profileCursor = 0
+	for idx, item := range m.profileList {
+		if item.name() == m.cfg.Profile {
+			m.profileCursor = idx
+			break
+		}
+	}
+	m.view = viewProfileSelect
+	return *m, nil
+}
+
+func (m *model) loadProfiles() {
+	m.profileList = nil
+	m.profileError = ""
+	if path, err := profiles.ConnectionsFile(); err == nil {
+		connections, err := profiles.LoadConnections(path)
+		if err != nil {
+			m.profileError = err.Error()
+		}
+		for idx := range connections {
+			m.profileList = append(m.profileList, profileItem{connection: &connections[idx]})
+		}
+	}
+	library, errs := profiles.Library()
+	if len(errs) > 0 && m.profileError == "" {
+		m.profileError = errs[0].Error()
+	}
+	for idx := range library {
+		m.profileList = append(m.profileList, profileItem{device: &library[idx]})
+	}
+	m.profileCursor = min(m.profileCursor, max(len(m.profileList)-1, 0))
+}
+
+// saveConnectionProfile stores the current connection settings under name.
+func (m *model) saveConnectionProfile(name string) error {
+	path, err := profiles.ConnectionsFile()
+	if err != nil {
+		return err
+	}
+	if err := profiles.PutConnection(path, profiles.NewConnection(name, m.cfg)); err != nil {
+		return err
+	}
+	m.cfg.Profile = name
+	m.updateConfig(false)
+	m.loadProfiles()
+	return nil
+}
+
+func (m *model) deleteConnectionProfile() {
+	if m.profileCursor < 0 || m.profileCursor >= len(m.profileList) {
+		return
+	}
+	item := m.profileList[m.profileCursor]
+	if item.connection == nil {
+		m.profileError = "built-in and user device profiles are removed from their directory"
+		return
+	}
+	path, err := profiles.ConnectionsFile()
+	if err == nil {
+		err = profiles.DeleteConnection(path, item.connection.Name)
+	}
+	m.loadProfiles()
+	if err != nil {
+		m.profileError = err.Error()
+	}
+}
+
+func (m model) handleProfileKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc", "o":
+		m.view = viewConnection
+	case "up", "k":
+		if m.profileCursor > 0 {
+			m.profileCursor--
+		}
+	case "down", "j":
+		if m.profileCursor < len(m.profileList)-1 {
+			m.profileCursor++
+		}
+	case "n":
+		return m.beginEdit(focusProfileName)
+	case "x":
+		m.deleteConnectionProfile()
+	case "enter":
+		if m.profileCursor >= 0 && m.profileCursor < len(m.profileList) {
+			item := m.profileList[m.profileCursor]
+			if item.connection != nil {
+				m.applyConnection(*item.connection)
+			} else {
+				m.applyProfile(*item.device)
+			}
+		}
+		m.view = viewConnection
+	}
+	return m, nil
+}
+
+func (m *model) applyConnection(connection profiles.Connection) {
+	profiles.Switch(&m.cfg, connection)
+	m.unitValue = fmt.Sprintf("%d", m.cfg.UnitID)
+	m.updateConfig(true)
+	m.updateValueTableCache()
+}
+
+// applyProfile replaces the mapped points of a previous profile or map file
+// with the profile's and takes over all of its connection defaults.
+func (m *model) applyProfile(profile profiles.Profile) {
+	profiles.Switch(&m.cfg, profile)
+	m.addressValue = fmt.Sprintf("%d", m.cfg.ReadAddress)
+	m.quantityValue = fmt.Sprintf("%d", m.cfg.ReadQuantity)
+	m.unitValue = fmt.Sprintf("%d", m.cfg.UnitID)
+	m.selectedKindIdx = readKindIndex(m.cfg.ReadKind)
+	m.updateConfig(true)
+}
+
+func renderProfileSelect(m model) string {
+	lines := []string{"Use [j]/[k] to move, [enter] to apply, [n] save current connection as, [x] delete, [esc] to cancel", ""}
+	if len(m.profileList) == 0 {
+		lines = append(lines, dimStyle.Render("No profiles found"))
+	}
+	for idx, item := range m.profileList {
+		label := item.name()
+		source := "connection"
+		if item.device != nil {
+			source = item.device.Source
+			if item.device.Title != "" {
+				label += " - " + item.device.Title
+			}
+		}
+		if idx == m.profileCursor {
+			label = selectedStyle.Render("> " + label)
+		} else {
+			label = "  " + label
+		}
+		lines = append(lines, label+"  "+dimStyle.Render(source))
+	}
+	if m.editActive && m.editField == focusProfileName {
+		lines = append(lines, "", renderEditableField(m, focusProfileName, fieldLabel(focusProfileName), ""))
+	}
+	if path, err := profiles.ConnectionsFile(); err == nil {
+		lines = append(lines, "", dimStyle.Render("connections: "+path))
+	}
+	if dir, err := profiles.UserDir(); err == nil {
+		lines = append(lines, dimStyle.Render("device profiles: "+dir))
+	}
+	if m.profileError != "" {
+		lines = append(lines, errorStyle.Render(m.profileError))
+	}
+	box := renderBox("profiles", strings.Join(lines, "\n"), m.width)
+	return renderScreen(m, box)
+}
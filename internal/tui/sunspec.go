@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gomodmaster/internal/sunspec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type sunspecMsg struct {
+	device sunspec.Device
+	err    error
+}
+
+func (m model) sunspecCmd() tea.Cmd {
+	return func() tea.Msg {
+		device, err := sunspec.Discover(context.Background(), sunspec.ServiceReader(m.service))
+		return sunspecMsg{device: device, err: err}
+	}
+}
+
+func (m model) openSunSpec() (tea.Model, tea.Cmd) {
+	m.view = viewSunSpec
+	if m.sunspecBusy || m.sunspecDevice != nil {
+		return m, nil
+	}
+	return m.discoverSunSpec()
+}
+
+func (m model) discoverSunSpec() (tea.Model, tea.Cmd) {
+	if m.sunspecBusy {
+		return m, nil
+	}
+	m.sunspecBusy = true
+	m.sunspecError = ""
+	m.sunspecScroll = 0
+	return m, m.sunspecCmd()
+}
+
+func (m model) handleSunSpecKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc", "u":
+		m.view = viewMain
+	case "r":
+		return m.discoverSunSpec()
+	case "h":
+		m.sunspecAll = !m.sunspecAll
+		m.sunspecScroll = 0
+	case "j", "down":
+		m.sunspecScroll = min(m.sunspecScroll+1, m.sunspecMaxScroll())
+	case "k", "up":
+		if m.sunspecScroll > 0 {
+			m.sunspecScroll--
+		}
+	case "pgdown", " ":
+		m.sunspecScroll = min(m.sunspecScroll+max(m.height-8, 1), m.sunspecMaxScroll())
+	case "pgup":
+		m.sunspecScroll = max(m.sunspecScroll-max(m.height-8, 1), 0)
+	}
+	return m, nil
+}
+
+func (m model) sunspecMaxScroll() int {
+	if m.sunspecDevice == nil {
+		return 0
+	}
+	return max(len(sunspecLines(*m.sunspecDevice, m.sunspecAll))-(m.height-7), 0)
+}
+
+func renderSunSpec(m model) string {
+	box := renderBox("sunspec", renderSunSpecBody(m), m.width)
+	return renderScreen(m, box)
+}
+
+func renderSunSpecBody(m model) string {
+	switch {
+	case m.sunspecBusy:
+		return dimStyle.Render("Discovering SunSpec models...")
+	case m.sunspecDevice == nil && m.sunspecError != "":
+		return errorStyle.Render(m.sunspecError)
+	case m.sunspecDevice == nil:
+		return ""
+	}
+	lines := sunspecLines(*m.sunspecDevice, m.sunspecAll)
+	if m.sunspecError != "" {
+		lines = append(lines, errorStyle.Render(m.sunspecError))
+	}
+	toggle := "show"
+	if m.sunspecAll {
+		toggle = "hide"
+	}
+	header := dimStyle.Render(fmt.Sprintf("SunS at %d, %d models | [h] %s unimplemented points",
+		m.sunspecDevice.BaseAddress, len(m.sunspecDevice.Models), toggle))
+	visible := m.height - 7
+	if visible < 1 {
+		visible = len(lines)
+	}
+	start := min(m.sunspecScroll, max(len(lines)-visible, 0))
+	end := min(start+visible, len(lines))
+	return header + "\n" + strings.Join(lines[start:end], "\n")
+}
+
+func sunspecLines(device sunspec.Device, all bool) []string {
+	lines := []string{}
+	for _, model := range device.Models {
+		name := model.Name
+		if name == "" {
+			name = "(not decoded)"
+		}
+		lines = append(lines, titleStyle.Render(fmt.Sprintf("Model %d %s", model.ID, name))+
+			dimStyle.Render(fmt.Sprintf("  @%d len %d", model.Address, model.Length)))
+		if model.Error != "" {
+			lines = append(lines, "  "+errorStyle.Render(model.Error))
+		}
+		for _, point := range model.Points {
+			if point.Value == nil && !all {
+				continue
+			}
+			value := point.Text
+			if point.Value == nil {
+				value = dimStyle.Render("n/a")
+			} else if point.Unit != "" {
+				value += " " + point.Unit
+			}
+			lines = append(lines, fmt.Sprintf("  %-14s %s", point.Name, value))
+		}
+	}
+	return lines
+}
@@ -15,6 +15,16 @@ const logBufferSize = 500
 // Run starts the TUI and blocks until it exits.
 func Run(cfg config.Config) error {
 	service := core.NewServiceWithLogSize(cfg, logBufferSize)
+	if cfg.LogFile.Path != "" {
+		if err := service.OpenLogFile(cfg.LogFile); err != nil {
+			return err
+		}
+	}
+	if cfg.CapturePath != "" {
+		if err := service.StartCapture(cfg.CapturePath); err != nil {
+			return err
+		}
+	}
 
 	state := newModel(cfg, service)
 	program := tea.NewProgram(state, tea.WithAltScreen())
@@ -35,10 +45,20 @@ func Run(cfg config.Config) error {
 
 	final, err := program.Run()
 	_ = service.Disconnect()
+	_ = service.StopCapture()
+	_ = service.CloseLogFile()
 	if err != nil {
 		return err
 	}
-	if m, ok := final.(model); ok && m.printInvocation {
+	m, ok := final.(model)
+	if ok && m.cfg.AutoSave {
+		path, err := config.Save(m.cfg)
+		if err != nil {
+			return fmt.Errorf("save config: %w", err)
+		}
+		fmt.Println("Saved config to", path)
+	}
+	if ok && m.printInvocation {
 		connectionOnly := m.cfg.InvocationTUI()
 		fmt.Println(connectionOnly)
 		full := m.cfg.InvocationFullTUI()
@@ -1,10 +1,11 @@
 package tui
 
 import (
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -55,6 +56,9 @@ func renderConnectionSummary(m model) string {
 		fmt.Sprintf("timeout %dms", m.cfg.TimeoutMs),
 		fmt.Sprintf("auto %s", onOff(m.autoConnect)),
 	}
+	if m.capture.Active {
+		parts = append(parts, fmt.Sprintf("capture %d pkts", m.capture.Packets))
+	}
 	return strings.Join(parts, " | ")
 }
 
@@ -161,21 +165,41 @@ func renderLogs(m model) string {
 
 func renderLogBody(m model) string {
 	var b strings.Builder
-	visible := m.height - 6
+	logs := core.FilterLogs(m.logs, m.logQuery())
+	b.WriteString(renderLogFilters(m, len(logs)) + "\n")
+	visible := m.height - 7
 	if visible < 1 {
-		visible = len(m.logs)
+		visible = len(logs)
 	}
 	start := 0
-	if visible > 0 && len(m.logs) > visible {
-		start = len(m.logs) - visible
+	if visible > 0 && len(logs) > visible {
+		start = len(logs) - visible
 	}
 
-	for _, entry := range m.logs[start:] {
+	for _, entry := range logs[start:] {
 		b.WriteString(fmt.Sprintf("%s %s\n", formatTime(entry.Time), entry.Message))
 	}
 	return b.String()
 }
 
+func renderLogFilters(m model, matched int) string {
+	direction := logDirections[m.logDirectionIdx]
+	if direction == "" {
+		direction = "all"
+	}
+	window := "all"
+	if w := logWindows[m.logWindowIdx]; w > 0 {
+		window = "last " + w.String()
+	}
+	parts := []string{
+		fmt.Sprintf("[f]ilter: %s", direction),
+		fmt.Sprintf("ti[m]e: %s", window),
+		renderEditableField(m, focusLogSearch, "[/] search", m.logSearch),
+		fmt.Sprintf("%d/%d", matched, len(m.logs)),
+	}
+	return dimStyle.Render(strings.Join(parts, " | "))
+}
+
 func renderHelp(m model) string {
 	lines := []string{
 		"Help (press [?] or [esc] to return)",
@@ -195,9 +219,12 @@ func renderHelp(m model) string {
 		"  [f] Function select",
 		"  [r] Read now",
 		"  [c] Connect/disconnect",
+		"  [p] Start/stop pcapng capture",
 		"  [s] Connection settings",
 		"  [d] Decoder settings",
 		"  [l] Raw logs",
+		"  [w] Write config file",
+		"  s[u]nspec model discovery",
 		"  [q] Quit (prints invocations)",
 	}
 	box := renderBox("help", strings.Join(lines, "\n"), m.width)
@@ -206,7 +233,18 @@ func renderHelp(m model) string {
 
 func renderDecoder(m model) string {
 	var b strings.Builder
-	b.WriteString("Keys: [j]/[k] move  [space] toggle  [e] endianness  [w] word order\n\n")
+	b.WriteString("Keys: [j]/[k] move  [space] toggle  [e] endianness  [w] word order  [o] byte order\n")
+	b.WriteString("Detect: [g] try all byte orders on the last read against an expected value\n")
+	b.WriteString("String: [+]/[-] registers  [t] trim  [c] encoding (le swaps bytes)\n")
+	b.WriteString("Packed: [+]/[-] bits  [b] LSB/MSB first\n")
+	b.WriteString("Encode: [n] show the registers a value encodes to with the selected decoder\n")
+	b.WriteString("Time: [z] timezone  [m] epoch milliseconds  [+]/[-] datetime registers (6) or bytes (3)\n")
+	b.WriteString("Assign: [a] pin selected decoder to ADDR[:COUNT]  [x] unpin address\n")
+	if m.editActive && (m.editField == focusAssign || m.editField == focusUnassign || m.editField == focusDetectOrder || m.editField == focusTimezone ||
+		m.editField == focusEncode) {
+		b.WriteString(renderEditableField(m, m.editField, fieldLabel(m.editField), "") + "\n")
+	}
+	b.WriteString("\n")
 
 	for idx, dec := range m.cfg.Decoders {
 		cursor := " "
@@ -217,20 +255,126 @@ func renderDecoder(m model) string {
 		if dec.Enabled {
 			enabled = "x"
 		}
-		line := fmt.Sprintf("%s [%s] %-8s endian=%-6s word=%s", cursor, enabled, dec.Type, string(dec.Endianness), string(dec.WordOrder))
+		line := fmt.Sprintf("%s [%s] %-8s endian=%-6s word=%-10s %s", cursor, enabled, dec.Type, string(dec.Endianness), string(dec.WordOrder), dec.ByteOrder())
+		if dec.Type == config.DecoderString {
+			line = fmt.Sprintf("%s [%s] %-8s endian=%-6s regs=%d trim=%s enc=%s", cursor, enabled, dec.Type, string(dec.Endianness), dec.Registers, dec.Trim, dec.Encoding)
+		}
+		if dec.Type == config.DecoderBits && len(dec.BitNames) > 0 {
+			line = fmt.Sprintf("%s [%s] %-8s endian=%-6s bits=%s", cursor, enabled, dec.Type, string(dec.Endianness), config.FormatLabels(dec.BitNames))
+		}
+		if dec.Type == config.DecoderEnum && len(dec.Labels) > 0 {
+			line = fmt.Sprintf("%s [%s] %-8s endian=%-6s labels=%s", cursor, enabled, dec.Type, string(dec.Endianness), config.FormatLabels(dec.Labels))
+		}
+		if dec.Type == config.DecoderQ16 || dec.Type == config.DecoderQ32 {
+			line += fmt.Sprintf("  n=%d", dec.FracBits)
+			if dec.Unsigned {
+				line += " unsigned"
+			}
+		}
+		if dec.Type == config.DecoderPacked {
+			line = fmt.Sprintf("%s [%s] %-8s bits=%d %s first (coils/discrete inputs)", cursor, enabled, dec.Type, dec.Bits, bitOrderName(dec))
+		}
+		if isTimestamp(dec.Type) {
+			line += "  " + timestampSummary(dec)
+		}
+		if core.HasScaling(dec) {
+			line += "  " + scalingSummary(dec)
+		}
 		if idx == m.decoderCursor {
 			line = selectedStyle.Render(line)
 		}
 		b.WriteString(line + "\n")
 	}
 
+	if m.detectMessage != "" {
+		b.WriteString("\n" + m.detectMessage + "\n")
+		for _, candidate := range m.detectCandidates {
+			b.WriteString(fmt.Sprintf("  %s at +%d = %s (Δ %s)\n", candidate.Order, candidate.Offset,
+				strconv.FormatFloat(candidate.Value, 'g', 8, 64), strconv.FormatFloat(candidate.Delta, 'g', 4, 64)))
+		}
+	}
+	if m.encodeMessage != "" {
+		b.WriteString("\n" + m.encodeMessage + "\n")
+	}
+	if len(m.cfg.Assignments) > 0 {
+		b.WriteString("\nAssigned:\n")
+		if m.cfg.MapFile != "" {
+			b.WriteString(dimStyle.Render("  map: "+m.cfg.MapFile) + "\n")
+		}
+		for _, assignment := range m.cfg.Assignments {
+			target := formatAddress(int(assignment.Address), m.cfg.AddressFormat)
+			if assignment.Count > 1 {
+				target = fmt.Sprintf("%s ×%d", target, assignment.Count)
+			}
+			code := "  "
+			if assignment.Kind != "" {
+				code = readKinds[readKindIndex(assignment.Kind)].code
+			}
+			line := fmt.Sprintf("  %s %-12s %-8s", code, target, assignment.Decoder.Type)
+			if assignment.Name != "" {
+				line += " " + assignment.Name
+			}
+			if assignment.Description != "" {
+				line += dimStyle.Render("  " + assignment.Description)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
 	box := renderBox("decoders", b.String(), m.width)
 	return renderScreen(m, box)
 }
 
+func bitOrderName(dec config.DecoderConfig) string {
+	if dec.MSBFirst {
+		return "MSB"
+	}
+	return "LSB"
+}
+
+func timestampSummary(dec config.DecoderConfig) string {
+	parts := []string{}
+	if dec.Type == config.DecoderDate {
+		parts = append(parts, fmt.Sprintf("regs=%d", core.RegisterSpan(dec)))
+	}
+	if dec.Millis {
+		parts = append(parts, "ms")
+	}
+	zone := dec.Timezone
+	if zone == "" {
+		zone = "UTC"
+	}
+	return strings.Join(append(parts, "tz="+zone), " ")
+}
+
+func scalingSummary(dec config.DecoderConfig) string {
+	parts := []string{}
+	if dec.Scale != 0 && dec.Scale != 1 {
+		parts = append(parts, "×"+strconv.FormatFloat(dec.Scale, 'g', -1, 64))
+	}
+	if dec.ScaleFactor != nil {
+		parts = append(parts, fmt.Sprintf("×10^[%d]", *dec.ScaleFactor))
+	}
+	if dec.Offset != 0 {
+		parts = append(parts, fmt.Sprintf("%+g", dec.Offset))
+	}
+	if dec.Decimals != nil {
+		parts = append(parts, fmt.Sprintf("dec=%d", *dec.Decimals))
+	}
+	if dec.Unit != "" {
+		parts = append(parts, dec.Unit)
+	}
+	return strings.Join(parts, " ")
+}
+
 func renderConnectionDetails(m model) string {
+	profile := m.cfg.Profile
+	if profile == "" {
+		profile = "none"
+	}
 	lines := []string{
 		fmt.Sprintf("[p]rotocol: %s", strings.ToUpper(string(m.cfg.Protocol))),
+		fmt.Sprintf("pr[o]file: %s", profile),
 	}
 	if m.cfg.Protocol == config.ProtocolTCP {
 		lines = append(lines,
@@ -290,6 +434,9 @@ func renderStatusClue(m model) string {
 			clue = fmt.Sprintf("%s | prefix with 0x for hex", clue)
 		}
 	}
+	if m.configMessage != "" {
+		clue = m.configMessage
+	}
 	if err := footerError(m); err != "" {
 		clue = errorStyle.Render(err)
 	}
@@ -310,12 +457,18 @@ func renderCommandsLine(m model) string {
 		return "[enter] select  [esc] back"
 	case viewDeviceSelect:
 		return "[enter] select  [esc] back"
+	case viewProfileSelect:
+		return "[enter] apply  [n] save as  [x] delete  [j]/[k] move  [esc] back"
 	case viewDecoder:
-		return "[space] toggle  [e] endianness  [w] word order  [j]/[k] move  [esc] back"
-	case viewLogs, viewHelp:
+		return "[space] toggle  [e]/[w]/[o] byte order  [g] detect  [a] assign  [x] unassign  [j]/[k] move  [esc] back"
+	case viewLogs:
+		return "[f] direction  [m] time window  [/] search  [x] clear  [esc] back"
+	case viewHelp:
 		return "[esc] back"
+	case viewSunSpec:
+		return "[r] rediscover  [h] unimplemented  [j]/[k] scroll  [esc] back"
 	default:
-		return "[r] read  [c] connect  [d] decoders  [l] logs  [?] help  [q] quit"
+		return "[r] read  [c] connect  [p] capture  [d] decoders  [l] logs  [?] help  [q] quit"
 	}
 }
 
@@ -394,6 +547,20 @@ func fieldLabel(field fieldFocus) string {
 		return "timeout"
 	case focusConnUnitID:
 		return "unit-id"
+	case focusLogSearch:
+		return "log search"
+	case focusAssign:
+		return "assign at"
+	case focusUnassign:
+		return "unassign at"
+	case focusDetectOrder:
+		return "expected value"
+	case focusTimezone:
+		return "timezone"
+	case focusEncode:
+		return "encode value"
+	case focusProfileName:
+		return "profile name"
 	default:
 		return "field"
 	}
@@ -477,6 +644,15 @@ func buildRows(m model, columns int) []tableRow {
 		}
 	}
 
+	var assigned []core.AddressValue
+	assignments := core.AssignmentsFor(result.Kind, m.cfg.Assignments)
+	if len(result.RegValues) > 0 && len(assignments) > 0 {
+		assigned = core.DecodeAssignments(result.Address, result.RegValues, assignments)
+	}
+	if len(result.BoolValues) > 0 && len(assignments) > 0 {
+		assigned = core.DecodeBoolAssignments(result.Address, result.BoolValues, assignments)
+	}
+
 	rows := []tableRow{}
 	for offset := 0; offset < len(values); offset += columns {
 		slice := values[offset:min(offset+columns, len(values))]
@@ -490,19 +666,30 @@ func buildRows(m model, columns int) []tableRow {
 		}
 		rows = append(rows, row)
 
-		if len(result.RegValues) == 0 {
-			continue
-		}
+		rows = append(rows, assignedRows(assigned, result.Address, offset, min(offset+columns, len(values)))...)
 
 		decoders := enabledDecoders(m.cfg.Decoders)
 		if len(decoders) == 0 {
 			continue
 		}
-		regSlice := result.RegValues[offset:min(offset+columns, len(result.RegValues))]
 		for _, decoder := range decoders {
+			if (decoder.Type == config.DecoderPacked) != (len(result.RegValues) == 0) {
+				continue
+			}
+			if decoder.Type == config.DecoderPacked {
+				rows = append(rows, tableRow{
+					label: "↳ " + string(decoder.Type),
+					cells: packedRow(result.BoolValues, offset, columns, decoder),
+				})
+				continue
+			}
+			if decoder.Type == config.DecoderBits {
+				rows = append(rows, bitfieldRows(m, offset, columns, decoder)...)
+				continue
+			}
 			rows = append(rows, tableRow{
 				label: "↳ " + string(decoder.Type),
-				cells: decodeRegisters(regSlice, decoder, columns),
+				cells: decodeRow(result.Address, result.RegValues, offset, columns, decoder),
 			})
 		}
 	}
@@ -516,7 +703,22 @@ func enabledDecoders(decoders []config.DecoderConfig) []config.DecoderConfig {
 		config.DecoderInt16:   1,
 		config.DecoderUint32:  2,
 		config.DecoderInt32:   3,
-		config.DecoderFloat32: 4,
+		config.DecoderFloat16: 4,
+		config.DecoderFloat32: 5,
+		config.DecoderUint64:  6,
+		config.DecoderInt64:   7,
+		config.DecoderFloat64: 8,
+		config.DecoderQ16:     9,
+		config.DecoderQ32:     10,
+		config.DecoderBCD16:   11,
+		config.DecoderBCD32:   12,
+		config.DecoderBits:    13,
+		config.DecoderEnum:    14,
+		config.DecoderEpoch32: 15,
+		config.DecoderEpoch64: 16,
+		config.DecoderDate:    17,
+		config.DecoderString:  18,
+		config.DecoderPacked:  19,
 	}
 	enabled := []config.DecoderConfig{}
 	for _, decoder := range decoders {
@@ -530,62 +732,162 @@ func enabledDecoders(decoders []config.DecoderConfig) []config.DecoderConfig {
 	return enabled
 }
 
-func decodeRegisters(regs []uint16, decoder config.DecoderConfig, columns int) []tableCell {
-	switch decoder.Type {
-	case config.DecoderUint16:
-		return mapCells(regs, func(v uint16) string { return formatValue(int(v), 10) })
-	case config.DecoderInt16:
-		return mapCells(regs, func(v uint16) string { return fmt.Sprintf("%d", toInt16(v)) })
-	case config.DecoderUint32, config.DecoderInt32, config.DecoderFloat32:
-		cells := []tableCell{}
-		for i := 0; i+1 < len(regs); i += 2 {
-			high := regs[i]
-			low := regs[i+1]
-			first, second := high, low
-			if decoder.WordOrder == config.WordLowFirst {
-				first, second = low, high
-			}
-			var bytes []byte
-			if decoder.Endianness == config.EndianLittle {
-				bytes = []byte{byte(first & 0xff), byte(first >> 8), byte(second & 0xff), byte(second >> 8)}
-			} else {
-				bytes = []byte{byte(first >> 8), byte(first & 0xff), byte(second >> 8), byte(second & 0xff)}
+// decodeRow renders the values of one decoder that start within the row
+// [offset, offset+columns). Values are aligned to the decoder span from the
+// start of the block; a value running past the row end is clipped to it.
+func decodeRow(address uint16, regs []uint16, offset, columns int, decoder config.DecoderConfig) []tableCell {
+	end := min(offset+columns, len(regs))
+	span := core.RegisterSpan(decoder)
+	cells := []tableCell{}
+	start := (offset + span - 1) / span * span
+	if start > offset {
+		cells = append(cells, tableCell{value: "", colSpan: min(start, end) - offset})
+	}
+	for i := start; i < end; i += span {
+		value, err := core.DecodeAt(regs[i:], decoder)
+		if errors.Is(err, core.ErrShortRegisters) {
+			cells = append(cells, tableCell{value: "—", colSpan: end - i})
+			break
+		}
+		if err == nil {
+			value, err = core.ApplyScaling(value, decoder, address, regs)
+		}
+		if errors.Is(err, core.ErrScaleFactor) {
+			cells = append(cells, tableCell{value: "no SF", colSpan: min(span, end-i)})
+			continue
+		}
+		if err != nil {
+			cells = append(cells, tableCell{value: "invalid", colSpan: min(span, end-i)})
+			continue
+		}
+		cells = append(cells, tableCell{value: formatDecoded(value), colSpan: min(span, end-i)})
+	}
+	if len(cells) == 0 {
+		return []tableCell{{value: "—", colSpan: columns}}
+	}
+	return cells
+}
+
+// packedRow is decodeRow for coil and discrete-input reads.
+func packedRow(bools []bool, offset, columns int, decoder config.DecoderConfig) []tableCell {
+	end := min(offset+columns, len(bools))
+	span := core.RegisterSpan(decoder)
+	cells := []tableCell{}
+	start := (offset + span - 1) / span * span
+	if start > offset {
+		cells = append(cells, tableCell{value: "", colSpan: min(start, end) - offset})
+	}
+	for i := start; i < end; i += span {
+		value, err := core.DecodeBoolsAt(bools[i:], decoder)
+		if err != nil {
+			cells = append(cells, tableCell{value: "—", colSpan: end - i})
+			break
+		}
+		cells = append(cells, tableCell{value: formatDecoded(value), colSpan: min(span, end-i)})
+	}
+	if len(cells) == 0 {
+		return []tableCell{{value: "—", colSpan: columns}}
+	}
+	return cells
+}
+
+// assignedRows renders the type and value of every assigned value touching
+// the row [offset, end). Continuations of a value started in an earlier row
+// are left blank, like decodeRow.
+func assignedRows(values []core.AddressValue, address uint16, offset, end int) []tableRow {
+	types := []tableCell{}
+	decoded := []tableCell{}
+	touched := false
+	for pos := offset; pos < end; {
+		value, start, ok := assignedAt(values, address, pos)
+		if !ok {
+			types = append(types, tableCell{value: "", colSpan: 1})
+			decoded = append(decoded, tableCell{value: "", colSpan: 1})
+			pos++
+			continue
+		}
+		touched = true
+		span := min(start+value.Span, end) - pos
+		if start < pos {
+			types = append(types, tableCell{value: "", colSpan: span})
+			decoded = append(decoded, tableCell{value: "", colSpan: span})
+		} else {
+			label := string(value.Type)
+			if value.Name != "" {
+				label = value.Name
 			}
-			value := decodeBytes(bytes, decoder.Type)
-			cells = append(cells, tableCell{value: value, colSpan: 2})
+			types = append(types, tableCell{value: label, colSpan: span})
+			decoded = append(decoded, tableCell{value: formatAssigned(value), colSpan: span})
 		}
-		if len(cells) == 0 {
-			return []tableCell{{value: "—", colSpan: columns}}
+		pos += span
+	}
+	if !touched {
+		return nil
+	}
+	return []tableRow{
+		{label: "↳ point", cells: types},
+		{label: "↳ value", cells: decoded},
+	}
+}
+
+// assignedAt finds the value covering block offset pos and its start offset.
+func assignedAt(values []core.AddressValue, address uint16, pos int) (core.AddressValue, int, bool) {
+	for _, value := range values {
+		start := int(value.Address) - int(address)
+		if start <= pos && pos < start+value.Span {
+			return value, start, true
 		}
-		return cells
-	default:
-		return mapCells(regs, func(v uint16) string { return fmt.Sprintf("%d", v) })
 	}
+	return core.AddressValue{}, 0, false
 }
 
-func decodeBytes(bytes []byte, decoderType config.DecoderType) string {
-	if len(bytes) < 4 {
+func formatAssigned(value core.AddressValue) string {
+	switch {
+	case value.Error == core.ErrShortRegisters.Error():
 		return "—"
+	case strings.HasPrefix(value.Error, core.ErrScaleFactor.Error()):
+		return "no SF"
+	case value.Error != "":
+		return "invalid"
+	}
+	if field, ok := value.Value.(core.Bitfield); ok {
+		if len(field.Flags) == 0 {
+			return fmt.Sprintf("0x%04X", field.Raw)
+		}
+		return strings.Join(field.Flags, ",")
+	}
+	return formatDecoded(value.Value)
+}
+
+// bitfieldRows renders one row per register of the block, each spanning the
+// full table width so the bit grid and flag names fit.
+func bitfieldRows(m model, offset, columns int, decoder config.DecoderConfig) []tableRow {
+	regs := m.lastResult.RegValues
+	end := min(offset+columns, len(regs))
+	rows := make([]tableRow, 0, end-offset)
+	for i := offset; i < end; i++ {
+		value, err := core.DecodeAt(regs[i:], decoder)
+		cell := tableCell{value: "invalid", colSpan: columns}
+		if err == nil {
+			cell.value = formatDecoded(value)
+		}
+		rows = append(rows, tableRow{
+			label: "↳ " + formatAddress(int(m.lastResult.Address)+i, m.cfg.AddressFormat),
+			cells: []tableCell{cell},
+		})
 	}
-	value := binary.BigEndian.Uint32(bytes)
-	switch decoderType {
-	case config.DecoderUint32:
-		return fmt.Sprintf("%d", value)
-	case config.DecoderInt32:
-		return fmt.Sprintf("%d", int32(value))
-	case config.DecoderFloat32:
-		return formatFloat(math.Float32frombits(value))
-	default:
-		return fmt.Sprintf("%d", value)
-	}
+	return rows
 }
 
-func mapCells(regs []uint16, fn func(uint16) string) []tableCell {
-	cells := make([]tableCell, 0, len(regs))
-	for _, value := range regs {
-		cells = append(cells, tableCell{value: fn(value), colSpan: 1})
+func formatDecoded(value interface{}) string {
+	switch v := value.(type) {
+	case float32:
+		return formatFloat(float64(v))
+	case float64:
+		return formatFloat(v)
+	default:
+		return fmt.Sprintf("%v", v)
 	}
-	return cells
 }
 
 func renderHeaderCells(columns, cellWidth int) string {
@@ -599,7 +901,7 @@ func renderHeaderCells(columns, cellWidth int) string {
 func renderRow(row tableRow, labelWidth, cellWidth int) string {
 	cells := make([]string, 0, len(row.cells))
 	for _, cell := range row.cells {
-		width := cellWidth * cell.colSpan
+		width := cellWidth*cell.colSpan + cell.colSpan - 1
 		cells = append(cells, col(cell.value, width))
 	}
 	return fmt.Sprintf("%s %s", col(row.label, labelWidth), strings.Join(cells, " "))
@@ -619,26 +921,18 @@ func formatValue(value int, base config.ValueBase) string {
 	return fmt.Sprintf("%d", value)
 }
 
-func toInt16(value uint16) int16 {
-	masked := int32(value & 0xffff)
-	if masked&0x8000 != 0 {
-		return int16(masked - 0x10000)
-	}
-	return int16(masked)
-}
-
-func formatFloat(value float32) string {
+func formatFloat(value float64) string {
 	if !isFinite(value) {
 		return fmt.Sprintf("%v", value)
 	}
-	if math.Abs(float64(value)) >= 1e21 {
+	if math.Abs(value) >= 1e21 {
 		return fmt.Sprintf("%.3e", value)
 	}
 	return fmt.Sprintf("%.3f", value)
 }
 
-func isFinite(value float32) bool {
-	return !math.IsNaN(float64(value)) && !math.IsInf(float64(value), 0)
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
 }
 
 func clamp(value string, width int) string {
@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gomodmaster/internal/config"
+	"gomodmaster/internal/regmap"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrImportCancelled is returned when the import wizard is left with esc.
+var ErrImportCancelled = errors.New("import cancelled")
+
+const (
+	stepAddresses = "addresses"
+	stepScale     = "scale"
+	stepPreview   = "preview"
+)
+
+var addressStyles = []string{regmap.AddressAuto, regmap.AddressModicon, regmap.AddressPlain}
+
+// RunImportWizard asks which vendor column holds each point field, starting
+// from mapping (columns not set there are guessed from the header), and
+// returns the mapping once the preview is accepted.
+func RunImportWizard(table regmap.Table, mapping regmap.Mapping, base config.AddressBase) (regmap.Mapping, error) {
+	if mapping.Columns == nil {
+		mapping.Columns = map[string]string{}
+	}
+	for field, column := range regmap.GuessColumns(table.Header) {
+		if _, ok := mapping.Columns[field]; !ok {
+			mapping.Columns[field] = column
+		}
+	}
+	if mapping.Addresses == "" {
+		mapping.Addresses = regmap.AddressAuto
+	}
+	wizard := wizardModel{table: table, mapping: mapping, base: base}
+	wizard.cursor = wizard.selected()
+	final, err := tea.NewProgram(wizard, tea.WithAltScreen()).Run()
+	if err != nil {
+		return regmap.Mapping{}, err
+	}
+	result := final.(wizardModel)
+	if !result.done {
+		return regmap.Mapping{}, ErrImportCancelled
+	}
+	return result.mapping, nil
+}
+
+type wizardModel struct {
+	table   regmap.Table
+	mapping regmap.Mapping
+	base    config.AddressBase
+	step    int
+	cursor  int
+	height  int
+	done    bool
+}
+
+func (w wizardModel) steps() []string {
+	steps := append([]string{}, regmap.ImportFields...)
+	steps = append(steps, stepAddresses)
+	if w.mapping.Columns["scale"] != "" {
+		steps = append(steps, stepScale)
+	}
+	return append(steps, stepPreview)
+}
+
+func (w wizardModel) current() string {
+	return w.steps()[w.step]
+}
+
+// options lists the choices of the current step; column steps start with
+// "(none)".
+func (w wizardModel) options() []string {
+	switch w.current() {
+	case stepAddresses:
+		return addressStyles
+	case stepScale:
+		return []string{"multiply (0.1 means ÷10)", "divide (10 means ÷10)"}
+	case stepPreview:
+		return nil
+	}
+	return append([]string{"(none)"}, w.table.Header...)
+}
+
+// selected is the option index matching the current mapping.
+func (w wizardModel) selected() int {
+	switch w.current() {
+	case stepAddresses:
+		for i, style := range addressStyles {
+			if style == w.mapping.Addresses {
+				return i
+			}
+		}
+		return 0
+	case stepScale:
+		if w.mapping.DivideScale {
+			return 1
+		}
+		return 0
+	case stepPreview:
+		return 0
+	}
+	column := w.mapping.Columns[w.current()]
+	for i, name := range w.table.Header {
+		if column != "" && strings.EqualFold(strings.TrimSpace(name), column) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (w *wizardModel) apply() {
+	switch w.current() {
+	case stepAddresses:
+		w.mapping.Addresses = addressStyles[w.cursor]
+	case stepScale:
+		w.mapping.DivideScale = w.cursor == 1
+	case stepPreview:
+	default:
+		if w.cursor == 0 {
+			delete(w.mapping.Columns, w.current())
+		} else {
+			w.mapping.Columns[w.current()] = strings.TrimSpace(w.table.Header[w.cursor-1])
+		}
+	}
+}
+
+func (w wizardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (w wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		w.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return w, tea.Quit
+		case "up", "k":
+			if w.cursor > 0 {
+				w.cursor--
+			}
+		case "down", "j":
+			if w.cursor < len(w.options())-1 {
+				w.cursor++
+			}
+		case "enter", "right", "tab":
+			if w.current() == stepPreview {
+				if _, _, err := regmap.ImportTable(w.table, w.mapping, w.base); err == nil && msg.String() == "enter" {
+					w.done = true
+					return w, tea.Quit
+				}
+				return w, nil
+			}
+			w.apply()
+			w.step++
+			w.cursor = w.selected()
+		case "left", "backspace", "shift+tab":
+			if w.step > 0 {
+				w.step--
+				w.cursor = w.selected()
+			}
+		}
+	}
+	return w, nil
+}
+
+func (w wizardModel) View() string {
+	var b strings.Builder
+	steps := w.steps()
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Import register list — step %d/%d: %s", w.step+1, len(steps), w.current())))
+	b.WriteString("\n\n")
+	if w.current() == stepPreview {
+		b.WriteString(w.previewView())
+	} else {
+		b.WriteString(w.optionsView())
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render("↑/↓ select  enter next  ← back  esc cancel"))
+	return b.String()
+}
+
+func (w wizardModel) optionsView() string {
+	var b strings.Builder
+	options := w.options()
+	visible := len(options)
+	if w.height > 8 {
+		visible = min(visible, w.height-6)
+	}
+	first := min(max(w.cursor-visible/2, 0), len(options)-visible)
+	for i := first; i < first+visible; i++ {
+		line := options[i]
+		if w.current() != stepAddresses && w.current() != stepScale && i > 0 {
+			line = fmt.Sprintf("%-24s %s", line, dimStyle.Render(w.samples(i-1)))
+		}
+		if i == w.cursor {
+			b.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// samples shows the first few non-empty values of a column.
+func (w wizardModel) samples(column int) string {
+	values := []string{}
+	for _, row := range w.table.Rows {
+		if column < len(row) && strings.TrimSpace(row[column]) != "" {
+			values = append(values, strings.TrimSpace(row[column]))
+		}
+		if len(values) == 3 {
+			break
+		}
+	}
+	return truncate(strings.Join(values, ", "), 48)
+}
+
+func (w wizardModel) previewView() string {
+	m, warnings, err := regmap.ImportTable(w.table, w.mapping, w.base)
+	if err != nil {
+		return errorStyle.Render(err.Error()) + "\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d points, %d rows skipped\n\n", len(m.Points), len(warnings))
+	limit := 10
+	if w.height > 16 {
+		limit = w.height - 12
+	}
+	for _, point := range m.Points[:min(limit, len(m.Points))] {
+		fmt.Fprintf(&b, "  %s %-6d %-8s %s\n", readKinds[readKindIndex(point.Kind)].code, point.Address, point.Type, point.Name)
+	}
+	for _, warning := range warnings[:min(3, len(warnings))] {
+		b.WriteString(dimStyle.Render("  "+warning) + "\n")
+	}
+	return b.String()
+}
+
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "…"
+}
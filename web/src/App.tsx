@@ -1,10 +1,10 @@
 import { useCallback, useEffect, useRef, useState } from 'react'
 import AppLayout from './components/AppLayout'
 import UnauthorizedPanel from './components/UnauthorizedPanel'
-import { apiPost, buildJsonHeaders, fetchJson } from './lib/api'
+import { ApiError, apiPost, buildJsonHeaders, fetchJson } from './lib/api'
 import { parseAddress } from './lib/parse'
-import type { Config } from './types'
-import type { LogEntry, ReadKind, ReadResult, Stats, WsEvent } from './view-models'
+import type { Assignment, Config, DecoderConfig, FieldError, ProfilesResponse } from './types'
+import type { ByteOrderCandidate, CaptureStatus, LogEntry, LogPage, ReadKind, ReadResult, Stats, WsEvent } from './view-models'
 
 type ConfigResponse = {
   config: Config
@@ -32,12 +32,14 @@ function App() {
   const [stats, setStats] = useState<Stats>({ readCount: 0, errorCount: 0, lastLatencyMs: 0 })
   const [connected, setConnected] = useState(false)
   const [connecting, setConnecting] = useState(false)
+  const [capture, setCapture] = useState<CaptureStatus>({ active: false, packets: 0 })
   const [autoConnect, setAutoConnect] = useState(true)
   const [columns, setColumns] = useState(8)
   const [version, setVersion] = useState('')
   const [connectionError, setConnectionError] = useState('')
   const [addressError, setAddressError] = useState('')
   const [quantityError, setQuantityError] = useState('')
+  const [configErrors, setConfigErrors] = useState<FieldError[]>([])
   const [showLogs, setShowLogs] = useState(false)
   const [pendingRead, setPendingRead] = useState<PendingRead | null>(null)
   const [authBlocked, setAuthBlocked] = useState(() => window.location.hash === '#/401')
@@ -83,6 +85,19 @@ function App() {
       .then((data: Stats) => setStats(data))
       .catch(() => undefined)
 
+    fetchJson<LogPage>('/api/logs?tail=true&limit=500', { headers }, handleUnauthorized)
+      .then((data: LogPage) =>
+        setLogs((prev) => {
+          const lastSeq = data.entries.length ? data.entries[data.entries.length - 1].seq : 0
+          return [...data.entries, ...prev.filter((entry) => entry.seq > lastSeq)].slice(-500)
+        }),
+      )
+      .catch(() => undefined)
+
+    fetchJson<CaptureStatus>('/api/capture', { headers }, handleUnauthorized)
+      .then((data: CaptureStatus) => setCapture(data))
+      .catch(() => undefined)
+
     fetchJson<{ version: string }>('/api/version', { headers }, handleUnauthorized).then((data) =>
       setVersion(data.version),
     )
@@ -112,6 +127,9 @@ function App() {
         const result = payload.payload as ReadResult
         setLastResult(result)
       }
+      if (payload.type === 'capture') {
+        setCapture(payload.payload as CaptureStatus)
+      }
       if (payload.type === 'status') {
         const status = payload.payload as { connected: boolean; connecting: boolean; lastError?: string }
         const isConnected = Boolean(status.connected)
@@ -145,15 +163,26 @@ function App() {
         setConfig(data.config)
         setInvocation(data.invocation)
         setInvocationFull(data.invocationFull)
+        setConfigErrors([])
         if (reconnect) {
           return apiPost('/api/disconnect', token, handleUnauthorized).then(() =>
             apiPost('/api/connect', token, handleUnauthorized),
           )
         }
       })
-      .catch(() => undefined)
+      .catch((err: Error) => {
+        if (err instanceof ApiError && err.fields.length > 0) {
+          setConfigErrors(err.fields)
+        }
+      })
   }
 
+  const handleSaveConfigFile = () =>
+    apiPost('/api/config/save', token, handleUnauthorized).then((data: { path: string }) => {
+      setConfig((prev) => (prev ? { ...prev, configFile: data.path } : prev))
+      return data.path
+    })
+
   const handleConnect = () => {
     apiPost('/api/connect', token, handleUnauthorized).catch(() => undefined)
   }
@@ -162,6 +191,26 @@ function App() {
     apiPost('/api/disconnect', token, handleUnauthorized).catch(() => undefined)
   }
 
+  const handleStartCapture = () => {
+    fetchJson<CaptureStatus>(
+      '/api/capture/start',
+      {
+        method: 'POST',
+        headers: buildJsonHeaders(token),
+        body: JSON.stringify({}),
+      },
+      handleUnauthorized,
+    )
+      .then((data: CaptureStatus) => setCapture(data))
+      .catch(() => undefined)
+  }
+
+  const handleStopCapture = () => {
+    apiPost('/api/capture/stop', token, handleUnauthorized)
+      .then((data: CaptureStatus) => setCapture(data))
+      .catch(() => undefined)
+  }
+
   const runRead = useCallback(
     (payload: PendingRead) => {
       const headers = buildJsonHeaders(token)
@@ -227,7 +276,7 @@ function App() {
     runRead(payload)
   }
 
-  const updateDecoder = (nextDecoder: { type: string; endianness: string; wordOrder: string; enabled: boolean }) => {
+  const updateDecoder = (nextDecoder: DecoderConfig) => {
     if (!config) return
     const index = config.decoders.findIndex((decoder) => decoder.type === nextDecoder.type)
     const decoders =
@@ -241,6 +290,92 @@ function App() {
     updateConfig(next, false)
   }
 
+  const updateAssignments = (assignments: Assignment[]) => {
+    if (!config) return
+    updateConfig({ ...config, assignments }, false)
+  }
+
+  const detectByteOrder = (type: string, expected: number) =>
+    fetchJson<{ candidates: ByteOrderCandidate[] }>(
+      '/api/byte-order/detect',
+      {
+        method: 'POST',
+        headers: buildJsonHeaders(token),
+        body: JSON.stringify({
+          type,
+          decoder: config?.decoders.find((decoder) => decoder.type === type),
+          expected,
+          registers: lastResult?.regValues ?? [],
+        }),
+      },
+      handleUnauthorized,
+    ).then((data) => data.candidates)
+
+  const encodeValue = (decoder: DecoderConfig, value: string) =>
+    fetchJson<{ registers: number[] }>(
+      '/api/encode',
+      {
+        method: 'POST',
+        headers: buildJsonHeaders(token),
+        body: JSON.stringify({
+          decoder,
+          value,
+          address: lastResult?.address ?? 0,
+          registers: lastResult?.regValues ?? [],
+        }),
+      },
+      handleUnauthorized,
+    ).then((data) => data.registers)
+
+  const listProfiles = useCallback(
+    () =>
+      fetchJson<ProfilesResponse>(
+        '/api/profiles',
+        { headers: token ? { 'X-GMM-Token': token } : undefined },
+        handleUnauthorized,
+      ),
+    [handleUnauthorized, token],
+  )
+
+  const applyProfile = (name: string) =>
+    fetchJson<ConfigResponse>(
+      `/api/profiles/${encodeURIComponent(name)}/apply`,
+      { method: 'POST', headers: buildJsonHeaders(token) },
+      handleUnauthorized,
+    ).then((data: ConfigResponse) => {
+      setConfig(data.config)
+      setInvocation(data.invocation)
+      setInvocationFull(data.invocationFull)
+      setSelectedKind(data.config.readKind)
+      setAddressInput(formatReadAddress(data.config.readAddress, data.config.addressFormat))
+      setQuantity(data.config.readQuantity)
+      if (connected || connecting) {
+        return apiPost('/api/disconnect', token, handleUnauthorized).then(() =>
+          apiPost('/api/connect', token, handleUnauthorized),
+        )
+      }
+    })
+
+  const saveProfile = (name: string) =>
+    fetchJson(
+      '/api/profiles',
+      { method: 'POST', headers: buildJsonHeaders(token), body: JSON.stringify({ name }) },
+      handleUnauthorized,
+    )
+
+  const deleteProfile = (name: string) =>
+    fetch(`/api/profiles/${encodeURIComponent(name)}`, {
+      method: 'DELETE',
+      headers: token ? { 'X-GMM-Token': token } : undefined,
+    }).then((res) => {
+      if (res.status === 401) {
+        handleUnauthorized()
+      }
+      if (!res.ok) {
+        throw new Error(res.status === 404 ? 'Profile not found' : res.statusText)
+      }
+    })
+
   const setAddressBase = (base: number) => {
     if (!config) return
     updateConfig({ ...config, addressBase: base }, false)
@@ -285,6 +420,7 @@ function App() {
       connected={connected}
       connecting={connecting}
       connectionError={connectionError}
+      capture={capture}
       logs={logs}
       showLogs={showLogs}
       stats={stats}
@@ -299,9 +435,18 @@ function App() {
       autoConnect={autoConnect}
       addressError={addressError}
       quantityError={quantityError}
+      configErrors={configErrors}
       onSaveConfig={(next) => updateConfig(next, connected || connecting)}
+      onSaveConfigFile={handleSaveConfigFile}
+      onListProfiles={listProfiles}
+      onApplyProfile={applyProfile}
+      onSaveProfile={saveProfile}
+      onDeleteProfile={deleteProfile}
       onUnauthorized={handleUnauthorized}
       onUpdateDecoder={updateDecoder}
+      onUpdateAssignments={updateAssignments}
+      onDetectByteOrder={detectByteOrder}
+      onEncode={encodeValue}
       onColumnsChange={setColumns}
       onAddressBaseChange={setAddressBase}
       onAddressFormatChange={setAddressFormat}
@@ -314,6 +459,8 @@ function App() {
       onToggleLogs={() => setShowLogs((prev) => !prev)}
       onConnect={handleConnect}
       onDisconnect={handleDisconnect}
+      onStartCapture={handleStartCapture}
+      onStopCapture={handleStopCapture}
     />
   )
 }
@@ -1,6 +1,10 @@
+import AssignmentPanel from './AssignmentPanel'
+import ByteOrderDetect from './ByteOrderDetect'
 import ConfigForm from './ConfigForm'
 import DecoderPanel from './DecoderPanel'
 import DisplayPanel from './DisplayPanel'
+import EncodePanel from './EncodePanel'
+import ProfilesPanel from './ProfilesPanel'
 import RawLog from './RawLog'
 import ReadPanel from './ReadPanel'
 import StatsPanel from './StatsPanel'
@@ -21,14 +25,15 @@ import {
   SidebarSeparator,
   SidebarTrigger,
 } from './ui/sidebar'
-import type { Config } from '../types'
-import type { LogEntry, ReadKind, ReadResult, Stats } from '../view-models'
+import type { Assignment, Config, DecoderConfig, FieldError, ProfilesResponse } from '../types'
+import type { ByteOrderCandidate, CaptureStatus, LogEntry, ReadKind, ReadResult, Stats } from '../view-models'
 
 type AppLayoutProps = {
   config: Config | null
   connected: boolean
   connecting: boolean
   connectionError: string
+  capture: CaptureStatus
   logs: LogEntry[]
   showLogs: boolean
   stats: Stats
@@ -43,9 +48,18 @@ type AppLayoutProps = {
   autoConnect: boolean
   addressError: string
   quantityError: string
+  configErrors: FieldError[]
   onSaveConfig: (next: Config) => void
+  onSaveConfigFile: () => Promise<string>
+  onListProfiles: () => Promise<ProfilesResponse>
+  onApplyProfile: (name: string) => Promise<unknown>
+  onSaveProfile: (name: string) => Promise<unknown>
+  onDeleteProfile: (name: string) => Promise<unknown>
   onUnauthorized: () => void
-  onUpdateDecoder: (nextDecoder: { type: string; endianness: string; wordOrder: string; enabled: boolean }) => void
+  onUpdateDecoder: (nextDecoder: DecoderConfig) => void
+  onUpdateAssignments: (next: Assignment[]) => void
+  onDetectByteOrder: (type: string, expected: number) => Promise<ByteOrderCandidate[]>
+  onEncode: (decoder: DecoderConfig, value: string) => Promise<number[]>
   onColumnsChange: (next: number) => void
   onAddressBaseChange: (base: number) => void
   onAddressFormatChange: (format: number) => void
@@ -58,6 +72,8 @@ type AppLayoutProps = {
   onToggleLogs: () => void
   onConnect: () => void
   onDisconnect: () => void
+  onStartCapture: () => void
+  onStopCapture: () => void
 }
 
 function AppLayout({
@@ -65,6 +81,7 @@ function AppLayout({
   connected,
   connecting,
   connectionError,
+  capture,
   logs,
   showLogs,
   stats,
@@ -79,9 +96,18 @@ function AppLayout({
   autoConnect,
   addressError,
   quantityError,
+  configErrors,
   onSaveConfig,
+  onSaveConfigFile,
+  onListProfiles,
+  onApplyProfile,
+  onSaveProfile,
+  onDeleteProfile,
   onUnauthorized,
   onUpdateDecoder,
+  onUpdateAssignments,
+  onDetectByteOrder,
+  onEncode,
   onColumnsChange,
   onAddressBaseChange,
   onAddressFormatChange,
@@ -94,6 +120,8 @@ function AppLayout({
   onToggleLogs,
   onConnect,
   onDisconnect,
+  onStartCapture,
+  onStopCapture,
 }: AppLayoutProps) {
   const statusLabel = connected ? 'online' : connecting ? 'connecting' : 'offline'
   const statusVariant = connected ? 'default' : connecting ? 'secondary' : 'outline'
@@ -102,6 +130,7 @@ function AppLayout({
   const addressFormat = config?.addressFormat ?? 10
   const valueBase = config?.valueBase ?? 10
   const decoders = config?.decoders ?? []
+  const assignments = config?.assignments ?? []
 
   return (
     <SidebarProvider defaultOpen>
@@ -116,6 +145,8 @@ function AppLayout({
               <ConfigForm
                 config={config}
                 onSave={onSaveConfig}
+                errors={configErrors}
+                onSaveFile={onSaveConfigFile}
                 connected={connected}
                 connecting={connecting}
                 onUnauthorized={onUnauthorized}
@@ -123,6 +154,19 @@ function AppLayout({
             </SidebarGroupContent>
           </SidebarGroup>
           <SidebarSeparator />
+          <SidebarGroup>
+            <SidebarGroupLabel>Profiles</SidebarGroupLabel>
+            <SidebarGroupContent>
+              <ProfilesPanel
+                activeProfile={config?.profile}
+                onList={onListProfiles}
+                onApply={onApplyProfile}
+                onSave={onSaveProfile}
+                onDelete={onDeleteProfile}
+              />
+            </SidebarGroupContent>
+          </SidebarGroup>
+          <SidebarSeparator />
           <SidebarGroup>
             <SidebarGroupLabel>
               <span className="flex items-center gap-1">
@@ -139,10 +183,10 @@ function AppLayout({
                   </TooltipTrigger>
                   <TooltipContent>
                     <div className="space-y-1 text-xs">
-                      <div>BE = big endian</div>
-                      <div>LE = little endian</div>
-                      <div>HF = high first</div>
-                      <div>LF = low first</div>
+                      <div>ABCD = big endian, high word first</div>
+                      <div>CDAB = word swap (low word first)</div>
+                      <div>BADC = byte swap (little endian)</div>
+                      <div>DCBA = byte and word swap</div>
                     </div>
                   </TooltipContent>
                 </Tooltip>
@@ -150,6 +194,25 @@ function AppLayout({
             </SidebarGroupLabel>
             <SidebarGroupContent>
               <DecoderPanel decoders={decoders} onUpdate={onUpdateDecoder} />
+              <ByteOrderDetect
+                decoders={decoders}
+                canDetect={Boolean(lastResult?.regValues?.length)}
+                onDetect={onDetectByteOrder}
+                onApply={onUpdateDecoder}
+              />
+              <EncodePanel decoders={decoders} onEncode={onEncode} />
+            </SidebarGroupContent>
+          </SidebarGroup>
+          <SidebarSeparator />
+          <SidebarGroup>
+            <SidebarGroupLabel>Assignments</SidebarGroupLabel>
+            <SidebarGroupContent>
+              <AssignmentPanel
+                assignments={assignments}
+                decoders={decoders}
+                addressFormat={addressFormat}
+                onUpdate={onUpdateAssignments}
+              />
             </SidebarGroupContent>
           </SidebarGroup>
           <SidebarSeparator />
@@ -158,6 +221,7 @@ function AppLayout({
             <SidebarGroupContent>
               <DisplayPanel
                 config={config}
+                lastResult={lastResult}
                 columns={columns}
                 onColumnsChange={onColumnsChange}
                 onAddressBaseChange={onAddressBaseChange}
@@ -185,6 +249,14 @@ function AppLayout({
                 </span>
               )}
               <div className="flex items-center gap-2 shrink-0">
+                <Button
+                  size="sm"
+                  variant={capture.active ? 'secondary' : 'outline'}
+                  onClick={capture.active ? onStopCapture : onStartCapture}
+                  title={capture.path}
+                >
+                  {capture.active ? `Stop capture (${capture.packets})` : 'Capture'}
+                </Button>
                 <Badge variant={statusVariant}>{statusLabel}</Badge>
                 <Button size="sm" variant={actionVariant} onClick={connected || connecting ? onDisconnect : onConnect}>
                   {connected || connecting ? 'Disconnect' : 'Connect'}
@@ -203,6 +275,7 @@ function AppLayout({
               addressFormat={addressFormat}
               valueBase={valueBase}
               decoders={decoders}
+              assignments={assignments}
               lastResult={lastResult}
               columns={columns}
               connected={connected}
@@ -0,0 +1,119 @@
+import { X } from 'lucide-react'
+import { useState } from 'react'
+import { parseAddress } from '../lib/parse'
+import type { Assignment, DecoderConfig } from '../types'
+import { decoderTypeOrder } from './decoder-order'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
+
+type Props = {
+  assignments: Assignment[]
+  decoders: DecoderConfig[]
+  addressFormat: number
+  onUpdate: (assignments: Assignment[]) => void
+}
+
+// AssignmentPanel pins decoders to addresses. A new assignment copies the
+// current settings of the global decoder of the same type.
+export default function AssignmentPanel({ assignments, decoders, addressFormat, onUpdate }: Props) {
+  const [address, setAddress] = useState('')
+  const [count, setCount] = useState('1')
+  const [type, setType] = useState<string>('float32')
+  const [name, setName] = useState('')
+  const parsedAddress = parseAddress(address)
+  const parsedCount = Number(count)
+  const valid =
+    parsedAddress !== null && parsedAddress <= 0xffff && Number.isInteger(parsedCount) && parsedCount >= 1
+
+  const add = () => {
+    if (!valid || parsedAddress === null) return
+    const decoder = decoders.find((item) => item.type === type) ?? {
+      type,
+      endianness: 'big',
+      wordOrder: 'high-first',
+      enabled: true,
+    }
+    const next: Assignment = {
+      address: parsedAddress,
+      count: parsedCount > 1 ? parsedCount : undefined,
+      name: name.trim() || undefined,
+      decoder: { ...decoder, enabled: true },
+    }
+    onUpdate([...assignments.filter((item) => item.address !== parsedAddress), next])
+    setAddress('')
+    setName('')
+  }
+
+  return (
+    <div className="space-y-2">
+      {assignments.map((assignment) => (
+        <div key={assignment.address} className="flex items-center justify-between gap-2 text-xs">
+          <code className="shrink-0">
+            {formatAddress(assignment.address, addressFormat)}
+            {assignment.count && assignment.count > 1 ? ` ×${assignment.count}` : ''}
+          </code>
+          <span className="min-w-0 flex-1 truncate" title={assignment.description ?? assignment.name}>
+            {assignment.decoder.type}
+            {assignment.name ? ` ${assignment.name}` : ''}
+            {assignment.mapped && <span className="text-muted-foreground"> (map)</span>}
+          </span>
+          <Button
+            size="icon"
+            variant="ghost"
+            className="size-6"
+            aria-label={`Remove assignment at ${assignment.address}`}
+            onClick={() => onUpdate(assignments.filter((item) => item.address !== assignment.address))}
+          >
+            <X className="size-3.5" />
+          </Button>
+        </div>
+      ))}
+      <div className="grid grid-cols-[minmax(0,1fr)_3rem] gap-2">
+        <Input
+          className="h-8"
+          value={address}
+          placeholder="Address"
+          aria-label="Assignment address"
+          aria-invalid={address !== '' && parsedAddress === null}
+          onChange={(event) => setAddress(event.target.value)}
+        />
+        <Input
+          className="h-8 px-2"
+          type="number"
+          min={1}
+          value={count}
+          aria-label="Assignment count"
+          title="Consecutive values"
+          onChange={(event) => setCount(event.target.value)}
+        />
+        <Select value={type} onValueChange={setType}>
+          <SelectTrigger size="sm" className="w-full">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {decoderTypeOrder.map((option) => (
+              <SelectItem key={option} value={option}>
+                {option}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <Button size="sm" disabled={!valid} onClick={add}>
+          Pin
+        </Button>
+        <Input
+          className="col-span-2 h-8"
+          value={name}
+          placeholder="Name (optional)"
+          aria-label="Assignment name"
+          onChange={(event) => setName(event.target.value)}
+        />
+      </div>
+    </div>
+  )
+}
+
+function formatAddress(address: number, format: number) {
+  return format === 16 ? `0x${address.toString(16).padStart(4, '0')}` : `${address}`
+}
@@ -0,0 +1,91 @@
+import { useState } from 'react'
+import { withByteOrder } from '../lib/byte-order'
+import type { DecoderConfig } from '../types'
+import type { ByteOrderCandidate } from '../view-models'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
+
+const detectTypes = ['float32', 'float64', 'uint32', 'int32', 'uint64', 'int64', 'bcd32']
+
+type Props = {
+  decoders: DecoderConfig[]
+  canDetect: boolean
+  onDetect: (type: string, expected: number) => Promise<ByteOrderCandidate[]>
+  onApply: (decoder: DecoderConfig) => void
+}
+
+// ByteOrderDetect tries every byte order on the last read and lists the
+// decodings closest to a value the user expects, e.g. from the device display.
+export default function ByteOrderDetect({ decoders, canDetect, onDetect, onApply }: Props) {
+  const [type, setType] = useState('float32')
+  const [expected, setExpected] = useState('')
+  const [candidates, setCandidates] = useState<ByteOrderCandidate[]>([])
+  const [error, setError] = useState('')
+  const value = Number(expected)
+  const valid = expected.trim() !== '' && Number.isFinite(value)
+
+  const detect = () => {
+    setError('')
+    onDetect(type, value)
+      .then((next) => setCandidates(next.slice(0, 4)))
+      .catch((err: Error) => {
+        setCandidates([])
+        setError(err.message)
+      })
+  }
+
+  const apply = (candidate: ByteOrderCandidate) => {
+    const decoder = decoders.find((item) => item.type === type) ?? {
+      type,
+      endianness: 'big',
+      wordOrder: 'high-first',
+      enabled: true,
+    }
+    onApply({ ...withByteOrder(decoder, candidate.order), enabled: true })
+  }
+
+  return (
+    <div className="space-y-2 pt-2">
+      <span className="text-xs text-muted-foreground">Detect byte order from the last read</span>
+      <div className="flex items-center gap-2">
+        <Select value={type} onValueChange={setType}>
+          <SelectTrigger size="sm" className="w-24">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {detectTypes.map((option) => (
+              <SelectItem key={option} value={option}>
+                {option}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <Input
+          className="h-8 min-w-0 flex-1"
+          value={expected}
+          placeholder="Expected"
+          aria-label="Expected value"
+          onChange={(event) => setExpected(event.target.value)}
+        />
+        <Button size="sm" disabled={!valid || !canDetect} onClick={detect}>
+          Detect
+        </Button>
+      </div>
+      {error && <p className="text-xs text-destructive">{error}</p>}
+      {candidates.map((candidate, index) => (
+        <div key={`${candidate.order}-${candidate.offset}`} className="flex items-center justify-between gap-2 text-xs">
+          <code>
+            {candidate.order} +{candidate.offset}
+          </code>
+          <span className="min-w-0 flex-1 truncate" title={`Δ ${candidate.delta}`}>
+            {candidate.value}
+          </span>
+          <Button size="sm" variant={index === 0 ? 'default' : 'outline'} onClick={() => apply(candidate)}>
+            Apply
+          </Button>
+        </div>
+      ))}
+    </div>
+  )
+}
@@ -1,6 +1,7 @@
 import { useEffect, useState } from 'react'
-import type { Config } from '../types'
+import type { Config, FieldError } from '../types'
 import { Button } from './ui/button'
+import { Checkbox } from './ui/checkbox'
 import { Input } from './ui/input'
 import { Label } from './ui/label'
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
@@ -8,6 +9,8 @@ import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '.
 type Props = {
   config: Config | null
   onSave: (config: Config) => void
+  errors?: FieldError[]
+  onSaveFile: () => Promise<string>
   connected: boolean
   connecting: boolean
   onUnauthorized?: () => void
@@ -17,9 +20,18 @@ type SerialDevicesResponse = {
   devices: string[]
 }
 
-export default function ConfigForm({ config, onSave, connected, connecting, onUnauthorized }: Props) {
+export default function ConfigForm({
+  config,
+  onSave,
+  errors = [],
+  onSaveFile,
+  connected,
+  connecting,
+  onUnauthorized,
+}: Props) {
   const [draft, setDraft] = useState<Config | null>(config)
   const [serialDevices, setSerialDevices] = useState<string[]>([])
+  const [saveMessage, setSaveMessage] = useState('')
 
   useEffect(() => {
     setDraft(config)
@@ -182,11 +194,37 @@ export default function ConfigForm({ config, onSave, connected, connecting, onUn
         />
       </div>
 
-      <div className="flex items-center justify-end md:col-span-2">
+      <label className="flex items-center gap-2 text-sm md:col-span-2">
+        <Checkbox
+          checked={Boolean(draft.autoSave)}
+          onCheckedChange={(value) => update({ autoSave: Boolean(value) || undefined })}
+        />
+        Save config file on exit
+      </label>
+
+      <div className="flex items-center justify-end gap-2 md:col-span-2">
+        <Button
+          size="sm"
+          variant="outline"
+          title={draft.configFile ? `Save to ${draft.configFile}` : 'Save to the default config file'}
+          onClick={() =>
+            onSaveFile()
+              .then((path) => setSaveMessage(`Saved to ${path}`))
+              .catch((err: Error) => setSaveMessage(err.message))
+          }
+        >
+          Save
+        </Button>
         <Button size="sm" onClick={() => onSave(draft)}>
           {actionLabel}
         </Button>
       </div>
+      {errors.map((error) => (
+        <p key={error.field} className="text-xs text-destructive md:col-span-2">
+          {error.field} {error.message}
+        </p>
+      ))}
+      {saveMessage && <p className="text-xs text-muted-foreground md:col-span-2">{saveMessage}</p>}
     </div>
   )
 }
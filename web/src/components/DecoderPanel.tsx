@@ -1,25 +1,36 @@
+import { useState } from 'react'
+import { byteOrderOf, byteOrders, withByteOrder, type ByteOrder } from '../lib/byte-order'
+import { formatLabels, parseLabels } from '../lib/labels'
+import { parseAddress } from '../lib/parse'
+import { isValidTimezone } from '../lib/timestamp'
 import type { DecoderConfig } from '../types'
 import { decoderTypeOrder } from './decoder-order'
 import { Checkbox } from './ui/checkbox'
+import { Input } from './ui/input'
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
 
-const endiannessOptions = [
-  { label: 'BE', value: 'big' },
-  { label: 'LE', value: 'little' },
+const trimOptions = [
+  { label: 'No trim', value: 'none' },
+  { label: 'NUL', value: 'null' },
+  { label: 'Space', value: 'space' },
+  { label: 'Both', value: 'both' },
 ]
 
-const wordOrderOptions = [
-  { label: 'HF', value: 'high-first' },
-  { label: 'LF', value: 'low-first' },
+const encodingOptions = [
+  { label: 'ASCII', value: 'ascii' },
+  { label: 'UTF-8', value: 'utf-8' },
+  { label: 'Latin-1', value: 'latin-1' },
 ]
 
+const unscaledTypes = new Set<string>(['string', 'bitfield', 'enum', 'epoch32', 'epoch64', 'datetime', 'packed'])
+
 type Props = {
   decoders: DecoderConfig[]
   onUpdate: (decoder: DecoderConfig) => void
 }
 
 export default function DecoderPanel({ decoders, onUpdate }: Props) {
-  const findDecoder = (type: string) =>
+  const findDecoder = (type: string): DecoderConfig =>
     decoders.find((decoder) => decoder.type === type) ?? {
       type,
       endianness: 'big',
@@ -28,7 +39,7 @@ export default function DecoderPanel({ decoders, onUpdate }: Props) {
     }
 
   return (
-    <div className="grid grid-cols-[auto_minmax(0,7rem)_auto_auto] items-center gap-2">
+    <div className="grid grid-cols-[auto_minmax(0,7rem)_auto] items-center gap-2">
       {decoderTypeOrder.map((type) => {
         const decoder = findDecoder(type)
         return (
@@ -38,33 +49,333 @@ export default function DecoderPanel({ decoders, onUpdate }: Props) {
               onCheckedChange={(value) => onUpdate({ ...decoder, enabled: Boolean(value) })}
             />
             <span className="truncate">{type}</span>
-            <Select value={decoder.endianness} onValueChange={(value) => onUpdate({ ...decoder, endianness: value })}>
-              <SelectTrigger size="sm" className="w-20">
+            <Select
+              value={byteOrderOf(decoder)}
+              onValueChange={(value) => onUpdate(withByteOrder(decoder, value as ByteOrder))}
+            >
+              <SelectTrigger size="sm" className="w-24">
                 <SelectValue />
               </SelectTrigger>
               <SelectContent>
-                {endiannessOptions.map((option) => (
-                  <SelectItem key={option.value} value={option.value}>
-                    {option.label}
-                  </SelectItem>
-                ))}
-              </SelectContent>
-            </Select>
-            <Select value={decoder.wordOrder} onValueChange={(value) => onUpdate({ ...decoder, wordOrder: value })}>
-              <SelectTrigger size="sm" className="w-20">
-                <SelectValue />
-              </SelectTrigger>
-              <SelectContent>
-                {wordOrderOptions.map((option) => (
-                  <SelectItem key={option.value} value={option.value}>
-                    {option.label}
+                {byteOrders.map((order) => (
+                  <SelectItem key={order} value={order}>
+                    {order}
                   </SelectItem>
                 ))}
               </SelectContent>
             </Select>
+            {decoder.enabled && (type === 'epoch32' || type === 'epoch64' || type === 'datetime') && (
+              <div className="col-span-3 flex items-center gap-2 pl-6">
+                <DraftInput
+                  key={`timezone-${decoder.timezone ?? ''}`}
+                  label="Timezone"
+                  placeholder="UTC"
+                  className="w-36"
+                  value={decoder.timezone}
+                  apply={(value) => {
+                    const timezone = value.trim()
+                    return timezone === '' || isValidTimezone(timezone)
+                      ? { ...decoder, timezone: timezone || undefined }
+                      : null
+                  }}
+                  onCommit={(next) => next && onUpdate(next)}
+                />
+                {type === 'datetime' ? (
+                  <Select
+                    value={`${decoder.registers === 3 ? 3 : 6}`}
+                    onValueChange={(value) => onUpdate({ ...decoder, registers: Number(value) })}
+                  >
+                    <SelectTrigger size="sm" className="w-32">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      <SelectItem value="6">6 registers</SelectItem>
+                      <SelectItem value="3">3 (byte pairs)</SelectItem>
+                    </SelectContent>
+                  </Select>
+                ) : (
+                  <label className="flex items-center gap-1 text-xs">
+                    <Checkbox
+                      checked={Boolean(decoder.millis)}
+                      onCheckedChange={(value) => onUpdate({ ...decoder, millis: Boolean(value) || undefined })}
+                    />
+                    ms
+                  </label>
+                )}
+              </div>
+            )}
+            {decoder.enabled && !unscaledTypes.has(type) && (
+              <ScalingInputs decoder={decoder} onUpdate={onUpdate} />
+            )}
+            {decoder.enabled && type === 'packed' && (
+              <div className="col-span-3 flex items-center gap-2 pl-6">
+                <DraftInput
+                  key={`bits-${decoder.bits ?? ''}`}
+                  label="Coils per value"
+                  placeholder="bits"
+                  className="w-14"
+                  value={decoder.bits}
+                  apply={(value) => {
+                    const bits = Number(value)
+                    return Number.isInteger(bits) && bits >= 1 && bits <= 64 ? { ...decoder, bits } : null
+                  }}
+                  onCommit={(next) => next && onUpdate(next)}
+                />
+                <Select
+                  value={decoder.msbFirst ? 'msb' : 'lsb'}
+                  onValueChange={(value) => onUpdate({ ...decoder, msbFirst: value === 'msb' || undefined })}
+                >
+                  <SelectTrigger size="sm" className="w-28">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="lsb">LSB first</SelectItem>
+                    <SelectItem value="msb">MSB first</SelectItem>
+                  </SelectContent>
+                </Select>
+              </div>
+            )}
+            {decoder.enabled && (type === 'q16' || type === 'q32') && (
+              <div className="col-span-3 flex items-center gap-2 pl-6">
+                <DraftInput
+                  key={`fracBits-${decoder.fracBits ?? ''}`}
+                  label="Fraction bits"
+                  placeholder="n"
+                  className="w-14"
+                  value={decoder.fracBits}
+                  apply={(value) => {
+                    const fracBits = value.trim() === '' ? 0 : Number(value)
+                    const max = type === 'q16' ? 16 : 32
+                    return Number.isInteger(fracBits) && fracBits >= 0 && fracBits <= max ? { ...decoder, fracBits } : null
+                  }}
+                  onCommit={(next) => next && onUpdate(next)}
+                />
+                <label className="flex items-center gap-1 text-xs">
+                  <Checkbox
+                    checked={Boolean(decoder.unsigned)}
+                    onCheckedChange={(value) => onUpdate({ ...decoder, unsigned: Boolean(value) || undefined })}
+                  />
+                  unsigned
+                </label>
+              </div>
+            )}
+            {type === 'bitfield' && (
+              <LabelsInput
+                key={formatLabels(decoder.bitNames)}
+                decoder={decoder}
+                field="bitNames"
+                max={15}
+                placeholder="0=RUN, 3=FAULT"
+                onUpdate={onUpdate}
+              />
+            )}
+            {type === 'enum' && (
+              <LabelsInput
+                key={formatLabels(decoder.labels)}
+                decoder={decoder}
+                field="labels"
+                max={0xffff}
+                placeholder="0=Stopped, 1=Running, 2=Fault"
+                onUpdate={onUpdate}
+              />
+            )}
+            {type === 'string' && (
+              <div className="col-span-3 flex items-center gap-2 pl-6">
+                <Input
+                  className="h-8 w-16"
+                  type="number"
+                  min={1}
+                  max={125}
+                  value={decoder.registers ?? 8}
+                  aria-label="String registers"
+                  onChange={(event) => {
+                    const registers = Number(event.target.value)
+                    if (Number.isInteger(registers) && registers >= 1 && registers <= 125) {
+                      onUpdate({ ...decoder, registers })
+                    }
+                  }}
+                />
+                <Select
+                  value={decoder.trim ?? 'both'}
+                  onValueChange={(value) => onUpdate({ ...decoder, trim: value as DecoderConfig['trim'] })}
+                >
+                  <SelectTrigger size="sm" className="w-20">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {trimOptions.map((option) => (
+                      <SelectItem key={option.value} value={option.value}>
+                        {option.label}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+                <Select
+                  value={decoder.encoding ?? 'ascii'}
+                  onValueChange={(value) => onUpdate({ ...decoder, encoding: value as DecoderConfig['encoding'] })}
+                >
+                  <SelectTrigger size="sm" className="w-24">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {encodingOptions.map((option) => (
+                      <SelectItem key={option.value} value={option.value}>
+                        {option.label}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+            )}
           </div>
         )
       })}
     </div>
   )
 }
+
+function LabelsInput({
+  decoder,
+  field,
+  max,
+  placeholder,
+  onUpdate,
+}: {
+  decoder: DecoderConfig
+  field: 'bitNames' | 'labels'
+  max: number
+  placeholder: string
+  onUpdate: (decoder: DecoderConfig) => void
+}) {
+  const [draft, setDraft] = useState(formatLabels(decoder[field]))
+  const parsed = parseLabels(draft, max)
+
+  return (
+    <div className="col-span-3 pl-6">
+      <Input
+        className="h-8"
+        value={draft}
+        placeholder={placeholder}
+        aria-label={field === 'bitNames' ? 'Bit names' : 'Enum labels'}
+        aria-invalid={parsed === null}
+        onChange={(event) => setDraft(event.target.value)}
+        onBlur={() => {
+          if (parsed) {
+            onUpdate({ ...decoder, [field]: parsed })
+          }
+        }}
+      />
+    </div>
+  )
+}
+
+// ScalingInputs edits gain, offset, decimals, unit and the SunSpec-style scale
+// factor register; each field is committed on blur once it parses.
+function ScalingInputs({ decoder, onUpdate }: { decoder: DecoderConfig; onUpdate: (decoder: DecoderConfig) => void }) {
+  const number = (value: string) => (value.trim() === '' ? undefined : Number(value))
+  const fields: {
+    label: string
+    placeholder: string
+    className: string
+    value: string | number | undefined
+    apply: (value: string) => DecoderConfig | null
+  }[] = [
+    {
+      label: 'Scale',
+      placeholder: '×1',
+      className: 'w-16',
+      value: decoder.scale,
+      apply: (value) => {
+        const scale = number(value)
+        return scale === undefined || (Number.isFinite(scale) && scale !== 0) ? { ...decoder, scale } : null
+      },
+    },
+    {
+      label: 'Offset',
+      placeholder: '+0',
+      className: 'w-16',
+      value: decoder.offset,
+      apply: (value) => {
+        const offset = number(value)
+        return offset === undefined || Number.isFinite(offset) ? { ...decoder, offset } : null
+      },
+    },
+    {
+      label: 'Decimals',
+      placeholder: 'dec',
+      className: 'w-12',
+      value: decoder.decimals,
+      apply: (value) => {
+        const decimals = number(value)
+        return decimals === undefined || (Number.isInteger(decimals) && decimals >= 0 && decimals <= 10)
+          ? { ...decoder, decimals }
+          : null
+      },
+    },
+    {
+      label: 'Unit',
+      placeholder: 'unit',
+      className: 'w-14',
+      value: decoder.unit,
+      apply: (value) => ({ ...decoder, unit: value.trim() || undefined }),
+    },
+    {
+      label: 'Scale factor register',
+      placeholder: 'SF reg',
+      className: 'w-20',
+      value: decoder.scaleFactor,
+      apply: (value) => {
+        if (value.trim() === '') {
+          return { ...decoder, scaleFactor: undefined }
+        }
+        const register = parseAddress(value)
+        return register !== null && register <= 0xffff ? { ...decoder, scaleFactor: register } : null
+      },
+    },
+  ]
+
+  return (
+    <div className="col-span-3 flex items-center gap-1 pl-6">
+      {fields.map((field) => (
+        <DraftInput key={`${field.label}-${field.value ?? ''}`} {...field} onCommit={(next) => next && onUpdate(next)} />
+      ))}
+    </div>
+  )
+}
+
+function DraftInput({
+  label,
+  placeholder,
+  className,
+  value,
+  apply,
+  onCommit,
+}: {
+  label: string
+  placeholder: string
+  className: string
+  value: string | number | undefined
+  apply: (value: string) => DecoderConfig | null
+  onCommit: (next: DecoderConfig | null) => void
+}) {
+  const initial = value === undefined ? '' : `${value}`
+  const [draft, setDraft] = useState(initial)
+  const next = apply(draft)
+
+  return (
+    <Input
+      className={`h-8 px-2 ${className}`}
+      value={draft}
+      placeholder={placeholder}
+      title={label}
+      aria-label={label}
+      aria-invalid={next === null}
+      onChange={(event) => setDraft(event.target.value)}
+      onBlur={() => {
+        if (draft !== initial) {
+          onCommit(next)
+        }
+      }}
+    />
+  )
+}
@@ -1,10 +1,13 @@
 import { CircleHelp } from 'lucide-react'
+import { decodeBitfield } from '../lib/bitfield'
 import type { Config } from '../types'
+import type { ReadResult } from '../view-models'
 import { Button } from './ui/button'
 import { Tooltip, TooltipContent, TooltipTrigger } from './ui/tooltip'
 
 type Props = {
   config: Config | null
+  lastResult: ReadResult | null
   columns: number
   onColumnsChange: (value: number) => void
   onAddressBaseChange: (value: number) => void
@@ -14,6 +17,7 @@ type Props = {
 
 export default function DisplayPanel({
   config,
+  lastResult,
   columns,
   onColumnsChange,
   onAddressBaseChange,
@@ -21,6 +25,14 @@ export default function DisplayPanel({
   onValueBaseChange,
 }: Props) {
   const activeVariant = (active: boolean) => (active ? 'default' : 'outline')
+  const bitfield = config?.decoders.find((decoder) => decoder.type === 'bitfield' && decoder.enabled)
+  const flagRows =
+    bitfield && lastResult?.regValues
+      ? lastResult.regValues.map((reg, index) => ({
+          address: lastResult.address + index,
+          ...decodeBitfield(reg, bitfield),
+        }))
+      : []
 
   return (
     <div className="space-y-2">
@@ -96,6 +108,21 @@ export default function DisplayPanel({
           </div>
         </div>
       </div>
+      {flagRows.length > 0 && (
+        <div className="space-y-1">
+          <span>Flags</span>
+          {flagRows.map((row) => (
+            <div key={row.address} className="flex items-start justify-between gap-2 text-xs">
+              <code className="shrink-0">
+                {config?.addressFormat === 16 ? `0x${row.address.toString(16).padStart(4, '0')}` : row.address}
+              </code>
+              <span className="text-right" title={row.bits}>
+                {row.flags.length ? row.flags.join(', ') : <span className="text-muted-foreground">—</span>}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
     </div>
   )
 }
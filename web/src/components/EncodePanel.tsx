@@ -0,0 +1,83 @@
+import { useState } from 'react'
+import type { DecoderConfig } from '../types'
+import { decoderTypeOrder } from './decoder-order'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
+
+type Props = {
+  decoders: DecoderConfig[]
+  onEncode: (decoder: DecoderConfig, value: string) => Promise<number[]>
+}
+
+// EncodePanel shows the register words a typed value encodes to with the
+// configured decoder settings (byte order, scaling, labels).
+export default function EncodePanel({ decoders, onEncode }: Props) {
+  const [type, setType] = useState<string>('float32')
+  const [value, setValue] = useState('')
+  const [registers, setRegisters] = useState<number[]>([])
+  const [error, setError] = useState('')
+
+  const encode = () => {
+    const decoder = decoders.find((item) => item.type === type) ?? {
+      type,
+      endianness: 'big',
+      wordOrder: 'high-first',
+      enabled: true,
+    }
+    setError('')
+    onEncode(decoder, value)
+      .then(setRegisters)
+      .catch((err: Error) => {
+        setRegisters([])
+        setError(err.message)
+      })
+  }
+
+  return (
+    <div className="space-y-2 pt-2">
+      <span className="text-xs text-muted-foreground">Encode a value to registers</span>
+      <div className="flex items-center gap-2">
+        <Select value={type} onValueChange={setType}>
+          <SelectTrigger size="sm" className="w-24">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {decoderTypeOrder
+              .filter((option) => option !== 'packed')
+              .map((option) => (
+                <SelectItem key={option} value={option}>
+                  {option}
+                </SelectItem>
+              ))}
+          </SelectContent>
+        </Select>
+        <Input
+          className="h-8 min-w-0 flex-1"
+          value={value}
+          placeholder="Value"
+          aria-label="Value to encode"
+          onChange={(event) => setValue(event.target.value)}
+          onKeyDown={(event) => {
+            if (event.key === 'Enter' && value.trim() !== '') {
+              encode()
+            }
+          }}
+        />
+        <Button size="sm" disabled={value.trim() === ''} onClick={encode}>
+          Encode
+        </Button>
+      </div>
+      {error && <p className="text-xs text-destructive">{error}</p>}
+      {registers.length > 0 && (
+        <div className="flex flex-wrap gap-2 text-xs">
+          {registers.map((reg, index) => (
+            <code key={index} title={`+${index}: ${reg}`}>
+              0x{reg.toString(16).toUpperCase().padStart(4, '0')}
+            </code>
+          ))}
+        </div>
+      )}
+    </div>
+  )
+}
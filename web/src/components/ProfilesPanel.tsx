@@ -0,0 +1,102 @@
+import { useCallback, useEffect, useState } from 'react'
+import type { ProfilesResponse } from '../types'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Select, SelectContent, SelectGroup, SelectItem, SelectLabel, SelectTrigger, SelectValue } from './ui/select'
+
+type Props = {
+  activeProfile?: string
+  onList: () => Promise<ProfilesResponse>
+  onApply: (name: string) => Promise<unknown>
+  onSave: (name: string) => Promise<unknown>
+  onDelete: (name: string) => Promise<unknown>
+}
+
+// ProfilesPanel switches between saved connection profiles and device
+// profiles, and saves the current connection settings under a name.
+export default function ProfilesPanel({ activeProfile, onList, onApply, onSave, onDelete }: Props) {
+  const [profiles, setProfiles] = useState<ProfilesResponse>({ connections: [], devices: [] })
+  const [selected, setSelected] = useState(activeProfile ?? '')
+  const [name, setName] = useState('')
+  const [error, setError] = useState('')
+
+  const refresh = useCallback(() => {
+    onList()
+      .then(setProfiles)
+      .catch((err: Error) => setError(err.message))
+  }, [onList])
+
+  useEffect(() => {
+    refresh()
+  }, [refresh])
+
+  useEffect(() => {
+    setSelected(activeProfile ?? '')
+  }, [activeProfile])
+
+  const run = (action: Promise<unknown>) => {
+    setError('')
+    action.then(refresh).catch((err: Error) => setError(err.message))
+  }
+
+  const isConnection = profiles.connections.some((profile) => profile.name === selected)
+
+  return (
+    <div className="grid gap-2">
+      <div className="flex items-center gap-2">
+        <Select value={selected} onValueChange={setSelected}>
+          <SelectTrigger className="w-full" aria-label="Profile">
+            <SelectValue placeholder="Choose a profile" />
+          </SelectTrigger>
+          <SelectContent>
+            {profiles.connections.length > 0 && (
+              <SelectGroup>
+                <SelectLabel>Connections</SelectLabel>
+                {profiles.connections.map((profile) => (
+                  <SelectItem key={`connection-${profile.name}`} value={profile.name}>
+                    {profile.name}
+                  </SelectItem>
+                ))}
+              </SelectGroup>
+            )}
+            <SelectGroup>
+              <SelectLabel>Devices</SelectLabel>
+              {profiles.devices
+                .filter((device) => !profiles.connections.some((profile) => profile.name === device.name))
+                .map((device) => (
+                  <SelectItem key={`device-${device.name}`} value={device.name} title={device.title}>
+                    {device.name}
+                  </SelectItem>
+                ))}
+            </SelectGroup>
+          </SelectContent>
+        </Select>
+        <Button size="sm" disabled={!selected} onClick={() => run(onApply(selected))}>
+          Apply
+        </Button>
+        <Button size="sm" variant="outline" disabled={!isConnection} onClick={() => run(onDelete(selected))}>
+          Delete
+        </Button>
+      </div>
+      <div className="flex items-center gap-2">
+        <Input
+          value={name}
+          placeholder="Save current connection as..."
+          onChange={(event) => setName(event.target.value)}
+        />
+        <Button
+          size="sm"
+          variant="outline"
+          disabled={!name.trim()}
+          onClick={() => {
+            run(onSave(name.trim()))
+            setName('')
+          }}
+        >
+          Save
+        </Button>
+      </div>
+      {error && <p className="text-xs text-destructive">{error}</p>}
+    </div>
+  )
+}
@@ -1,5 +1,23 @@
 import { useEffect, useRef, useState } from 'react'
 import type { LogEntry } from '../view-models'
+import { Input } from './ui/input'
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from './ui/select'
+
+const directionOptions = [
+  { label: 'All', value: 'all' },
+  { label: 'TX', value: 'tx' },
+  { label: 'RX', value: 'rx' },
+  { label: 'Errors', value: 'err' },
+  { label: 'System', value: 'sys' },
+]
+
+const windowOptions = [
+  { label: 'All time', value: '0' },
+  { label: 'Last 1m', value: '60' },
+  { label: 'Last 5m', value: '300' },
+  { label: 'Last 15m', value: '900' },
+  { label: 'Last 1h', value: '3600' },
+]
 
 type Props = {
   logs: LogEntry[]
@@ -8,6 +26,18 @@ type Props = {
 export default function RawLog({ logs }: Props) {
   const containerRef = useRef<HTMLDivElement | null>(null)
   const [atBottom, setAtBottom] = useState(true)
+  const [direction, setDirection] = useState('all')
+  const [windowSeconds, setWindowSeconds] = useState('0')
+  const [search, setSearch] = useState('')
+
+  const since = Number(windowSeconds) > 0 ? Date.now() - Number(windowSeconds) * 1000 : 0
+  const needle = search.trim().toLowerCase()
+  const visible = logs.filter(
+    (entry) =>
+      (direction === 'all' || entry.direction === direction) &&
+      (since === 0 || new Date(entry.time).getTime() >= since) &&
+      (needle === '' || entry.message.toLowerCase().includes(needle)),
+  )
 
   useEffect(() => {
     if (!atBottom) return
@@ -25,10 +55,46 @@ export default function RawLog({ logs }: Props) {
 
   return (
     <section className="space-y-2">
+      <div className="flex flex-wrap items-center gap-2">
+        <Select value={direction} onValueChange={setDirection}>
+          <SelectTrigger size="sm" className="w-28">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {directionOptions.map((option) => (
+              <SelectItem key={option.value} value={option.value}>
+                {option.label}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <Select value={windowSeconds} onValueChange={setWindowSeconds}>
+          <SelectTrigger size="sm" className="w-28">
+            <SelectValue />
+          </SelectTrigger>
+          <SelectContent>
+            {windowOptions.map((option) => (
+              <SelectItem key={option.value} value={option.value}>
+                {option.label}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <Input
+          className="h-8 w-56"
+          type="search"
+          value={search}
+          onChange={(event) => setSearch(event.target.value)}
+          placeholder="Search logs"
+        />
+        <small>
+          {visible.length}/{logs.length}
+        </small>
+      </div>
       <div ref={containerRef} className="max-h-72 overflow-auto" onScroll={handleScroll}>
-        {logs.length === 0 && <p>No frames captured yet.</p>}
-        {logs.map((entry, index) => (
-          <div key={`${entry.time}-${index}`} className="flex gap-2">
+        {visible.length === 0 && <p>No frames captured yet.</p>}
+        {visible.map((entry, index) => (
+          <div key={`${entry.seq}-${index}`} className="flex gap-2">
             <span>{new Date(entry.time).toLocaleTimeString()}</span>
             <span>{entry.direction}</span>
             <span>{entry.message}</span>
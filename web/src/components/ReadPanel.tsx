@@ -1,5 +1,7 @@
-import type { DecoderConfig } from '../types'
+import type { Assignment, DecoderConfig } from '../types'
 import type { ReadKind, ReadResult } from '../view-models'
+import { decodeBitfield } from '../lib/bitfield'
+import { formatTimestamp, wallClockToEpoch } from '../lib/timestamp'
 import { decoderTypeOrder } from './decoder-order'
 import { Badge } from './ui/badge'
 import { Button } from './ui/button'
@@ -26,6 +28,7 @@ type Props = {
   addressFormat: number
   valueBase: number
   decoders: DecoderConfig[]
+  assignments: Assignment[]
   lastResult: ReadResult | null
   columns: number
   connected: boolean
@@ -41,6 +44,8 @@ type Props = {
 
 type Cell = { value: string; colSpan: number; fullValue?: string }
 
+type Decoded = Omit<Cell, 'colSpan'> & { raw?: number }
+
 type RenderRow = {
   label: string
   cells: Cell[]
@@ -54,6 +59,7 @@ export default function ReadPanel({
   addressFormat,
   valueBase,
   decoders,
+  assignments,
   lastResult,
   columns,
   connected,
@@ -66,7 +72,7 @@ export default function ReadPanel({
   onRead,
   onAutoConnectChange,
 }: Props) {
-  const rows = buildRows(lastResult, decoders, addressBase, addressFormat, valueBase, columns)
+  const rows = buildRows(lastResult, decoders, assignments, addressBase, addressFormat, valueBase, columns)
   const canRead = connected && !addressError && !quantityError
 
   return (
@@ -187,6 +193,7 @@ export default function ReadPanel({
 function buildRows(
   result: ReadResult | null,
   decoders: DecoderConfig[],
+  assignments: Assignment[],
   addressBase: number,
   addressFormat: number,
   valueBase: number,
@@ -197,6 +204,16 @@ function buildRows(
   }
   const rows: RenderRow[] = []
   const values = result.regValues ?? result.boolValues?.map((value) => (value ? 1 : 0)) ?? []
+  // Packed decoders only apply to coils and discrete inputs, all others only
+  // to registers.
+  const applies = (decoder: DecoderConfig) => (decoder.type === 'packed') === !result.regValues
+  const assigned = decodeAssignments(
+    result.address,
+    values,
+    assignments.filter(
+      (assignment) => applies(assignment.decoder) && (!assignment.kind || assignment.kind === result.kind),
+    ),
+  )
   const orderIndex = (type: string) => {
     const index = decoderTypeOrder.indexOf(type as (typeof decoderTypeOrder)[number])
     return index === -1 ? Number.MAX_SAFE_INTEGER : index
@@ -210,13 +227,18 @@ function buildRows(
       cells: slice.map((value) => ({ value: formatValue(value, valueBase), colSpan: 1 })),
     })
 
+    rows.push(...assignedRows(assigned, result.address, offset, Math.min(offset + columns, values.length)))
+
     const enabledDecoders = decoders
-      .filter((decoder) => decoder.enabled)
+      .filter((decoder) => decoder.enabled && applies(decoder))
       .sort((left, right) => orderIndex(left.type) - orderIndex(right.type))
-    if (enabledDecoders.length && result.regValues) {
-      const regSlice = result.regValues.slice(offset, offset + columns)
+    if (enabledDecoders.length) {
       for (const decoder of enabledDecoders) {
-        const decoded = decodeRegisters(regSlice, decoder, columns)
+        if (decoder.type === 'bitfield') {
+          rows.push(...bitfieldRows(values, result.address, offset, columns, decoder, addressBase, addressFormat))
+          continue
+        }
+        const decoded = decodeRow(result.address, values, offset, columns, decoder)
         rows.push({
           label: `↳ ${decoder.type}`,
           cells: decoded,
@@ -228,6 +250,111 @@ function buildRows(
   return rows
 }
 
+type AssignedValue = { address: number; span: number; type: string; name?: string; description?: string } & Decoded
+
+// decodeAssignments mirrors core.DecodeAssignments: values starting inside the
+// block, in address order, dropping overlaps.
+function decodeAssignments(address: number, regs: number[], assignments: Assignment[]): AssignedValue[] {
+  const values: AssignedValue[] = []
+  for (const assignment of assignments) {
+    const span = registerSpan(assignment.decoder)
+    for (let i = 0; i < Math.max(assignment.count ?? 1, 1); i++) {
+      const start = assignment.address + i * span
+      if (start < address || start >= address + regs.length) {
+        continue
+      }
+      const base = {
+        address: start,
+        type: assignment.decoder.type,
+        name: assignment.name,
+        description: assignment.description,
+      }
+      const slice = regs.slice(start - address, start - address + span)
+      if (slice.length < span) {
+        values.push({ ...base, span: slice.length, value: '—', fullValue: 'not enough registers' })
+        continue
+      }
+      values.push({ ...base, span, ...applyScaling(decodeAssigned(slice, assignment.decoder), assignment.decoder, address, regs) })
+    }
+  }
+  values.sort((left, right) => left.address - right.address)
+  let next = address
+  return values.filter((value) => {
+    if (value.address < next) {
+      return false
+    }
+    next = value.address + value.span
+    return true
+  })
+}
+
+function decodeAssigned(regs: number[], decoder: DecoderConfig): Decoded {
+  if (decoder.type !== 'bitfield') {
+    return decodeValue(regs, decoder)
+  }
+  const { raw, bits, flags } = decodeBitfield(regs[0], decoder)
+  const hex = `0x${raw.toString(16).toUpperCase().padStart(4, '0')}`
+  return { value: flags.length ? flags.join(',') : hex, fullValue: bits }
+}
+
+function assignedRows(values: AssignedValue[], address: number, offset: number, end: number): RenderRow[] {
+  const types: Cell[] = []
+  const decoded: Cell[] = []
+  let touched = false
+  for (let pos = offset; pos < end; ) {
+    const value = values.find((item) => item.address - address <= pos && pos < item.address - address + item.span)
+    if (!value) {
+      types.push({ value: '', colSpan: 1 })
+      decoded.push({ value: '', colSpan: 1 })
+      pos++
+      continue
+    }
+    touched = true
+    const start = value.address - address
+    const colSpan = Math.min(start + value.span, end) - pos
+    if (start < pos) {
+      types.push({ value: '', colSpan })
+      decoded.push({ value: '', colSpan })
+    } else {
+      types.push({
+        value: value.name ?? value.type,
+        colSpan,
+        fullValue: [value.type, value.description].filter(Boolean).join(' — '),
+      })
+      decoded.push({ value: value.value, colSpan, fullValue: value.fullValue ?? value.name })
+    }
+    pos += colSpan
+  }
+  if (!touched) {
+    return []
+  }
+  return [
+    { label: '↳ point', cells: types },
+    { label: '↳ value', cells: decoded },
+  ]
+}
+
+// Bitfield rows take one register each and span the full table width so the
+// bit grid and flag names fit.
+function bitfieldRows(
+  regs: number[],
+  address: number,
+  offset: number,
+  columns: number,
+  decoder: DecoderConfig,
+  addressBase: number,
+  addressFormat: number,
+): RenderRow[] {
+  return regs.slice(offset, offset + columns).map((reg, index) => {
+    const { bits, flags } = decodeBitfield(reg, decoder)
+    const value = flags.length ? `${bits}  ${flags.join(', ')}` : bits
+    return {
+      label: `↳ ${formatAddress(address + offset + index, addressBase, addressFormat)}`,
+      cells: [{ value, colSpan: columns }],
+    }
+  })
+}
+
 function formatAddress(address: number, _addressBase: number, format: number) {
   if (format === 16) {
     return `0x${address.toString(16).padStart(4, '0')}`
@@ -242,42 +369,227 @@ function formatValue(value: number, base: number) {
   return `${value}`
 }
 
-function decodeRegisters(regs: number[], decoder: DecoderConfig, columns: number): Cell[] {
+// Values are aligned to the decoder span from the start of the block; a value
+// running past the row end is clipped to it.
+function decodeRow(address: number, regs: number[], offset: number, columns: number, decoder: DecoderConfig): Cell[] {
+  const end = Math.min(offset + columns, regs.length)
+  const span = registerSpan(decoder)
+  const cells: Cell[] = []
+  const start = Math.ceil(offset / span) * span
+  if (start > offset) {
+    cells.push({ value: '', colSpan: Math.min(start, end) - offset })
+  }
+  for (let i = start; i < end; i += span) {
+    if (i + span > regs.length) {
+      cells.push({ value: '—', colSpan: end - i })
+      break
+    }
+    const decoded = applyScaling(decodeValue(regs.slice(i, i + span), decoder), decoder, address, regs)
+    cells.push({ value: decoded.value, fullValue: decoded.fullValue, colSpan: Math.min(span, end - i) })
+  }
+  if (cells.length === 0) {
+    cells.push({ value: '—', colSpan: columns })
+  }
+  return cells
+}
+
+function decodeValue(regs: number[], decoder: DecoderConfig): Decoded {
   if (decoder.type === 'uint16') {
-    return regs.map((value) => ({ value: formatValue(value, 10), colSpan: 1 }))
+    return { value: formatValue(regs[0], 10), raw: regs[0] }
   }
   if (decoder.type === 'int16') {
-    return regs.map((value) => ({ value: `${toInt16(value)}`, colSpan: 1 }))
-  }
-  if (decoder.type === 'uint32' || decoder.type === 'int32' || decoder.type === 'float32') {
-    const cells: Cell[] = []
-    for (let i = 0; i + 1 < regs.length; i += 2) {
-      const high = regs[i]
-      const low = regs[i + 1]
-      const [first, second] = decoder.wordOrder === 'low-first' ? [low, high] : [high, low]
-      const bytes = decoder.endianness === 'little'
-        ? [first & 0xff, first >> 8, second & 0xff, second >> 8]
-        : [first >> 8, first & 0xff, second >> 8, second & 0xff]
-      const view = new DataView(new Uint8Array(bytes).buffer)
-      let value: string
-      let fullValue: string | undefined
-      if (decoder.type === 'uint32') {
-        value = `${view.getUint32(0, false)}`
-      } else if (decoder.type === 'int32') {
-        value = `${view.getInt32(0, false)}`
-      } else {
-        const raw = view.getFloat32(0, false)
-        value = formatFloat(raw)
-        fullValue = `${raw}`
+    return { value: `${toInt16(regs[0])}`, raw: toInt16(regs[0]) }
+  }
+  if (decoder.type === 'packed') {
+    const raw = regs.reduce(
+      (value, bit, index) => value + (bit ? 2 ** (decoder.msbFirst ? regs.length - 1 - index : index) : 0),
+      0,
+    )
+    return { value: `${raw}`, raw }
+  }
+  if (decoder.type === 'string') {
+    const text = decodeString(regs, decoder)
+    return { value: text, fullValue: text }
+  }
+  if (decoder.type === 'enum') {
+    const raw = decoder.endianness === 'little' ? ((regs[0] & 0xff) << 8) | (regs[0] >> 8) : regs[0]
+    const label = decoder.labels?.[raw] ?? `unknown (${raw})`
+    return { value: label, fullValue: `${raw}: ${label}` }
+  }
+  if (decoder.type === 'bcd16' || decoder.type === 'bcd32') {
+    return decodeBCD(orderedBytes(regs, decoder))
+  }
+  if (decoder.type === 'datetime') {
+    return decodeDateTime(orderedBytes(regs, decoder), decoder)
+  }
+  const view = new DataView(new Uint8Array(orderedBytes(regs, decoder)).buffer)
+  switch (decoder.type) {
+    case 'uint32':
+      return { value: `${view.getUint32(0, false)}`, raw: view.getUint32(0, false) }
+    case 'int32':
+      return { value: `${view.getInt32(0, false)}`, raw: view.getInt32(0, false) }
+    case 'float16': {
+      const raw = float16(view.getUint16(0, false))
+      return { value: formatFloat(raw), fullValue: `${raw}`, raw }
+    }
+    case 'q16':
+    case 'q32': {
+      const bits = decoder.type === 'q16' ? 16 : 32
+      let int = bits === 16 ? view.getUint16(0, false) : view.getUint32(0, false)
+      if (!decoder.unsigned && int >= 2 ** (bits - 1)) {
+        int -= 2 ** bits
+      }
+      const raw = int / 2 ** (decoder.fracBits ?? 0)
+      return { value: formatFloat(raw), fullValue: `${raw}`, raw }
+    }
+    case 'float32': {
+      const raw = view.getFloat32(0, false)
+      return { value: formatFloat(raw), fullValue: `${raw}`, raw }
+    }
+    case 'uint64':
+      return { value: `${view.getBigUint64(0, false)}`, raw: Number(view.getBigUint64(0, false)) }
+    case 'int64':
+      return { value: `${view.getBigInt64(0, false)}`, raw: Number(view.getBigInt64(0, false)) }
+    case 'float64': {
+      const raw = view.getFloat64(0, false)
+      return { value: formatFloat(raw), fullValue: `${raw}`, raw }
+    }
+    case 'epoch32':
+    case 'epoch64': {
+      const raw = decoder.type === 'epoch32' ? view.getUint32(0, false) : Number(view.getBigInt64(0, false))
+      const ms = decoder.millis ? raw : raw * 1000
+      if (!Number.isFinite(new Date(ms).getTime())) {
+        return { value: 'invalid', fullValue: `${raw} out of range` }
+      }
+      const text = formatTimestamp(ms, decoder.timezone || 'UTC', decoder.millis)
+      return { value: text, fullValue: `${text} (${raw})` }
+    }
+    default:
+      return { value: `${regs[0]}` }
+  }
+}
+
+// float16 widens an IEEE 754 half-precision bit pattern.
+function float16(half: number) {
+  const sign = half & 0x8000 ? -1 : 1
+  const exp = (half >> 10) & 0x1f
+  const frac = half & 0x3ff
+  if (exp === 0) {
+    return sign * frac * 2 ** -24
+  }
+  if (exp === 0x1f) {
+    return frac ? NaN : sign * Infinity
+  }
+  return sign * (1 + frac / 1024) * 2 ** (exp - 15)
+}
+
+// decodeDateTime reads Y/M/D/h/m/s wall-clock fields, one per register or,
+// for three registers, one per byte; two-digit years are taken as 20YY.
+function decodeDateTime(bytes: number[], decoder: DecoderConfig): Decoded {
+  const fields =
+    bytes.length === 6 ? bytes : [0, 1, 2, 3, 4, 5].map((index) => (bytes[index * 2] << 8) | bytes[index * 2 + 1])
+  if (fields[0] < 100) {
+    fields[0] += 2000
+  }
+  const [year, month, day, hour, minute, second] = fields
+  const check = new Date(Date.UTC(year, month - 1, day))
+  if (month < 1 || month > 12 || hour > 23 || minute > 59 || second > 59 || check.getUTCDate() !== day) {
+    return { value: 'invalid', fullValue: `invalid date ${fields.join(' ')}` }
+  }
+  const text = formatTimestamp(wallClockToEpoch(fields, decoder.timezone || 'UTC'), decoder.timezone || 'UTC')
+  return { value: text, fullValue: text }
+}
+
+function decodeBCD(bytes: number[]): Decoded {
+  let value = 0
+  for (const byte of bytes) {
+    for (const nibble of [byte >> 4, byte & 0x0f]) {
+      if (nibble > 9) {
+        return { value: 'invalid', fullValue: `invalid BCD digit 0x${nibble.toString(16).toUpperCase()}` }
       }
-      cells.push({ value, colSpan: 2, fullValue })
+      value = value * 10 + nibble
+    }
+  }
+  return { value: `${value}`, raw: value }
+}
+
+// applyScaling mirrors the server: raw × scale × 10^SF + offset, with the
+// scale factor read from another register of the same block.
+function applyScaling(decoded: Decoded, decoder: DecoderConfig, address: number, block: number[]): Decoded {
+  const hasScaling =
+    (decoder.scale !== undefined && decoder.scale !== 0 && decoder.scale !== 1) ||
+    Boolean(decoder.offset) ||
+    decoder.decimals !== undefined ||
+    Boolean(decoder.unit) ||
+    decoder.scaleFactor !== undefined
+  if (!hasScaling || decoded.raw === undefined) {
+    return decoded
+  }
+  let value = decoded.raw * (decoder.scale || 1)
+  if (decoder.scaleFactor !== undefined) {
+    const index = decoder.scaleFactor - address
+    if (index < 0 || index >= block.length) {
+      return { value: 'no SF', fullValue: `scale factor register ${decoder.scaleFactor} not in read` }
     }
-    if (cells.length === 0) {
-      cells.push({ value: '—', colSpan: columns })
+    const exponent = toInt16(block[index])
+    if (exponent === -0x8000) {
+      return { value: 'no SF', fullValue: `scale factor register ${decoder.scaleFactor} not implemented` }
     }
-    return cells
+    value *= 10 ** exponent
+  }
+  value += decoder.offset ?? 0
+  const text = decoder.decimals !== undefined ? value.toFixed(decoder.decimals) : `${value}`
+  return { value: decoder.unit ? `${text} ${decoder.unit}` : text, fullValue: `raw ${decoded.value}` }
+}
+
+function decodeString(regs: number[], decoder: DecoderConfig) {
+  const bytes = regs.flatMap((word) =>
+    decoder.endianness === 'little' ? [word & 0xff, word >> 8] : [word >> 8, word & 0xff],
+  )
+  let text: string
+  if (decoder.encoding === 'utf-8') {
+    text = new TextDecoder('utf-8').decode(new Uint8Array(bytes))
+  } else if (decoder.encoding === 'latin-1') {
+    text = String.fromCharCode(...bytes)
+  } else {
+    text = bytes.map((byte) => (byte < 0x80 ? String.fromCharCode(byte) : '\uFFFD')).join('')
+  }
+  const trim = decoder.trim ?? 'both'
+  if (trim === 'null' || trim === 'both') {
+    const nul = text.indexOf('\0')
+    text = nul === -1 ? text : text.slice(0, nul)
+  }
+  if (trim === 'space' || trim === 'both') {
+    text = text.trim()
   }
-  return regs.map((value) => ({ value: `${value}`, colSpan: 1 }))
+  return text
+}
+
+function registerSpan(decoder: DecoderConfig) {
+  const { type } = decoder
+  if (type === 'uint32' || type === 'int32' || type === 'float32' || type === 'bcd32' || type === 'q32' || type === 'epoch32') {
+    return 2
+  }
+  if (type === 'uint64' || type === 'int64' || type === 'float64' || type === 'epoch64') {
+    return 4
+  }
+  if (type === 'datetime') {
+    return decoder.registers === 3 ? 3 : 6
+  }
+  if (type === 'string') {
+    return Math.max(decoder.registers ?? 1, 1)
+  }
+  if (type === 'packed') {
+    return Math.min(Math.max(decoder.bits ?? 1, 1), 64)
+  }
+  return 1
+}
+
+function orderedBytes(regs: number[], decoder: DecoderConfig) {
+  const words = decoder.wordOrder === 'low-first' ? [...regs].reverse() : regs
+  return words.flatMap((word) =>
+    decoder.endianness === 'little' ? [word & 0xff, word >> 8] : [word >> 8, word & 0xff],
+  )
 }
 
 function toInt16(value: number) {
@@ -1 +1,22 @@
-export const decoderTypeOrder = ['uint16', 'int16', 'uint32', 'int32', 'float32'] as const
+export const decoderTypeOrder = [
+  'uint16',
+  'int16',
+  'uint32',
+  'int32',
+  'float16',
+  'float32',
+  'uint64',
+  'int64',
+  'float64',
+  'q16',
+  'q32',
+  'bcd16',
+  'bcd32',
+  'bitfield',
+  'enum',
+  'epoch32',
+  'epoch64',
+  'datetime',
+  'string',
+  'packed',
+] as const
@@ -1,5 +1,17 @@
+import type { FieldError } from '../types'
+
 const baseUrl = ''
 
+// ApiError carries the per-field messages of a rejected configuration.
+export class ApiError extends Error {
+  fields: FieldError[]
+
+  constructor(message: string, fields: FieldError[] = []) {
+    super(message)
+    this.fields = fields
+  }
+}
+
 export async function fetchJson<T>(
   path: string,
   options: RequestInit,
@@ -18,7 +30,7 @@ export async function fetchJson<T>(
       typeof (data as { error?: string })?.error === 'string'
         ? (data as { error?: string }).error
         : res.statusText
-    throw new Error(message || 'Request failed')
+    throw new ApiError(message || 'Request failed', (data as { fields?: FieldError[] })?.fields ?? [])
   }
   return data as T
 }
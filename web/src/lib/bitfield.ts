@@ -0,0 +1,17 @@
+import type { DecoderConfig } from '../types'
+
+export function decodeBitfield(reg: number, decoder: DecoderConfig) {
+  const raw = decoder.endianness === 'little' ? ((reg & 0xff) << 8) | (reg >> 8) : reg
+  const bits = (raw & 0xffff)
+    .toString(2)
+    .padStart(16, '0')
+    .replace(/(.{4})(?=.)/g, '$1 ')
+  const flags: string[] = []
+  for (let bit = 0; bit < 16; bit++) {
+    const name = decoder.bitNames?.[bit]
+    if (raw & (1 << bit) && name) {
+      flags.push(name)
+    }
+  }
+  return { raw, bits, flags }
+}
@@ -0,0 +1,24 @@
+import type { DecoderConfig } from '../types'
+
+export const byteOrders = ['ABCD', 'CDAB', 'BADC', 'DCBA'] as const
+
+export type ByteOrder = (typeof byteOrders)[number]
+
+// byteOrderOf names the decoder's endianness/word order pair: little endian
+// swaps bytes (BA), low-first swaps words (CD first).
+export function byteOrderOf(decoder: DecoderConfig): ByteOrder {
+  const little = decoder.endianness === 'little'
+  const lowFirst = decoder.wordOrder === 'low-first'
+  if (little) {
+    return lowFirst ? 'DCBA' : 'BADC'
+  }
+  return lowFirst ? 'CDAB' : 'ABCD'
+}
+
+export function withByteOrder(decoder: DecoderConfig, order: ByteOrder): DecoderConfig {
+  return {
+    ...decoder,
+    endianness: order === 'BADC' || order === 'DCBA' ? 'little' : 'big',
+    wordOrder: order === 'CDAB' || order === 'DCBA' ? 'low-first' : 'high-first',
+  }
+}
@@ -0,0 +1,24 @@
+export function formatLabels(labels: Record<string, string> | undefined) {
+  return Object.entries(labels ?? {})
+    .sort(([left], [right]) => Number(left) - Number(right))
+    .map(([key, label]) => `${key}=${label}`)
+    .join(', ')
+}
+
+// parseLabels reads "0=RUN, 3=FAULT"; returns null when an entry is malformed
+// or a key exceeds max.
+export function parseLabels(input: string, max: number): Record<string, string> | null {
+  const labels: Record<string, string> = {}
+  for (const part of input.split(',')) {
+    if (part.trim() === '') {
+      continue
+    }
+    const [key, label] = part.split('=').map((value) => value.trim())
+    const index = Number(key)
+    if (!/^[0-9]+$/.test(key) || index > max || !label) {
+      return null
+    }
+    labels[index] = label
+  }
+  return labels
+}
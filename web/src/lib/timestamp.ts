@@ -0,0 +1,48 @@
+// Timestamp helpers mirroring the server: ISO-8601 in an IANA timezone,
+// UTC when none is set.
+
+export function isValidTimezone(timeZone: string) {
+  try {
+    new Intl.DateTimeFormat('en-US', { timeZone })
+    return true
+  } catch {
+    return false
+  }
+}
+
+// zoneOffset returns the offset of timeZone from UTC at instant ms, in minutes.
+function zoneOffset(ms: number, timeZone: string) {
+  const parts = new Intl.DateTimeFormat('en-US', {
+    timeZone,
+    hourCycle: 'h23',
+    year: 'numeric',
+    month: 'numeric',
+    day: 'numeric',
+    hour: 'numeric',
+    minute: 'numeric',
+    second: 'numeric',
+  }).formatToParts(new Date(ms))
+  const field = (type: string) => Number(parts.find((part) => part.type === type)?.value ?? 0)
+  const wall = Date.UTC(field('year'), field('month') - 1, field('day'), field('hour'), field('minute'), field('second'))
+  return Math.round((wall - Math.floor(ms / 1000) * 1000) / 60000)
+}
+
+export function formatTimestamp(ms: number, timeZone = 'UTC', millis = false) {
+  const offset = zoneOffset(ms, timeZone)
+  const iso = new Date(ms + offset * 60000).toISOString()
+  const wall = millis ? iso.slice(0, 23) : iso.slice(0, 19)
+  if (offset === 0) {
+    return `${wall}Z`
+  }
+  const abs = Math.abs(offset)
+  const sign = offset < 0 ? '-' : '+'
+  return `${wall}${sign}${String(Math.floor(abs / 60)).padStart(2, '0')}:${String(abs % 60).padStart(2, '0')}`
+}
+
+// wallClockToEpoch converts wall-clock fields in timeZone into epoch ms.
+export function wallClockToEpoch(fields: number[], timeZone = 'UTC') {
+  const [year, month, day, hour, minute, second] = fields
+  const guess = Date.UTC(year, month - 1, day, hour, minute, second)
+  const first = guess - zoneOffset(guess, timeZone) * 60000
+  return guess - zoneOffset(first, timeZone) * 60000
+}
@@ -3,6 +3,37 @@ export type DecoderConfig = {
   endianness: string
   wordOrder: string
   enabled: boolean
+  registers?: number
+  trim?: 'none' | 'null' | 'space' | 'both'
+  encoding?: 'ascii' | 'utf-8' | 'latin-1'
+  bitNames?: Record<string, string>
+  labels?: Record<string, string>
+  fracBits?: number
+  unsigned?: boolean
+  millis?: boolean
+  timezone?: string
+  bits?: number
+  msbFirst?: boolean
+  scale?: number
+  offset?: number
+  decimals?: number
+  unit?: string
+  scaleFactor?: number
+}
+
+export type Assignment = {
+  address: number
+  count?: number
+  name?: string
+  kind?: string
+  description?: string
+  mapped?: boolean
+  decoder: DecoderConfig
+}
+
+export type FieldError = {
+  field: string
+  message: string
 }
 
 export type Config = {
@@ -29,5 +60,39 @@ export type Config = {
   listenAddr: string
   requireToken: boolean
   token: string
+  capturePath?: string
+  logFile: {
+    path?: string
+    maxSizeMb: number
+    maxAgeHours: number
+    maxBackups: number
+    compress: boolean
+  }
   decoders: DecoderConfig[]
+  assignments?: Assignment[]
+  mapFile?: string
+  profile?: string
+  autoSave?: boolean
+  configFile?: string
+}
+
+export type ConnectionProfile = {
+  name: string
+  protocol: 'tcp' | 'rtu'
+  serial: Config['serial']
+  tcp: Config['tcp']
+  unitId: number
+  timeoutMs: number
+  decoders?: DecoderConfig[]
+}
+
+export type DeviceProfile = {
+  name: string
+  title?: string
+  source: string
+}
+
+export type ProfilesResponse = {
+  connections: ConnectionProfile[]
+  devices: DeviceProfile[]
 }
@@ -6,19 +6,33 @@ export type ReadResult = {
   quantity: number
   boolValues?: boolean[]
   regValues?: number[]
-  decoded?: { type: string; value: number }[]
+  decoded?: { type: string; value: unknown; error?: string }[]
+  values?: { address: number; span: number; name?: string; type: string; value?: unknown; error?: string }[]
   latencyMs: number
   completedAt: string
   errorMessage?: string
   errorKind?: string
 }
 
+export type ByteOrderCandidate = {
+  order: 'ABCD' | 'CDAB' | 'BADC' | 'DCBA'
+  offset: number
+  value: number
+  delta: number
+}
+
 export type LogEntry = {
+  seq: number
   time: string
   direction: string
   message: string
 }
 
+export type LogPage = {
+  entries: LogEntry[]
+  nextCursor?: number
+}
+
 export type Stats = {
   readCount: number
   errorCount: number
@@ -26,7 +40,7 @@ export type Stats = {
 }
 
 export type WsEvent = {
-  type: 'data' | 'log' | 'stats' | 'error' | 'status'
+  type: 'data' | 'log' | 'stats' | 'error' | 'status' | 'capture'
   payload: any
 }
 
@@ -35,3 +49,9 @@ export type ConnectionStatus = {
   connecting: boolean
   lastError?: string
 }
+
+export type CaptureStatus = {
+  active: boolean
+  path?: string
+  packets: number
+}